@@ -9,6 +9,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 
 	"github.com/BurntSushi/toml"
 	"go.uber.org/zap"
@@ -23,8 +24,14 @@ type App struct {
 	TranscribeMaxAttempts int      `toml:"transcribe_max_attempts"`
 	TranslateMaxAttempts  int      `toml:"translate_max_attempts"`
 	MaxSentenceLength     int      `toml:"max_sentence_length"`
+	ConfidenceThreshold   float64  `toml:"confidence_threshold"`  // 识别置信度低于该值的词会列入复核报告
+	PunctuationMode       string   `toml:"punctuation_mode"`      // 分句前的标点恢复：none不处理 llm大模型 pause按词间停顿
+	SegmentMode           string   `toml:"segment_mode"`          // 分句方式：punctuation按标点 pause结合词间停顿和时长
+	MaxSentenceDuration   float64  `toml:"max_sentence_duration"` // pause分句时每句最长秒数
 	Proxy                 string   `toml:"proxy"`
 	ParsedProxy           *url.URL `toml:"-"`
+	TranslateContextLines int      `toml:"translate_context_lines"`   // 批量翻译时作为上下文携带的前文行数，0为不携带
+	RunningSummary        bool     `toml:"translate_running_summary"` // 携带前文时是否同时维护视频内容摘要作为上下文
 }
 
 type Server struct {
@@ -38,10 +45,33 @@ type OpenaiCompatibleConfig struct {
 	Model   string `toml:"model"`
 }
 
+// LlmProfile 大模型提供方配置，base_url、api_key、model 留空时沿用 [llm] 的配置
+type LlmProfile struct {
+	Name    string        `toml:"name"` // 配置名称，同时作为限流和用量统计的提供方名称
+	BaseUrl string        `toml:"base_url"`
+	ApiKey  string        `toml:"api_key"`
+	Model   string        `toml:"model"`
+	Params  LlmCallConfig `toml:"params"` // 覆盖 llm_calls 中对应用途的参数，为空或0的字段不覆盖
+}
+
+// LlmConfig 默认的大模型配置，routes 按用途（translate/split/summary/clipper）指定使用 profiles 中的哪个配置
+type LlmConfig struct {
+	OpenaiCompatibleConfig
+	Profiles []LlmProfile      `toml:"profiles"`
+	Routes   map[string]string `toml:"routes"`
+}
+
 type LocalModelConfig struct {
 	Model string `toml:"model"`
 }
 
+// WhisperServerConfig 常驻的 whisper.cpp / faster-whisper HTTP 服务，模型只加载一次
+type WhisperServerConfig struct {
+	BaseUrl       string `toml:"base_url"`
+	InferencePath string `toml:"inference_path"`
+	Model         string `toml:"model"` // 可选，服务端支持多模型时传入
+}
+
 type AliyunSpeechConfig struct {
 	AccessKeyId     string `toml:"access_key_id"`
 	AccessKeySecret string `toml:"access_key_secret"`
@@ -66,6 +96,7 @@ type Transcribe struct {
 	Fasterwhisper         LocalModelConfig       `toml:"fasterwhisper"`
 	Whisperkit            LocalModelConfig       `toml:"whisperkit"`
 	Whispercpp            LocalModelConfig       `toml:"whispercpp"`
+	WhispercppServer      WhisperServerConfig    `toml:"whispercpp_server"`
 	Aliyun                AliyunTranscribeConfig `toml:"aliyun"`
 }
 
@@ -115,20 +146,174 @@ type SmartClipperConfig struct {
 	Model           string `toml:"model"`
 	BaseUrl         string `toml:"base_url"` // New: Independent BaseUrl
 	ApiKey          string `toml:"api_key"`  // New: Independent ApiKey
-	Prompt          string `toml:"prompt"`
+	Prompt          string `toml:"prompt"`   // 旧版 printf 格式（%d %d %s）的提示词，建议改用 prompt 模板
 	MinClipDuration int    `toml:"min_clip_duration"`
 	MaxClipDuration int    `toml:"max_clip_duration"`
 }
 
+// TranslationMemoryConfig 翻译记忆库：完全相同的句子直接复用历史译文，相似句子作为参考提供给大模型
+type TranslationMemoryConfig struct {
+	Enabled         bool    `toml:"enabled"`           // 默认关闭，需要手动开启
+	FuzzyThreshold  float64 `toml:"fuzzy_threshold"`   // 相似度（0~1）不低于该值的历史译文作为参考
+	MaxFuzzyMatches int     `toml:"max_fuzzy_matches"` // 每批次最多提供的参考译文数量
+}
+
+// TranslationQaConfig 翻译质检：规则检查（漏译、臆造、未翻译、语言错误、长度异常）加可选的大模型评审，
+// 不合格的字幕自动重译一次，并输出质检报告
+type TranslationQaConfig struct {
+	Enabled        bool    `toml:"enabled"`
+	LlmJudge       bool    `toml:"llm_judge"`        // 是否让大模型给通过规则检查的译文打分
+	MinJudgeScore  int     `toml:"min_judge_score"`  // 大模型评分（1-5）低于该值视为不合格
+	MinLengthRatio float64 `toml:"min_length_ratio"` // 译文与原文长度比低于该值视为漏译
+	MaxLengthRatio float64 `toml:"max_length_ratio"` // 译文与原文长度比高于该值视为长度异常
+}
+
+// TranslateRoute 按语言对指定翻译后端，语言为 * 或留空表示任意语言，靠前的规则优先
+type TranslateRoute struct {
+	SourceLanguage string `toml:"source_lang"`
+	TargetLanguage string `toml:"target_lang"`
+	Provider       string `toml:"provider"` // llm / mt
+}
+
+// MachineTranslateConfig 通用机器翻译 HTTP 接口，兼容 DeepL 和 LibreTranslate 风格的 API
+type MachineTranslateConfig struct {
+	Api     string `toml:"api"` // deepl / libretranslate
+	BaseUrl string `toml:"base_url"`
+	ApiKey  string `toml:"api_key"`
+}
+
+// TranslateConfig 翻译后端选择：默认使用大模型，可以按语言对改用更便宜、更快的机器翻译
+type TranslateConfig struct {
+	Provider string                 `toml:"provider"` // 默认翻译后端 llm / mt
+	Routes   []TranslateRoute       `toml:"routes"`
+	Mt       MachineTranslateConfig `toml:"mt"`
+}
+
+// LlmCallConfig 某一类大模型调用的请求参数，temperature、max_tokens、seed 为0时不传，使用服务端默认值
+type LlmCallConfig struct {
+	SystemPrompt    string  `toml:"system_prompt"`
+	Temperature     float32 `toml:"temperature"`
+	MaxTokens       int     `toml:"max_tokens"`
+	ResponseFormat  string  `toml:"response_format"` // text / json_object / json_schema
+	Seed            int     `toml:"seed"`
+	ReasoningEffort string  `toml:"reasoning_effort"` // low / medium / high，仅推理模型支持
+}
+
+// LlmCallsConfig 按用途分别配置大模型调用参数
+type LlmCallsConfig struct {
+	Translate LlmCallConfig `toml:"translate"` // 批量翻译、翻译质检
+	Split     LlmCallConfig `toml:"split"`     // 长句拆分、标点恢复
+	Summary   LlmCallConfig `toml:"summary"`   // 视频标题简介、内容摘要
+	Clipper   LlmCallConfig `toml:"clipper"`   // 智能切片
+}
+
+// UsagePrice 某个提供方/模型的价格，model 为空或 * 时匹配该提供方的所有模型
+type UsagePrice struct {
+	Provider               string  `toml:"provider"` // llm，或转录、语音合成的 provider 名
+	Model                  string  `toml:"model"`
+	InputPerMillionTokens  float64 `toml:"input_per_million_tokens"`
+	OutputPerMillionTokens float64 `toml:"output_per_million_tokens"`
+	PerAudioMinute         float64 `toml:"per_audio_minute"`
+	PerMillionCharacters   float64 `toml:"per_million_characters"`
+}
+
+type UsageConfig struct {
+	Enabled     bool         `toml:"enabled"`
+	StreamUsage bool         `toml:"stream_usage"` // 流式请求时是否要求服务端返回 token 用量
+	Currency    string       `toml:"currency"`
+	Prices      []UsagePrice `toml:"prices"`
+}
+
+// RateLimitProvider 某个大模型提供方的限额，rpm/tpm 为0表示不限制
+type RateLimitProvider struct {
+	Name string `toml:"name"` // 提供方名称，主模型为 llm，智能切片为 smart_clipper，路由的模型配置为配置名称
+	Rpm  int    `toml:"rpm"`  // 每分钟请求数
+	Tpm  int    `toml:"tpm"`  // 每分钟 token 数
+}
+
+type RateLimitConfig struct {
+	MaxRetries        int                 `toml:"max_retries"` // 限流（429）和服务端临时错误的重试次数
+	BaseBackoffMs     int                 `toml:"base_backoff_ms"`
+	MaxBackoffSeconds int                 `toml:"max_backoff_seconds"`
+	Providers         []RateLimitProvider `toml:"providers"`
+}
+
+// PromptTemplateConfig 配置文件中定义的提示词模板，内容为 text/template 格式，可用变量见 /api/prompt_template/kinds
+type PromptTemplateConfig struct {
+	Name    string `toml:"name"`
+	Kind    string `toml:"kind"` // 模板类型，如 batch_translate、smart_clipper
+	Content string `toml:"content"`
+}
+
+type PromptConfig struct {
+	Defaults  map[string]string      `toml:"defaults"` // 各模板类型默认使用的模板名称，可写 name@version 固定版本，未配置时使用内置模板
+	Templates []PromptTemplateConfig `toml:"templates"`
+}
+
+// CondensationConfig 译文超出阅读速度或长度限制时，让大模型生成精简译文用于字幕和配音，完整译文保留在翻译数据中
+type CondensationConfig struct {
+	Enabled       bool    `toml:"enabled"`
+	MaxCps        float64 `toml:"max_cps"`         // 每秒字符数上限
+	MaxCpsAsian   float64 `toml:"max_cps_asian"`   // 中日韩泰语的每秒字符数上限
+	MaxLineLength int     `toml:"max_line_length"` // 单条字幕译文的最大字符数，0为不限制
+}
+
+// SubtitleTimingConfig 字幕时间轴规整，生成的字幕在配音和嵌入视频前统一处理。时间单位为秒，为0的项不处理
+type SubtitleTimingConfig struct {
+	Enabled     bool    `toml:"enabled"`
+	MinDuration float64 `toml:"min_duration"`  // 最短显示时长，不足时延长
+	MaxDuration float64 `toml:"max_duration"`  // 最长显示时长，超出时截断结尾
+	MinGap      float64 `toml:"min_gap"`       // 相邻字幕的最小间隔，重叠或过近时提前上一条的结束时间
+	MergeUnder  float64 `toml:"merge_under"`   // 短于该时长的字幕与相邻字幕合并
+	MergeMaxGap float64 `toml:"merge_max_gap"` // 合并时与相邻字幕的最大间隔
+	FillGap     float64 `toml:"fill_gap"`      // 小于该值的间隔由上一条字幕延长填补，避免闪烁
+	ChainGap    float64 `toml:"chain_gap"`     // 小于该值的间隔由前后两条字幕在中点衔接
+	MaxCps      float64 `toml:"max_cps"`       // 每秒字符数上限，字幕显示时间不够读完时延长
+	MaxCpsAsian float64 `toml:"max_cps_asian"` // 中日韩泰语的每秒字符数上限
+
+	ShotChange      bool    `toml:"shot_change"`       // 用 ffmpeg 场景检测获取镜头切换，把字幕起止时间对齐到镜头切换
+	SceneThreshold  float64 `toml:"scene_threshold"`   // 场景变化分数（0~1）高于该值视为镜头切换
+	SnapTolerance   float64 `toml:"snap_tolerance"`    // 起止时间与镜头切换相差不超过该值时对齐
+	FramesBeforeCut int     `toml:"frames_before_cut"` // 结束时间对齐到镜头切换之前的帧数
+}
+
+// TimeoutProvider 某个提供方的超时设置（秒），为0的字段沿用 timeout 中的默认值
+type TimeoutProvider struct {
+	Name           string `toml:"name"` // 提供方名称，大模型同 rate_limit.providers，转录服务为 transcribe，配音服务为 tts，机器翻译为 mt
+	ConnectSeconds int    `toml:"connect_seconds"`
+	IdleSeconds    int    `toml:"idle_seconds"`
+	OverallSeconds int    `toml:"overall_seconds"`
+}
+
+// TimeoutConfig 调用外部服务的超时（秒），0表示不限制
+type TimeoutConfig struct {
+	ConnectSeconds int               `toml:"connect_seconds"` // 建立连接的超时
+	IdleSeconds    int               `toml:"idle_seconds"`    // 等待响应以及流式响应中途没有任何数据的最长时间，超过后中断请求
+	OverallSeconds int               `toml:"overall_seconds"` // 单次请求从发出到读完响应的总时长
+	Providers      []TimeoutProvider `toml:"providers"`
+}
+
 type Config struct {
-	App          App                    `toml:"app"`
-	Server       Server                 `toml:"server"`
-	Llm          OpenaiCompatibleConfig `toml:"llm"`
-	Transcribe   Transcribe             `toml:"transcribe"`
-	Tts          Tts                    `toml:"tts"`
-	SmartClipper SmartClipperConfig     `toml:"smart_clipper"`
+	App               App                     `toml:"app"`
+	Server            Server                  `toml:"server"`
+	Llm               LlmConfig               `toml:"llm"`
+	Transcribe        Transcribe              `toml:"transcribe"`
+	Tts               Tts                     `toml:"tts"`
+	SmartClipper      SmartClipperConfig      `toml:"smart_clipper"`
+	TranslationMemory TranslationMemoryConfig `toml:"translation_memory"`
+	Translate         TranslateConfig         `toml:"translate"`
+	TranslationQa     TranslationQaConfig     `toml:"translation_qa"`
+	LlmCalls          LlmCallsConfig          `toml:"llm_calls"`
+	Usage             UsageConfig             `toml:"usage"`
+	RateLimit         RateLimitConfig         `toml:"rate_limit"`
+	Prompt            PromptConfig            `toml:"prompt"`
+	Condensation      CondensationConfig      `toml:"condensation"`
+	SubtitleTiming    SubtitleTimingConfig    `toml:"subtitle_timing"`
+	Timeout           TimeoutConfig           `toml:"timeout"`
 }
 
+const defaultLlmSystemPrompt = "You are an assistant that helps with subtitle translation."
+
 var Conf = Config{
 	App: App{
 		SegmentDuration:       5,
@@ -137,13 +322,19 @@ var Conf = Config{
 		TranscribeMaxAttempts: 3,
 		TranslateMaxAttempts:  3,
 		MaxSentenceLength:     70,
+		ConfidenceThreshold:   0.6,
+		PunctuationMode:       "none",
+		SegmentMode:           "punctuation",
+		MaxSentenceDuration:   7,
 	},
 	Server: Server{
 		Host: "127.0.0.1",
 		Port: 8888,
 	},
-	Llm: OpenaiCompatibleConfig{
-		Model: "gpt-4o-mini",
+	Llm: LlmConfig{
+		OpenaiCompatibleConfig: OpenaiCompatibleConfig{
+			Model: "gpt-4o-mini",
+		},
 	},
 	Transcribe: Transcribe{
 		Provider:              "openai",
@@ -160,6 +351,10 @@ var Conf = Config{
 		Whispercpp: LocalModelConfig{
 			Model: "large-v2",
 		},
+		WhispercppServer: WhisperServerConfig{
+			BaseUrl:       "http://127.0.0.1:8080",
+			InferencePath: "/inference",
+		},
 	},
 	Tts: Tts{
 		Provider: "openai",
@@ -183,6 +378,82 @@ var Conf = Config{
 		MinClipDuration: 60,
 		MaxClipDuration: 600,
 	},
+	TranslationMemory: TranslationMemoryConfig{
+		Enabled:         false,
+		FuzzyThreshold:  0.75,
+		MaxFuzzyMatches: 5,
+	},
+	Translate: TranslateConfig{
+		Provider: "llm",
+		Mt: MachineTranslateConfig{
+			Api: "libretranslate",
+		},
+	},
+	TranslationQa: TranslationQaConfig{
+		Enabled:        false,
+		LlmJudge:       true,
+		MinJudgeScore:  3,
+		MinLengthRatio: 0.2,
+		MaxLengthRatio: 3,
+	},
+	LlmCalls: LlmCallsConfig{
+		Translate: LlmCallConfig{
+			SystemPrompt:   defaultLlmSystemPrompt,
+			Temperature:    0.9,
+			MaxTokens:      8192,
+			ResponseFormat: "text",
+		},
+		Split: LlmCallConfig{
+			SystemPrompt:   defaultLlmSystemPrompt,
+			Temperature:    0.9,
+			MaxTokens:      8192,
+			ResponseFormat: "text",
+		},
+		Summary: LlmCallConfig{
+			SystemPrompt:   defaultLlmSystemPrompt,
+			Temperature:    0.9,
+			MaxTokens:      8192,
+			ResponseFormat: "text",
+		},
+		Clipper: LlmCallConfig{
+			SystemPrompt:   defaultLlmSystemPrompt,
+			Temperature:    0.9,
+			MaxTokens:      8192,
+			ResponseFormat: "text",
+		},
+	},
+	Usage: UsageConfig{
+		Enabled:     true,
+		StreamUsage: false,
+		Currency:    "USD",
+	},
+	RateLimit: RateLimitConfig{
+		MaxRetries:        3,
+		BaseBackoffMs:     1000,
+		MaxBackoffSeconds: 60,
+	},
+	Condensation: CondensationConfig{
+		MaxCps:      17,
+		MaxCpsAsian: 9,
+	},
+	SubtitleTiming: SubtitleTimingConfig{
+		MinDuration: 1,
+		MaxDuration: 7,
+		MinGap:      0.083,
+		MergeUnder:  0.5,
+		MergeMaxGap: 0.3,
+		FillGap:     0.25,
+		ChainGap:    0.5,
+		MaxCps:      20,
+		MaxCpsAsian: 10,
+
+		SceneThreshold:  0.3,
+		SnapTolerance:   0.5,
+		FramesBeforeCut: 2,
+	},
+	Timeout: TimeoutConfig{
+		ConnectSeconds: 30,
+	},
 }
 
 // 检查必要的配置是否完整
@@ -213,6 +484,10 @@ func validateConfig() error {
 		if Conf.Transcribe.Whispercpp.Model != "large-v2" {
 			return errors.New("检测到开启了whisper.cpp，但模型选型配置不正确，请检查配置")
 		}
+	case "whispercpp_server":
+		if Conf.Transcribe.WhispercppServer.BaseUrl == "" {
+			return errors.New("使用whisper.cpp服务需要配置服务地址 base_url")
+		}
 	case "aliyun":
 		if Conf.Transcribe.Aliyun.Speech.AccessKeyId == "" || Conf.Transcribe.Aliyun.Speech.AccessKeySecret == "" || Conf.Transcribe.Aliyun.Speech.AppKey == "" {
 			return errors.New("使用阿里云语音服务需要配置相关密钥")
@@ -221,6 +496,191 @@ func validateConfig() error {
 		return errors.New("不支持的转录提供商")
 	}
 
+	switch Conf.App.PunctuationMode {
+	case "", "none", "llm", "pause":
+	default:
+		return errors.New("标点恢复方式 punctuation_mode 仅支持 none、llm、pause")
+	}
+	switch Conf.App.SegmentMode {
+	case "", "punctuation", "pause":
+	default:
+		return errors.New("分句方式 segment_mode 仅支持 punctuation、pause")
+	}
+	if err := validateTranslateConfig(); err != nil {
+		return err
+	}
+	if err := validateLlmCallsConfig(); err != nil {
+		return err
+	}
+	if err := validateLlmRoutesConfig(); err != nil {
+		return err
+	}
+	if err := validatePromptConfig(); err != nil {
+		return err
+	}
+	if Conf.Condensation.Enabled && (Conf.Condensation.MaxCps <= 0 || Conf.Condensation.MaxCpsAsian <= 0 || Conf.Condensation.MaxLineLength < 0) {
+		return errors.New("开启译文精简时 condensation.max_cps、max_cps_asian 需要大于0，max_line_length 不能为负数")
+	}
+	if err := validateTimeoutConfig(); err != nil {
+		return err
+	}
+	if err := validateSubtitleTimingConfig(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// validateTranslateConfig 检查翻译后端配置，用到机器翻译时必须配置接口地址
+func validateTranslateConfig() error {
+	useMt := false
+	providers := []string{Conf.Translate.Provider}
+	for _, route := range Conf.Translate.Routes {
+		providers = append(providers, route.Provider)
+	}
+	for _, provider := range providers {
+		switch provider {
+		case "", "llm":
+		case "mt":
+			useMt = true
+		default:
+			return fmt.Errorf("不支持的翻译后端 %q，仅支持 llm、mt", provider)
+		}
+	}
+	if !useMt {
+		return nil
+	}
+	switch Conf.Translate.Mt.Api {
+	case "", "libretranslate", "deepl":
+	default:
+		return errors.New("机器翻译接口类型 translate.mt.api 仅支持 libretranslate、deepl")
+	}
+	if Conf.Translate.Mt.BaseUrl == "" {
+		return errors.New("使用机器翻译需要配置接口地址 translate.mt.base_url")
+	}
+	return nil
+}
+
+// validatePromptConfig 检查配置文件中的模板名称，模板内容在服务启动时按类型校验
+func validatePromptConfig() error {
+	names := make(map[string]bool)
+	for _, tmpl := range Conf.Prompt.Templates {
+		if tmpl.Name == "" || tmpl.Kind == "" {
+			return errors.New("提示词模板 prompt.templates 的 name 和 kind 不能为空")
+		}
+		if strings.Contains(tmpl.Name, "@") {
+			return fmt.Errorf("提示词模板名称不能包含@: %s", tmpl.Name)
+		}
+		if names[tmpl.Name] {
+			return fmt.Errorf("提示词模板名称重复: %s", tmpl.Name)
+		}
+		names[tmpl.Name] = true
+	}
+	return nil
+}
+
+// validateLlmCallsConfig 检查各用途的大模型调用参数
+func validateLlmCallsConfig() error {
+	calls := map[string]LlmCallConfig{
+		"translate": Conf.LlmCalls.Translate,
+		"split":     Conf.LlmCalls.Split,
+		"summary":   Conf.LlmCalls.Summary,
+		"clipper":   Conf.LlmCalls.Clipper,
+	}
+	for name, call := range calls {
+		if err := validateLlmCallConfig("llm_calls."+name, call); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateLlmCallConfig(prefix string, call LlmCallConfig) error {
+	switch call.ResponseFormat {
+	case "", "text", "json_object", "json_schema":
+	default:
+		return fmt.Errorf("%s.response_format 仅支持 text、json_object、json_schema", prefix)
+	}
+	switch call.ReasoningEffort {
+	case "", "low", "medium", "high":
+	default:
+		return fmt.Errorf("%s.reasoning_effort 仅支持 low、medium、high", prefix)
+	}
+	if call.Temperature < 0 || call.Temperature > 2 {
+		return fmt.Errorf("%s.temperature 需要在0到2之间", prefix)
+	}
+	if call.MaxTokens < 0 {
+		return fmt.Errorf("%s.max_tokens 不能为负数", prefix)
+	}
+	return nil
+}
+
+// validateLlmRoutesConfig 检查大模型路由，路由只能指向已定义的配置，llm 保留给默认配置
+func validateLlmRoutesConfig() error {
+	profiles := make(map[string]bool)
+	for _, profile := range Conf.Llm.Profiles {
+		if profile.Name == "" || profile.Name == "llm" {
+			return errors.New("大模型配置 llm.profiles 的 name 不能为空且不能为 llm")
+		}
+		if profiles[profile.Name] {
+			return fmt.Errorf("大模型配置名称重复: %s", profile.Name)
+		}
+		profiles[profile.Name] = true
+		if err := validateLlmCallConfig("llm.profiles."+profile.Name+".params", profile.Params); err != nil {
+			return err
+		}
+	}
+	for callType, name := range Conf.Llm.Routes {
+		switch callType {
+		case "translate", "split", "summary", "clipper":
+		default:
+			return fmt.Errorf("llm.routes 不支持的用途 %s，仅支持 translate、split、summary、clipper", callType)
+		}
+		if name != "" && name != "llm" && !profiles[name] {
+			return fmt.Errorf("llm.routes.%s 指向的配置 %s 不存在", callType, name)
+		}
+	}
+	return nil
+}
+
+// validateTimeoutConfig 检查超时设置，超时不能为负数，提供方名称不能重复
+func validateTimeoutConfig() error {
+	if Conf.Timeout.ConnectSeconds < 0 || Conf.Timeout.IdleSeconds < 0 || Conf.Timeout.OverallSeconds < 0 {
+		return errors.New("timeout 中的超时不能为负数")
+	}
+	names := make(map[string]bool)
+	for _, provider := range Conf.Timeout.Providers {
+		if provider.Name == "" {
+			return errors.New("超时设置 timeout.providers 的 name 不能为空")
+		}
+		if names[provider.Name] {
+			return fmt.Errorf("超时设置的提供方重复: %s", provider.Name)
+		}
+		names[provider.Name] = true
+		if provider.ConnectSeconds < 0 || provider.IdleSeconds < 0 || provider.OverallSeconds < 0 {
+			return fmt.Errorf("timeout.providers.%s 的超时不能为负数", provider.Name)
+		}
+	}
+	return nil
+}
+
+// validateSubtitleTimingConfig 开启时间轴规整时各项不能为负数，最长显示时长不能小于最短显示时长
+func validateSubtitleTimingConfig() error {
+	timing := Conf.SubtitleTiming
+	if !timing.Enabled {
+		return nil
+	}
+	for _, value := range []float64{timing.MinDuration, timing.MaxDuration, timing.MinGap, timing.MergeUnder, timing.MergeMaxGap, timing.FillGap, timing.ChainGap, timing.MaxCps, timing.MaxCpsAsian} {
+		if value < 0 {
+			return errors.New("subtitle_timing 中的时长、间隔和每秒字符数不能为负数")
+		}
+	}
+	if timing.MaxDuration > 0 && timing.MaxDuration < timing.MinDuration {
+		return errors.New("subtitle_timing.max_duration 不能小于 min_duration")
+	}
+	if timing.ShotChange && (timing.SceneThreshold <= 0 || timing.SceneThreshold >= 1 || timing.SnapTolerance <= 0 || timing.FramesBeforeCut < 0) {
+		return errors.New("开启镜头切换对齐时 subtitle_timing.scene_threshold 需要在0~1之间，snap_tolerance 需要大于0，frames_before_cut 不能为负数")
+	}
 	return nil
 }
 
@@ -279,13 +739,19 @@ func defaultConfig() Config {
 			TranscribeMaxAttempts: 3,
 			TranslateMaxAttempts:  3,
 			MaxSentenceLength:     70,
+			ConfidenceThreshold:   0.6,
+			PunctuationMode:       "none",
+			SegmentMode:           "punctuation",
+			MaxSentenceDuration:   7,
 		},
 		Server: Server{
 			Host: "127.0.0.1",
 			Port: 8888,
 		},
-		Llm: OpenaiCompatibleConfig{
-			Model: "gpt-4o-mini",
+		Llm: LlmConfig{
+			OpenaiCompatibleConfig: OpenaiCompatibleConfig{
+				Model: "gpt-4o-mini",
+			},
 		},
 		Transcribe: Transcribe{
 			Provider:              "openai",
@@ -302,6 +768,10 @@ func defaultConfig() Config {
 			Whispercpp: LocalModelConfig{
 				Model: "large-v2",
 			},
+			WhispercppServer: WhisperServerConfig{
+				BaseUrl:       "http://127.0.0.1:8080",
+				InferencePath: "/inference",
+			},
 		},
 		Tts: Tts{
 			Provider: "openai",
@@ -325,6 +795,82 @@ func defaultConfig() Config {
 			MinClipDuration: 60,
 			MaxClipDuration: 600,
 		},
+		TranslationMemory: TranslationMemoryConfig{
+			Enabled:         false,
+			FuzzyThreshold:  0.75,
+			MaxFuzzyMatches: 5,
+		},
+		Translate: TranslateConfig{
+			Provider: "llm",
+			Mt: MachineTranslateConfig{
+				Api: "libretranslate",
+			},
+		},
+		TranslationQa: TranslationQaConfig{
+			Enabled:        false,
+			LlmJudge:       true,
+			MinJudgeScore:  3,
+			MinLengthRatio: 0.2,
+			MaxLengthRatio: 3,
+		},
+		LlmCalls: LlmCallsConfig{
+			Translate: LlmCallConfig{
+				SystemPrompt:   defaultLlmSystemPrompt,
+				Temperature:    0.9,
+				MaxTokens:      8192,
+				ResponseFormat: "text",
+			},
+			Split: LlmCallConfig{
+				SystemPrompt:   defaultLlmSystemPrompt,
+				Temperature:    0.9,
+				MaxTokens:      8192,
+				ResponseFormat: "text",
+			},
+			Summary: LlmCallConfig{
+				SystemPrompt:   defaultLlmSystemPrompt,
+				Temperature:    0.9,
+				MaxTokens:      8192,
+				ResponseFormat: "text",
+			},
+			Clipper: LlmCallConfig{
+				SystemPrompt:   defaultLlmSystemPrompt,
+				Temperature:    0.9,
+				MaxTokens:      8192,
+				ResponseFormat: "text",
+			},
+		},
+		Usage: UsageConfig{
+			Enabled:     true,
+			StreamUsage: false,
+			Currency:    "USD",
+		},
+		RateLimit: RateLimitConfig{
+			MaxRetries:        3,
+			BaseBackoffMs:     1000,
+			MaxBackoffSeconds: 60,
+		},
+		Condensation: CondensationConfig{
+			MaxCps:      17,
+			MaxCpsAsian: 9,
+		},
+		SubtitleTiming: SubtitleTimingConfig{
+			MinDuration: 1,
+			MaxDuration: 7,
+			MinGap:      0.083,
+			MergeUnder:  0.5,
+			MergeMaxGap: 0.3,
+			FillGap:     0.25,
+			ChainGap:    0.5,
+			MaxCps:      20,
+			MaxCpsAsian: 10,
+
+			SceneThreshold:  0.3,
+			SnapTolerance:   0.5,
+			FramesBeforeCut: 2,
+		},
+		Timeout: TimeoutConfig{
+			ConnectSeconds: 30,
+		},
 	}
 }
 
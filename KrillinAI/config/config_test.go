@@ -72,3 +72,43 @@ func TestSaveConfigCreatesParentDirs(t *testing.T) {
 		t.Fatalf("saved server port = %d, want %d", got.Server.Port, 9999)
 	}
 }
+
+func TestValidateLlmRoutesConfig(t *testing.T) {
+	old := Conf.Llm
+	t.Cleanup(func() { Conf.Llm = old })
+
+	var conf Config
+	if _, err := toml.Decode(`
+[llm]
+base_url = "https://default"
+model = "gpt-4o-mini"
+[llm.routes]
+split = "cheap"
+[[llm.profiles]]
+name = "cheap"
+model = "small-model"
+`, &conf); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if conf.Llm.BaseUrl != "https://default" || conf.Llm.Routes["split"] != "cheap" || conf.Llm.Profiles[0].Model != "small-model" {
+		t.Fatalf("unexpected llm config: %+v", conf.Llm)
+	}
+	Conf.Llm = conf.Llm
+	if err := validateLlmRoutesConfig(); err != nil {
+		t.Fatalf("validateLlmRoutesConfig() error: %v", err)
+	}
+
+	Conf.Llm.Routes = map[string]string{"split": "missing"}
+	if err := validateLlmRoutesConfig(); err == nil {
+		t.Fatalf("expected error for route to unknown profile")
+	}
+	Conf.Llm.Routes = map[string]string{"unknown": "cheap"}
+	if err := validateLlmRoutesConfig(); err == nil {
+		t.Fatalf("expected error for unknown call type")
+	}
+	Conf.Llm.Routes = nil
+	Conf.Llm.Profiles = []LlmProfile{{Name: "llm"}}
+	if err := validateLlmRoutesConfig(); err == nil {
+		t.Fatalf("expected error for reserved profile name")
+	}
+}
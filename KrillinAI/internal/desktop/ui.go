@@ -642,7 +642,7 @@ func createLlmConfigGroup() *fyne.Container {
 
 // 创建语音识别配置组
 func createTranscribeConfigGroup() *fyne.Container {
-	providerOptions := []string{"openai", "fasterwhisper", "whisperkit", "whispercpp", "aliyun"}
+	providerOptions := []string{"openai", "fasterwhisper", "whisperkit", "whispercpp", "whispercpp_server", "aliyun"}
 	providerSelect := widget.NewSelect(providerOptions, func(value string) {
 		config.Conf.Transcribe.Provider = value
 	})
@@ -664,6 +664,9 @@ func createTranscribeConfigGroup() *fyne.Container {
 	whisperCppModelEntry := StyledEntry("模型名称 Model name")
 	whisperCppModelEntry.Bind(binding.BindString(&config.Conf.Transcribe.Whispercpp.Model))
 
+	whisperCppServerUrlEntry := StyledEntry("http://127.0.0.1:8080")
+	whisperCppServerUrlEntry.Bind(binding.BindString(&config.Conf.Transcribe.WhispercppServer.BaseUrl))
+
 	aliyunOssKeyIdEntry := StyledEntry("阿里云 Aliyun Access Key ID")
 	aliyunOssKeyIdEntry.Bind(binding.BindString(&config.Conf.Transcribe.Aliyun.Oss.AccessKeyId))
 	aliyunOssKeySecretEntry := StyledPasswordEntry("阿里云 Aliyun Access Key Secret")
@@ -692,6 +695,8 @@ func createTranscribeConfigGroup() *fyne.Container {
 
 		widget.NewFormItem("WhisperCpp 模型 Model", whisperCppModelEntry),
 
+		widget.NewFormItem("WhisperCpp 服务地址 Server URL", whisperCppServerUrlEntry),
+
 		widget.NewFormItem("阿里云 Aliyun OSS Access Key ID", aliyunOssKeyIdEntry),
 		widget.NewFormItem("阿里云 Aliyun OSS Access Key Secret", aliyunOssKeySecretEntry),
 		widget.NewFormItem("阿里云 Aliyun OSS Bucket Name", aliyunOssBucketEntry),
@@ -0,0 +1,18 @@
+package dto
+
+// GlossaryTermReq 术语条目，do_not_translate 为 true 时译文保留原文，target 可为空；
+// target_language 为空时适用于所有目标语言
+type GlossaryTermReq struct {
+	Source         string `json:"source"`
+	Target         string `json:"target"`
+	CaseSensitive  bool   `json:"case_sensitive"`
+	DoNotTranslate bool   `json:"do_not_translate"`
+	TargetLanguage string `json:"target_language"`
+}
+
+// SaveGlossaryReq 创建或更新术语表，更新时术语列表整体替换
+type SaveGlossaryReq struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Terms       []GlossaryTermReq `json:"terms"`
+}
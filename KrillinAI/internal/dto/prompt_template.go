@@ -0,0 +1,36 @@
+package dto
+
+// PromptVariable 模板类型可用的变量，模板中以 {{.Name}} 引用
+type PromptVariable struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"` // string 或 int
+	Description string `json:"description"`
+	Required    bool   `json:"required"` // 模板中必须引用该变量
+}
+
+// PromptKind 模板类型，对应流程中一处大模型调用
+type PromptKind struct {
+	Kind        string           `json:"kind"`
+	Description string           `json:"description"`
+	Variables   []PromptVariable `json:"variables"`
+	Builtin     string           `json:"builtin"` // 内置模板内容
+}
+
+// SavePromptTemplateReq 创建或更新提示词模板，更新内容时生成新版本
+type SavePromptTemplateReq struct {
+	Name        string `json:"name"`
+	Kind        string `json:"kind"`
+	Description string `json:"description"`
+	Content     string `json:"content"`
+}
+
+// RenderPromptTemplateReq 校验并试渲染模板，variables 未提供的变量使用示例值
+type RenderPromptTemplateReq struct {
+	Kind      string         `json:"kind"`
+	Content   string         `json:"content"`
+	Variables map[string]any `json:"variables"`
+}
+
+type RenderPromptTemplateResData struct {
+	Prompt string `json:"prompt"`
+}
@@ -0,0 +1,19 @@
+package dto
+
+// ReplacementRuleReq 替换规则，scope 可选 source（翻译前替换原文）、target（翻译后替换译文）、tts（只替换配音文字），
+// languages 为空时对所有语言生效
+type ReplacementRuleReq struct {
+	Scope         string   `json:"scope"`
+	Pattern       string   `json:"pattern"`
+	Replacement   string   `json:"replacement"`
+	IsRegex       bool     `json:"is_regex"`
+	CaseSensitive bool     `json:"case_sensitive"`
+	Languages     []string `json:"languages"`
+}
+
+// SaveReplacementRuleSetReq 创建或更新替换规则集，规则按列表顺序执行，更新时整体替换
+type SaveReplacementRuleSetReq struct {
+	Name        string               `json:"name"`
+	Description string               `json:"description"`
+	Rules       []ReplacementRuleReq `json:"rules"`
+}
@@ -0,0 +1,14 @@
+package dto
+
+// SaveStyleProfileReq 创建或更新翻译风格，formality 可选 formal/neutral/informal，
+// do_not_translate_patterns 为正则表达式，原文中匹配到的内容保留不译
+type SaveStyleProfileReq struct {
+	Name                   string   `json:"name"`
+	Description            string   `json:"description"`
+	Tone                   string   `json:"tone"`
+	Formality              string   `json:"formality"`
+	Audience               string   `json:"audience"`
+	Honorifics             string   `json:"honorifics"`
+	DoNotTranslatePatterns []string `json:"do_not_translate_patterns"`
+	CustomInstructions     string   `json:"custom_instructions"`
+}
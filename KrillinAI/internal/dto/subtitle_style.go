@@ -0,0 +1,29 @@
+package dto
+
+// SubtitleTextStyleReq 字幕文字样式，字号、描边、阴影为视频高度的百分比，颜色为 #RRGGBB，为空或为0时使用默认值
+type SubtitleTextStyleReq struct {
+	FontName     string  `json:"font_name"`
+	FontSize     float64 `json:"font_size"`
+	Bold         bool    `json:"bold"`
+	Italic       bool    `json:"italic"`
+	PrimaryColor string  `json:"primary_color"`
+	OutlineColor string  `json:"outline_color"`
+	Outline      float64 `json:"outline"`
+	ShadowColor  string  `json:"shadow_color"`
+	Shadow       float64 `json:"shadow"`
+	Box          bool    `json:"box"`
+	BoxColor     string  `json:"box_color"`
+	BoxOpacity   float64 `json:"box_opacity"`
+}
+
+// SaveSubtitleStyleReq 创建或更新字幕样式预设，position 可选 bottom/middle/top，边距为视频宽度/高度的百分比，
+// major 为主字幕（译文或单语字幕）样式，minor 为双语字幕中原文的样式
+type SaveSubtitleStyleReq struct {
+	Name             string               `json:"name"`
+	Description      string               `json:"description"`
+	Position         string               `json:"position"`
+	MarginHorizontal float64              `json:"margin_horizontal"`
+	MarginVertical   float64              `json:"margin_vertical"`
+	Major            SubtitleTextStyleReq `json:"major"`
+	Minor            SubtitleTextStyleReq `json:"minor"`
+}
@@ -1,24 +1,67 @@
 package dto
 
+import (
+	"encoding/json"
+	"strings"
+)
+
 type StartVideoSubtitleTaskReq struct {
-	AppId                     uint32   `json:"app_id"`
-	Url                       string   `json:"url"`
-	AudioUrl                  string   `json:"audio_url"` // New: Optional separate audio file URL
-	OriginLanguage            string   `json:"origin_lang"`
-	TargetLang                string   `json:"target_lang"`
-	Bilingual                 uint8    `json:"bilingual"`
-	TranslationSubtitlePos    uint8    `json:"translation_subtitle_pos"`
-	ModalFilter               uint8    `json:"modal_filter"`
-	Tts                       uint8    `json:"tts"`
-	TtsVoiceCode              string   `json:"tts_voice_code"`
-	TtsVoiceCloneSrcFileUrl   string   `json:"tts_voice_clone_src_file_url"`
-	Replace                   []string `json:"replace"`
-	Language                  string   `json:"language"`
-	EmbedSubtitleVideoType    string   `json:"embed_subtitle_video_type"`
-	VerticalMajorTitle        string   `json:"vertical_major_title"`
-	VerticalMinorTitle        string   `json:"vertical_minor_title"`
-	OriginLanguageWordOneLine int      `json:"origin_language_word_one_line"`
-	ReuseTaskId               string   `json:"reuse_task_id"` // New: For retry/resume
+	AppId                     uint32              `json:"app_id"`
+	Url                       string              `json:"url"`
+	AudioUrl                  string              `json:"audio_url"` // New: Optional separate audio file URL
+	OriginLanguage            string              `json:"origin_lang"`
+	TargetLang                LanguageList        `json:"target_lang"` // 目标语言，可传多个，第一个为主语言
+	Bilingual                 uint8               `json:"bilingual"`
+	TranslationSubtitlePos    uint8               `json:"translation_subtitle_pos"`
+	ModalFilter               uint8               `json:"modal_filter"`
+	Tts                       uint8               `json:"tts"`
+	TtsVoiceCode              string              `json:"tts_voice_code"`
+	TtsVoiceCodes             map[string]string   `json:"tts_voice_codes"` // 按目标语言指定的配音音色，未指定的语言使用tts_voice_code
+	TtsVoiceCloneSrcFileUrl   string              `json:"tts_voice_clone_src_file_url"`
+	Replace                   []string            `json:"replace"`
+	Language                  string              `json:"language"`
+	EmbedSubtitleVideoType    string              `json:"embed_subtitle_video_type"`
+	VerticalMajorTitle        string              `json:"vertical_major_title"`
+	VerticalMinorTitle        string              `json:"vertical_minor_title"`
+	OriginLanguageWordOneLine int                 `json:"origin_language_word_one_line"`
+	ReuseTaskId               string              `json:"reuse_task_id"`            // New: For retry/resume
+	TranscriptFileUrl         string              `json:"transcript_file_url"`      // 用户上传的纯文本文稿（local:路径），提供时按文稿强制对齐
+	GlossaryIds               []uint64            `json:"glossary_ids"`             // 关联的术语表id，多个术语表中原文和目标语言都相同的术语以靠前的为准
+	PromptTemplates           map[string]string   `json:"prompt_templates"`         // 按模板类型选用的提示词模板名称，可写 name@version，未指定的类型使用配置默认
+	StyleProfileId            uint64              `json:"style_profile_id"`         // 翻译风格id，0表示不指定
+	ReplacementRuleSetIds     []uint64            `json:"replacement_rule_set_ids"` // 关联的替换规则集id，按列表顺序执行
+	SubtitleFormats           map[string][]string `json:"subtitle_formats"`         // 按字幕轨道 origin/target/bilingual 额外导出的格式：vtt、ass、ttml、sbv、json，SRT 始终生成
+	KaraokeMode               string              `json:"karaoke_mode"`             // 原文卡拉OK字幕：k 逐词切换高亮，kf 逐词渐变填充，为空不启用
+	KaraokeColor              string              `json:"karaoke_color"`            // 卡拉OK高亮颜色 #RRGGBB，默认黄色
+	SubtitleStyleId           uint64              `json:"subtitle_style_id"`        // 字幕嵌入视频时使用的样式预设id，0表示使用默认样式
+}
+
+// LanguageList 语言列表，JSON中既可以是字符串数组，也可以是单个字符串（多个语言用逗号分隔）
+type LanguageList []string
+
+func (l *LanguageList) UnmarshalJSON(data []byte) error {
+	var list []string
+	if err := json.Unmarshal(data, &list); err == nil {
+		*l = list
+		return nil
+	}
+	var single string
+	if err := json.Unmarshal(data, &single); err != nil {
+		return err
+	}
+	*l = NewLanguageList(single)
+	return nil
+}
+
+// NewLanguageList 解析逗号分隔的语言字符串
+func NewLanguageList(languages string) LanguageList {
+	list := make(LanguageList, 0)
+	for _, language := range strings.Split(languages, ",") {
+		if language = strings.TrimSpace(language); language != "" {
+			list = append(list, language)
+		}
+	}
+	return list
 }
 
 type StartVideoSubtitleTaskResData struct {
@@ -46,6 +89,7 @@ type VideoInfo struct {
 type SubtitleInfo struct {
 	Name        string `json:"name"`
 	DownloadUrl string `json:"download_url"`
+	Language    string `json:"language"` // 文件对应的语言标识，如zh_cn，bilingual
 }
 
 type GetVideoSubtitleTaskResData struct {
@@ -54,7 +98,9 @@ type GetVideoSubtitleTaskResData struct {
 	VideoInfo         *VideoInfo      `json:"video_info"`
 	SubtitleInfo      []*SubtitleInfo `json:"subtitle_info"`
 	TargetLanguage    string          `json:"target_language"`
+	TargetLanguages   []string        `json:"target_languages"`
 	SpeechDownloadUrl string          `json:"speech_download_url"`
+	Usage             *UsageSummary   `json:"usage,omitempty"` // 任务累计的模型用量和费用
 }
 
 type GetVideoSubtitleTaskRes struct {
@@ -0,0 +1,34 @@
+package dto
+
+// UsageSummary 用量合计，费用按配置的价格表计算，未配置价格的调用费用为0
+type UsageSummary struct {
+	Calls            int64   `json:"calls"`
+	PromptTokens     int64   `json:"prompt_tokens"`
+	CompletionTokens int64   `json:"completion_tokens"`
+	TotalTokens      int64   `json:"total_tokens"`
+	AudioSeconds     float64 `json:"audio_seconds"`
+	Characters       int64   `json:"characters"`
+	Cost             float64 `json:"cost"`
+	Currency         string  `json:"currency"`
+	EstimatedCalls   int64   `json:"estimated_calls"` // 服务端没有返回 token 用量、按文本长度估算的大模型调用次数
+}
+
+// GetUsageReq 用量查询条件，都可不填；since/until 为 unix 秒
+type GetUsageReq struct {
+	TaskId string `form:"task_id"`
+	Stage  string `form:"stage"`
+	Since  int64  `form:"since"`
+	Until  int64  `form:"until"`
+}
+
+type UsageGroup struct {
+	Provider string `json:"provider"`
+	Model    string `json:"model"`
+	Stage    string `json:"stage"`
+	*UsageSummary
+}
+
+type GetUsageResData struct {
+	Total  *UsageSummary `json:"total"`
+	Groups []*UsageGroup `json:"groups"`
+}
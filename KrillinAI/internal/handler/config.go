@@ -49,6 +49,11 @@ type ConfigRequest struct {
 		Whispercpp struct {
 			Model string `json:"model"`
 		} `json:"whispercpp"`
+		WhispercppServer struct {
+			BaseUrl       string `json:"baseUrl"`
+			InferencePath string `json:"inferencePath"`
+			Model         string `json:"model"`
+		} `json:"whispercppServer"`
 		Aliyun struct {
 			Oss struct {
 				AccessKeyId     string `json:"accessKeyId"`
@@ -134,6 +139,9 @@ func (h Handler) GetConfig(c *gin.Context) {
 	configResponse.Transcribe.Fasterwhisper.Model = config.Conf.Transcribe.Fasterwhisper.Model
 	configResponse.Transcribe.Whisperkit.Model = config.Conf.Transcribe.Whisperkit.Model
 	configResponse.Transcribe.Whispercpp.Model = config.Conf.Transcribe.Whispercpp.Model
+	configResponse.Transcribe.WhispercppServer.BaseUrl = config.Conf.Transcribe.WhispercppServer.BaseUrl
+	configResponse.Transcribe.WhispercppServer.InferencePath = config.Conf.Transcribe.WhispercppServer.InferencePath
+	configResponse.Transcribe.WhispercppServer.Model = config.Conf.Transcribe.WhispercppServer.Model
 	configResponse.Transcribe.Aliyun.Oss.AccessKeyId = config.Conf.Transcribe.Aliyun.Oss.AccessKeyId
 	configResponse.Transcribe.Aliyun.Oss.AccessKeySecret = config.Conf.Transcribe.Aliyun.Oss.AccessKeySecret
 	configResponse.Transcribe.Aliyun.Oss.Bucket = config.Conf.Transcribe.Aliyun.Oss.Bucket
@@ -208,6 +216,9 @@ func (h Handler) UpdateConfig(c *gin.Context) {
 	config.Conf.Transcribe.Fasterwhisper.Model = req.Transcribe.Fasterwhisper.Model
 	config.Conf.Transcribe.Whisperkit.Model = req.Transcribe.Whisperkit.Model
 	config.Conf.Transcribe.Whispercpp.Model = req.Transcribe.Whispercpp.Model
+	config.Conf.Transcribe.WhispercppServer.BaseUrl = req.Transcribe.WhispercppServer.BaseUrl
+	config.Conf.Transcribe.WhispercppServer.InferencePath = req.Transcribe.WhispercppServer.InferencePath
+	config.Conf.Transcribe.WhispercppServer.Model = req.Transcribe.WhispercppServer.Model
 	config.Conf.Transcribe.Aliyun.Oss.AccessKeyId = req.Transcribe.Aliyun.Oss.AccessKeyId
 	config.Conf.Transcribe.Aliyun.Oss.AccessKeySecret = req.Transcribe.Aliyun.Oss.AccessKeySecret
 	config.Conf.Transcribe.Aliyun.Oss.Bucket = req.Transcribe.Aliyun.Oss.Bucket
@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"errors"
+	"krillin-ai/internal/dto"
+	"krillin-ai/internal/response"
+	"krillin-ai/internal/storage"
+	"krillin-ai/internal/types"
+	"krillin-ai/log"
+	apperrors "krillin-ai/pkg/errors"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func (h Handler) ListGlossaries(c *gin.Context) {
+	glossaries, err := storage.ListGlossaries()
+	if err != nil {
+		log.GetLogger().Error("ListGlossaries err", zap.Error(err))
+		response.ErrorResponse(c, apperrors.Wrap(apperrors.CodeDBError, "获取术语表失败 Failed to list glossaries", err))
+		return
+	}
+	response.Success(c, glossaries)
+}
+
+func (h Handler) GetGlossary(c *gin.Context) {
+	id, ok := parseGlossaryId(c)
+	if !ok {
+		return
+	}
+	glossary, err := storage.GetGlossary(id)
+	if err != nil {
+		response.ErrorResponse(c, glossaryStorageError(err))
+		return
+	}
+	response.Success(c, glossary)
+}
+
+func (h Handler) CreateGlossary(c *gin.Context) {
+	glossary, ok := bindGlossary(c)
+	if !ok {
+		return
+	}
+	if err := storage.CreateGlossary(glossary); err != nil {
+		log.GetLogger().Error("CreateGlossary err", zap.Error(err))
+		response.ErrorResponse(c, apperrors.Wrap(apperrors.CodeDBError, "保存术语表失败 Failed to save glossary", err))
+		return
+	}
+	response.Success(c, glossary)
+}
+
+func (h Handler) UpdateGlossary(c *gin.Context) {
+	id, ok := parseGlossaryId(c)
+	if !ok {
+		return
+	}
+	if _, err := storage.GetGlossary(id); err != nil {
+		response.ErrorResponse(c, glossaryStorageError(err))
+		return
+	}
+	glossary, ok := bindGlossary(c)
+	if !ok {
+		return
+	}
+	glossary.Id = id
+	if err := storage.UpdateGlossary(glossary); err != nil {
+		log.GetLogger().Error("UpdateGlossary err", zap.Uint64("id", id), zap.Error(err))
+		response.ErrorResponse(c, apperrors.Wrap(apperrors.CodeDBError, "保存术语表失败 Failed to save glossary", err))
+		return
+	}
+	response.Success(c, glossary)
+}
+
+func (h Handler) DeleteGlossary(c *gin.Context) {
+	id, ok := parseGlossaryId(c)
+	if !ok {
+		return
+	}
+	if err := storage.DeleteGlossary(id); err != nil {
+		log.GetLogger().Error("DeleteGlossary err", zap.Uint64("id", id), zap.Error(err))
+		response.ErrorResponse(c, apperrors.Wrap(apperrors.CodeDBError, "删除术语表失败 Failed to delete glossary", err))
+		return
+	}
+	response.Success(c, nil)
+}
+
+func parseGlossaryId(c *gin.Context) (uint64, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.ErrorResponse(c, apperrors.Wrap(apperrors.CodeInvalidParams, "术语表id不合法 Invalid glossary id", err))
+		return 0, false
+	}
+	return id, true
+}
+
+// bindGlossary 解析并校验请求体，失败时已写入响应
+func bindGlossary(c *gin.Context) (*types.Glossary, bool) {
+	var req dto.SaveGlossaryReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.GetLogger().Error("bindGlossary ShouldBindJSON err", zap.Error(err))
+		response.ErrorResponse(c, apperrors.Wrap(apperrors.CodeInvalidParams, "参数错误 Invalid parameters", err))
+		return nil, false
+	}
+	glossary := &types.Glossary{
+		Name:        strings.TrimSpace(req.Name),
+		Description: req.Description,
+		Terms:       make([]types.GlossaryTerm, 0, len(req.Terms)),
+	}
+	if glossary.Name == "" {
+		response.ErrorResponse(c, apperrors.New(apperrors.CodeInvalidParams, "术语表名称不能为空 Glossary name is required"))
+		return nil, false
+	}
+	for _, term := range req.Terms {
+		source, target := strings.TrimSpace(term.Source), strings.TrimSpace(term.Target)
+		if source == "" || (target == "" && !term.DoNotTranslate) {
+			response.ErrorResponse(c, apperrors.New(apperrors.CodeInvalidParams, "术语原文不能为空，且需要填写译法或标记为不翻译 Term source is required, and target is required unless do_not_translate is set"))
+			return nil, false
+		}
+		glossary.Terms = append(glossary.Terms, types.GlossaryTerm{
+			Source:         source,
+			Target:         target,
+			CaseSensitive:  term.CaseSensitive,
+			DoNotTranslate: term.DoNotTranslate,
+			TargetLanguage: strings.TrimSpace(term.TargetLanguage),
+		})
+	}
+	return glossary, true
+}
+
+func glossaryStorageError(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return apperrors.Wrap(apperrors.CodeNotFound, "术语表不存在 Glossary not found", err)
+	}
+	return apperrors.Wrap(apperrors.CodeDBError, "获取术语表失败 Failed to get glossary", err)
+}
@@ -0,0 +1,175 @@
+package handler
+
+import (
+	"errors"
+	"krillin-ai/config"
+	"krillin-ai/internal/dto"
+	"krillin-ai/internal/response"
+	"krillin-ai/internal/storage"
+	"krillin-ai/internal/types"
+	"krillin-ai/log"
+	apperrors "krillin-ai/pkg/errors"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ListPromptKinds 列出模板类型、可用变量和内置模板
+func (h Handler) ListPromptKinds(c *gin.Context) {
+	response.Success(c, h.Service.PromptKinds())
+}
+
+func (h Handler) ListPromptTemplates(c *gin.Context) {
+	templates, err := storage.ListPromptTemplates()
+	if err != nil {
+		log.GetLogger().Error("ListPromptTemplates err", zap.Error(err))
+		response.ErrorResponse(c, apperrors.Wrap(apperrors.CodeDBError, "获取提示词模板失败 Failed to list prompt templates", err))
+		return
+	}
+	response.Success(c, templates)
+}
+
+func (h Handler) GetPromptTemplate(c *gin.Context) {
+	id, ok := parsePromptTemplateId(c)
+	if !ok {
+		return
+	}
+	tmpl, err := storage.GetPromptTemplate(id)
+	if err != nil {
+		response.ErrorResponse(c, promptTemplateStorageError(err))
+		return
+	}
+	response.Success(c, tmpl)
+}
+
+func (h Handler) ListPromptTemplateVersions(c *gin.Context) {
+	id, ok := parsePromptTemplateId(c)
+	if !ok {
+		return
+	}
+	if _, err := storage.GetPromptTemplate(id); err != nil {
+		response.ErrorResponse(c, promptTemplateStorageError(err))
+		return
+	}
+	versions, err := storage.ListPromptTemplateVersions(id)
+	if err != nil {
+		log.GetLogger().Error("ListPromptTemplateVersions err", zap.Uint64("id", id), zap.Error(err))
+		response.ErrorResponse(c, apperrors.Wrap(apperrors.CodeDBError, "获取模板版本失败 Failed to list prompt template versions", err))
+		return
+	}
+	response.Success(c, versions)
+}
+
+func (h Handler) CreatePromptTemplate(c *gin.Context) {
+	tmpl, ok := h.bindPromptTemplate(c)
+	if !ok {
+		return
+	}
+	if err := storage.CreatePromptTemplate(tmpl); err != nil {
+		log.GetLogger().Error("CreatePromptTemplate err", zap.Error(err))
+		response.ErrorResponse(c, apperrors.Wrap(apperrors.CodeDBError, "保存提示词模板失败 Failed to save prompt template", err))
+		return
+	}
+	response.Success(c, tmpl)
+}
+
+// UpdatePromptTemplate 更新模板，内容有变化时生成新版本
+func (h Handler) UpdatePromptTemplate(c *gin.Context) {
+	id, ok := parsePromptTemplateId(c)
+	if !ok {
+		return
+	}
+	if _, err := storage.GetPromptTemplate(id); err != nil {
+		response.ErrorResponse(c, promptTemplateStorageError(err))
+		return
+	}
+	tmpl, ok := h.bindPromptTemplate(c)
+	if !ok {
+		return
+	}
+	tmpl.Id = id
+	if err := storage.UpdatePromptTemplate(tmpl); err != nil {
+		log.GetLogger().Error("UpdatePromptTemplate err", zap.Uint64("id", id), zap.Error(err))
+		response.ErrorResponse(c, apperrors.Wrap(apperrors.CodeDBError, "保存提示词模板失败 Failed to save prompt template", err))
+		return
+	}
+	response.Success(c, tmpl)
+}
+
+func (h Handler) DeletePromptTemplate(c *gin.Context) {
+	id, ok := parsePromptTemplateId(c)
+	if !ok {
+		return
+	}
+	if err := storage.DeletePromptTemplate(id); err != nil {
+		log.GetLogger().Error("DeletePromptTemplate err", zap.Uint64("id", id), zap.Error(err))
+		response.ErrorResponse(c, apperrors.Wrap(apperrors.CodeDBError, "删除提示词模板失败 Failed to delete prompt template", err))
+		return
+	}
+	response.Success(c, nil)
+}
+
+// RenderPromptTemplate 校验模板并用示例变量试渲染，不保存
+func (h Handler) RenderPromptTemplate(c *gin.Context) {
+	var req dto.RenderPromptTemplateReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ErrorResponse(c, apperrors.Wrap(apperrors.CodeInvalidParams, "参数错误 Invalid parameters", err))
+		return
+	}
+	data, err := h.Service.RenderPromptTemplate(req)
+	if err != nil {
+		response.ErrorResponse(c, apperrors.Wrap(apperrors.CodeInvalidParams, "提示词模板校验失败 Prompt template is invalid", err))
+		return
+	}
+	response.Success(c, data)
+}
+
+func parsePromptTemplateId(c *gin.Context) (uint64, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.ErrorResponse(c, apperrors.Wrap(apperrors.CodeInvalidParams, "模板id不合法 Invalid prompt template id", err))
+		return 0, false
+	}
+	return id, true
+}
+
+// bindPromptTemplate 解析请求体并校验模板内容，失败时已写入响应
+func (h Handler) bindPromptTemplate(c *gin.Context) (*types.PromptTemplate, bool) {
+	var req dto.SavePromptTemplateReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.GetLogger().Error("bindPromptTemplate ShouldBindJSON err", zap.Error(err))
+		response.ErrorResponse(c, apperrors.Wrap(apperrors.CodeInvalidParams, "参数错误 Invalid parameters", err))
+		return nil, false
+	}
+	tmpl := &types.PromptTemplate{
+		Name:        strings.TrimSpace(req.Name),
+		Kind:        req.Kind,
+		Description: req.Description,
+		Content:     req.Content,
+	}
+	if tmpl.Name == "" || strings.Contains(tmpl.Name, "@") {
+		response.ErrorResponse(c, apperrors.New(apperrors.CodeInvalidParams, "模板名称不能为空且不能包含@ Template name is required and must not contain @"))
+		return nil, false
+	}
+	for _, configTemplate := range config.Conf.Prompt.Templates {
+		if configTemplate.Name == tmpl.Name {
+			response.ErrorResponse(c, apperrors.New(apperrors.CodeInvalidParams, "模板名称已在配置文件中使用 Template name is already defined in config"))
+			return nil, false
+		}
+	}
+	if err := h.Service.ValidatePromptTemplate(tmpl.Kind, tmpl.Content); err != nil {
+		response.ErrorResponse(c, apperrors.Wrap(apperrors.CodeInvalidParams, "提示词模板校验失败 Prompt template is invalid", err))
+		return nil, false
+	}
+	return tmpl, true
+}
+
+func promptTemplateStorageError(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return apperrors.Wrap(apperrors.CodeNotFound, "提示词模板不存在 Prompt template not found", err)
+	}
+	return apperrors.Wrap(apperrors.CodeDBError, "获取提示词模板失败 Failed to get prompt template", err)
+}
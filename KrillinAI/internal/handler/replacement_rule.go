@@ -0,0 +1,160 @@
+package handler
+
+import (
+	"errors"
+	"krillin-ai/internal/dto"
+	"krillin-ai/internal/response"
+	"krillin-ai/internal/storage"
+	"krillin-ai/internal/types"
+	"krillin-ai/log"
+	apperrors "krillin-ai/pkg/errors"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func (h Handler) ListReplacementRuleSets(c *gin.Context) {
+	ruleSets, err := storage.ListReplacementRuleSets()
+	if err != nil {
+		log.GetLogger().Error("ListReplacementRuleSets err", zap.Error(err))
+		response.ErrorResponse(c, apperrors.Wrap(apperrors.CodeDBError, "获取替换规则失败 Failed to list replacement rule sets", err))
+		return
+	}
+	response.Success(c, ruleSets)
+}
+
+func (h Handler) GetReplacementRuleSet(c *gin.Context) {
+	id, ok := parseReplacementRuleSetId(c)
+	if !ok {
+		return
+	}
+	ruleSet, err := storage.GetReplacementRuleSet(id)
+	if err != nil {
+		response.ErrorResponse(c, replacementRuleSetStorageError(err))
+		return
+	}
+	response.Success(c, ruleSet)
+}
+
+func (h Handler) CreateReplacementRuleSet(c *gin.Context) {
+	ruleSet, ok := bindReplacementRuleSet(c)
+	if !ok {
+		return
+	}
+	if err := storage.CreateReplacementRuleSet(ruleSet); err != nil {
+		log.GetLogger().Error("CreateReplacementRuleSet err", zap.Error(err))
+		response.ErrorResponse(c, apperrors.Wrap(apperrors.CodeDBError, "保存替换规则失败 Failed to save replacement rule set", err))
+		return
+	}
+	response.Success(c, ruleSet)
+}
+
+func (h Handler) UpdateReplacementRuleSet(c *gin.Context) {
+	id, ok := parseReplacementRuleSetId(c)
+	if !ok {
+		return
+	}
+	if _, err := storage.GetReplacementRuleSet(id); err != nil {
+		response.ErrorResponse(c, replacementRuleSetStorageError(err))
+		return
+	}
+	ruleSet, ok := bindReplacementRuleSet(c)
+	if !ok {
+		return
+	}
+	ruleSet.Id = id
+	if err := storage.UpdateReplacementRuleSet(ruleSet); err != nil {
+		log.GetLogger().Error("UpdateReplacementRuleSet err", zap.Uint64("id", id), zap.Error(err))
+		response.ErrorResponse(c, apperrors.Wrap(apperrors.CodeDBError, "保存替换规则失败 Failed to save replacement rule set", err))
+		return
+	}
+	response.Success(c, ruleSet)
+}
+
+func (h Handler) DeleteReplacementRuleSet(c *gin.Context) {
+	id, ok := parseReplacementRuleSetId(c)
+	if !ok {
+		return
+	}
+	if err := storage.DeleteReplacementRuleSet(id); err != nil {
+		log.GetLogger().Error("DeleteReplacementRuleSet err", zap.Uint64("id", id), zap.Error(err))
+		response.ErrorResponse(c, apperrors.Wrap(apperrors.CodeDBError, "删除替换规则失败 Failed to delete replacement rule set", err))
+		return
+	}
+	response.Success(c, nil)
+}
+
+func parseReplacementRuleSetId(c *gin.Context) (uint64, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.ErrorResponse(c, apperrors.Wrap(apperrors.CodeInvalidParams, "替换规则集id不合法 Invalid replacement rule set id", err))
+		return 0, false
+	}
+	return id, true
+}
+
+// bindReplacementRuleSet 解析并校验请求体，规则的执行顺序取列表中的位置，失败时已写入响应
+func bindReplacementRuleSet(c *gin.Context) (*types.ReplacementRuleSet, bool) {
+	var req dto.SaveReplacementRuleSetReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.GetLogger().Error("bindReplacementRuleSet ShouldBindJSON err", zap.Error(err))
+		response.ErrorResponse(c, apperrors.Wrap(apperrors.CodeInvalidParams, "参数错误 Invalid parameters", err))
+		return nil, false
+	}
+	ruleSet := &types.ReplacementRuleSet{
+		Name:        strings.TrimSpace(req.Name),
+		Description: req.Description,
+		Rules:       make([]types.ReplacementRule, 0, len(req.Rules)),
+	}
+	if ruleSet.Name == "" {
+		response.ErrorResponse(c, apperrors.New(apperrors.CodeInvalidParams, "替换规则集名称不能为空 Replacement rule set name is required"))
+		return nil, false
+	}
+	for i, rule := range req.Rules {
+		switch rule.Scope {
+		case types.ReplacementScopeSource, types.ReplacementScopeTarget, types.ReplacementScopeTts:
+		default:
+			response.ErrorResponse(c, apperrors.New(apperrors.CodeInvalidParams, "替换规则的生效环节只能是 source、target 或 tts Rule scope must be source, target or tts"))
+			return nil, false
+		}
+		if rule.Pattern == "" {
+			response.ErrorResponse(c, apperrors.New(apperrors.CodeInvalidParams, "替换规则的匹配内容不能为空 Rule pattern is required"))
+			return nil, false
+		}
+		if rule.IsRegex {
+			if _, err := regexp.Compile(rule.Pattern); err != nil {
+				response.ErrorResponse(c, apperrors.Wrap(apperrors.CodeInvalidParams, "替换规则不是合法的正则表达式 Invalid rule regex", err))
+				return nil, false
+			}
+		}
+		languages := make([]string, 0, len(rule.Languages))
+		for _, language := range rule.Languages {
+			if _, ok := types.StandardLanguageCode2Name[types.StandardLanguageCode(language)]; !ok {
+				response.ErrorResponse(c, apperrors.New(apperrors.CodeInvalidParams, "替换规则的语言不支持 Unsupported rule language: "+language))
+				return nil, false
+			}
+			languages = append(languages, language)
+		}
+		ruleSet.Rules = append(ruleSet.Rules, types.ReplacementRule{
+			Position:      i,
+			Scope:         rule.Scope,
+			Pattern:       rule.Pattern,
+			Replacement:   rule.Replacement,
+			IsRegex:       rule.IsRegex,
+			CaseSensitive: rule.CaseSensitive,
+			Languages:     languages,
+		})
+	}
+	return ruleSet, true
+}
+
+func replacementRuleSetStorageError(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return apperrors.Wrap(apperrors.CodeNotFound, "替换规则集不存在 Replacement rule set not found", err)
+	}
+	return apperrors.Wrap(apperrors.CodeDBError, "获取替换规则失败 Failed to get replacement rule set", err)
+}
@@ -22,7 +22,7 @@ func (h Handler) AnalyzeVideo(c *gin.Context) {
 	}
 
 	svc := service.NewService()
-	data, err := svc.AnalyzeVideo(req)
+	data, err := svc.AnalyzeVideo(c.Request.Context(), req)
 	if err != nil {
 		log.GetLogger().Error("AnalyzeVideo failed", zap.Error(err))
 		c.JSON(http.StatusOK, dto.SmartClipperAnalyzeRes{
@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"errors"
+	"krillin-ai/internal/dto"
+	"krillin-ai/internal/response"
+	"krillin-ai/internal/storage"
+	"krillin-ai/internal/types"
+	"krillin-ai/log"
+	apperrors "krillin-ai/pkg/errors"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func (h Handler) ListStyleProfiles(c *gin.Context) {
+	profiles, err := storage.ListStyleProfiles()
+	if err != nil {
+		log.GetLogger().Error("ListStyleProfiles err", zap.Error(err))
+		response.ErrorResponse(c, apperrors.Wrap(apperrors.CodeDBError, "获取翻译风格失败 Failed to list style profiles", err))
+		return
+	}
+	response.Success(c, profiles)
+}
+
+func (h Handler) GetStyleProfile(c *gin.Context) {
+	id, ok := parseStyleProfileId(c)
+	if !ok {
+		return
+	}
+	profile, err := storage.GetStyleProfile(id)
+	if err != nil {
+		response.ErrorResponse(c, styleProfileStorageError(err))
+		return
+	}
+	response.Success(c, profile)
+}
+
+func (h Handler) CreateStyleProfile(c *gin.Context) {
+	profile, ok := bindStyleProfile(c)
+	if !ok {
+		return
+	}
+	if err := storage.CreateStyleProfile(profile); err != nil {
+		log.GetLogger().Error("CreateStyleProfile err", zap.Error(err))
+		response.ErrorResponse(c, apperrors.Wrap(apperrors.CodeDBError, "保存翻译风格失败 Failed to save style profile", err))
+		return
+	}
+	response.Success(c, profile)
+}
+
+func (h Handler) UpdateStyleProfile(c *gin.Context) {
+	id, ok := parseStyleProfileId(c)
+	if !ok {
+		return
+	}
+	if _, err := storage.GetStyleProfile(id); err != nil {
+		response.ErrorResponse(c, styleProfileStorageError(err))
+		return
+	}
+	profile, ok := bindStyleProfile(c)
+	if !ok {
+		return
+	}
+	profile.Id = id
+	if err := storage.UpdateStyleProfile(profile); err != nil {
+		log.GetLogger().Error("UpdateStyleProfile err", zap.Uint64("id", id), zap.Error(err))
+		response.ErrorResponse(c, apperrors.Wrap(apperrors.CodeDBError, "保存翻译风格失败 Failed to save style profile", err))
+		return
+	}
+	response.Success(c, profile)
+}
+
+func (h Handler) DeleteStyleProfile(c *gin.Context) {
+	id, ok := parseStyleProfileId(c)
+	if !ok {
+		return
+	}
+	if err := storage.DeleteStyleProfile(id); err != nil {
+		log.GetLogger().Error("DeleteStyleProfile err", zap.Uint64("id", id), zap.Error(err))
+		response.ErrorResponse(c, apperrors.Wrap(apperrors.CodeDBError, "删除翻译风格失败 Failed to delete style profile", err))
+		return
+	}
+	response.Success(c, nil)
+}
+
+func parseStyleProfileId(c *gin.Context) (uint64, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.ErrorResponse(c, apperrors.Wrap(apperrors.CodeInvalidParams, "翻译风格id不合法 Invalid style profile id", err))
+		return 0, false
+	}
+	return id, true
+}
+
+// bindStyleProfile 解析并校验请求体，失败时已写入响应
+func bindStyleProfile(c *gin.Context) (*types.StyleProfile, bool) {
+	var req dto.SaveStyleProfileReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.GetLogger().Error("bindStyleProfile ShouldBindJSON err", zap.Error(err))
+		response.ErrorResponse(c, apperrors.Wrap(apperrors.CodeInvalidParams, "参数错误 Invalid parameters", err))
+		return nil, false
+	}
+	profile := &types.StyleProfile{
+		Name:                   strings.TrimSpace(req.Name),
+		Description:            req.Description,
+		Tone:                   strings.TrimSpace(req.Tone),
+		Formality:              strings.ToLower(strings.TrimSpace(req.Formality)),
+		Audience:               strings.TrimSpace(req.Audience),
+		Honorifics:             strings.TrimSpace(req.Honorifics),
+		DoNotTranslatePatterns: make([]string, 0, len(req.DoNotTranslatePatterns)),
+		CustomInstructions:     strings.TrimSpace(req.CustomInstructions),
+	}
+	if profile.Name == "" {
+		response.ErrorResponse(c, apperrors.New(apperrors.CodeInvalidParams, "翻译风格名称不能为空 Style profile name is required"))
+		return nil, false
+	}
+	switch profile.Formality {
+	case "", types.StyleFormalityFormal, types.StyleFormalityNeutral, types.StyleFormalityInformal:
+	default:
+		response.ErrorResponse(c, apperrors.New(apperrors.CodeInvalidParams, "正式程度只能是 formal、neutral 或 informal Formality must be formal, neutral or informal"))
+		return nil, false
+	}
+	for _, pattern := range req.DoNotTranslatePatterns {
+		if strings.TrimSpace(pattern) == "" {
+			continue
+		}
+		if _, err := regexp.Compile(pattern); err != nil {
+			response.ErrorResponse(c, apperrors.Wrap(apperrors.CodeInvalidParams, "不翻译规则不是合法的正则表达式 Invalid do-not-translate pattern", err))
+			return nil, false
+		}
+		profile.DoNotTranslatePatterns = append(profile.DoNotTranslatePatterns, pattern)
+	}
+	return profile, true
+}
+
+func styleProfileStorageError(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return apperrors.Wrap(apperrors.CodeNotFound, "翻译风格不存在 Style profile not found", err)
+	}
+	return apperrors.Wrap(apperrors.CodeDBError, "获取翻译风格失败 Failed to get style profile", err)
+}
@@ -0,0 +1,184 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"krillin-ai/internal/dto"
+	"krillin-ai/internal/response"
+	"krillin-ai/internal/storage"
+	"krillin-ai/internal/types"
+	"krillin-ai/log"
+	apperrors "krillin-ai/pkg/errors"
+	"krillin-ai/pkg/subtitle"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func (h Handler) ListSubtitleStyles(c *gin.Context) {
+	styles, err := storage.ListSubtitleStyles()
+	if err != nil {
+		log.GetLogger().Error("ListSubtitleStyles err", zap.Error(err))
+		response.ErrorResponse(c, apperrors.Wrap(apperrors.CodeDBError, "获取字幕样式失败 Failed to list subtitle styles", err))
+		return
+	}
+	response.Success(c, styles)
+}
+
+func (h Handler) GetSubtitleStyle(c *gin.Context) {
+	id, ok := parseSubtitleStyleId(c)
+	if !ok {
+		return
+	}
+	style, err := storage.GetSubtitleStyle(id)
+	if err != nil {
+		response.ErrorResponse(c, subtitleStyleStorageError(err))
+		return
+	}
+	response.Success(c, style)
+}
+
+func (h Handler) CreateSubtitleStyle(c *gin.Context) {
+	style, ok := bindSubtitleStyle(c)
+	if !ok {
+		return
+	}
+	if err := storage.CreateSubtitleStyle(style); err != nil {
+		log.GetLogger().Error("CreateSubtitleStyle err", zap.Error(err))
+		response.ErrorResponse(c, apperrors.Wrap(apperrors.CodeDBError, "保存字幕样式失败 Failed to save subtitle style", err))
+		return
+	}
+	response.Success(c, style)
+}
+
+func (h Handler) UpdateSubtitleStyle(c *gin.Context) {
+	id, ok := parseSubtitleStyleId(c)
+	if !ok {
+		return
+	}
+	if _, err := storage.GetSubtitleStyle(id); err != nil {
+		response.ErrorResponse(c, subtitleStyleStorageError(err))
+		return
+	}
+	style, ok := bindSubtitleStyle(c)
+	if !ok {
+		return
+	}
+	style.Id = id
+	if err := storage.UpdateSubtitleStyle(style); err != nil {
+		log.GetLogger().Error("UpdateSubtitleStyle err", zap.Uint64("id", id), zap.Error(err))
+		response.ErrorResponse(c, apperrors.Wrap(apperrors.CodeDBError, "保存字幕样式失败 Failed to save subtitle style", err))
+		return
+	}
+	response.Success(c, style)
+}
+
+func (h Handler) DeleteSubtitleStyle(c *gin.Context) {
+	id, ok := parseSubtitleStyleId(c)
+	if !ok {
+		return
+	}
+	if err := storage.DeleteSubtitleStyle(id); err != nil {
+		log.GetLogger().Error("DeleteSubtitleStyle err", zap.Uint64("id", id), zap.Error(err))
+		response.ErrorResponse(c, apperrors.Wrap(apperrors.CodeDBError, "删除字幕样式失败 Failed to delete subtitle style", err))
+		return
+	}
+	response.Success(c, nil)
+}
+
+func parseSubtitleStyleId(c *gin.Context) (uint64, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.ErrorResponse(c, apperrors.Wrap(apperrors.CodeInvalidParams, "字幕样式id不合法 Invalid subtitle style id", err))
+		return 0, false
+	}
+	return id, true
+}
+
+// bindSubtitleStyle 解析并校验请求体，失败时已写入响应
+func bindSubtitleStyle(c *gin.Context) (*types.SubtitleStyle, bool) {
+	var req dto.SaveSubtitleStyleReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.GetLogger().Error("bindSubtitleStyle ShouldBindJSON err", zap.Error(err))
+		response.ErrorResponse(c, apperrors.Wrap(apperrors.CodeInvalidParams, "参数错误 Invalid parameters", err))
+		return nil, false
+	}
+	style := &types.SubtitleStyle{
+		Name:             strings.TrimSpace(req.Name),
+		Description:      req.Description,
+		Position:         strings.ToLower(strings.TrimSpace(req.Position)),
+		MarginHorizontal: req.MarginHorizontal,
+		MarginVertical:   req.MarginVertical,
+		Major:            subtitleTextStyle(req.Major),
+		Minor:            subtitleTextStyle(req.Minor),
+	}
+	if style.Name == "" {
+		response.ErrorResponse(c, apperrors.New(apperrors.CodeInvalidParams, "字幕样式名称不能为空 Subtitle style name is required"))
+		return nil, false
+	}
+	switch style.Position {
+	case "", types.SubtitlePositionBottom, types.SubtitlePositionMiddle, types.SubtitlePositionTop:
+	default:
+		response.ErrorResponse(c, apperrors.New(apperrors.CodeInvalidParams, "字幕位置只能是 bottom、middle 或 top Position must be bottom, middle or top"))
+		return nil, false
+	}
+	if style.MarginHorizontal < 0 || style.MarginHorizontal >= 50 || style.MarginVertical < 0 || style.MarginVertical >= 50 {
+		response.ErrorResponse(c, apperrors.New(apperrors.CodeInvalidParams, "边距必须在0到50%之间 Margins must be between 0 and 50 percent"))
+		return nil, false
+	}
+	for name, textStyle := range map[string]types.SubtitleTextStyle{"major": style.Major, "minor": style.Minor} {
+		if err := validateSubtitleTextStyle(textStyle); err != nil {
+			response.ErrorResponse(c, apperrors.Wrap(apperrors.CodeInvalidParams, "字幕文字样式不合法 Invalid "+name+" text style", err))
+			return nil, false
+		}
+	}
+	return style, true
+}
+
+func subtitleTextStyle(req dto.SubtitleTextStyleReq) types.SubtitleTextStyle {
+	return types.SubtitleTextStyle{
+		FontName:     strings.TrimSpace(req.FontName),
+		FontSize:     req.FontSize,
+		Bold:         req.Bold,
+		Italic:       req.Italic,
+		PrimaryColor: strings.TrimSpace(req.PrimaryColor),
+		OutlineColor: strings.TrimSpace(req.OutlineColor),
+		Outline:      req.Outline,
+		ShadowColor:  strings.TrimSpace(req.ShadowColor),
+		Shadow:       req.Shadow,
+		Box:          req.Box,
+		BoxColor:     strings.TrimSpace(req.BoxColor),
+		BoxOpacity:   req.BoxOpacity,
+	}
+}
+
+func validateSubtitleTextStyle(style types.SubtitleTextStyle) error {
+	if style.FontSize < 0 || style.FontSize > 50 {
+		return fmt.Errorf("font_size must be between 0 and 50 percent of video height")
+	}
+	if style.Outline < 0 || style.Outline > 10 || style.Shadow < 0 || style.Shadow > 10 {
+		return fmt.Errorf("outline and shadow must be between 0 and 10 percent of video height")
+	}
+	if style.BoxOpacity < 0 || style.BoxOpacity > 1 {
+		return fmt.Errorf("box_opacity must be between 0 and 1")
+	}
+	for _, color := range []string{style.PrimaryColor, style.OutlineColor, style.ShadowColor, style.BoxColor} {
+		if color == "" {
+			continue
+		}
+		if _, err := subtitle.AssColour(color); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func subtitleStyleStorageError(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return apperrors.Wrap(apperrors.CodeNotFound, "字幕样式不存在 Subtitle style not found", err)
+	}
+	return apperrors.Wrap(apperrors.CodeDBError, "获取字幕样式失败 Failed to get subtitle style", err)
+}
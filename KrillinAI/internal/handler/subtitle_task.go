@@ -182,7 +182,7 @@ func (h Handler) RetryTask(c *gin.Context) {
 		Url:                    task.VideoSrc,
 		ReuseTaskId:            task.TaskId,
 		OriginLanguage:         string(task.OriginLanguage),
-		TargetLang:             string(task.TargetLanguage),
+		TargetLang:             dto.NewLanguageList(task.TargetLanguage),
 		EmbedSubtitleVideoType: "all", // Force enable video generation (adaptive horizontal/vertical)
 		Bilingual:              1,     // Default to Bilingual Yes
 		Tts:                    1,     // Force Enable TTS for retry
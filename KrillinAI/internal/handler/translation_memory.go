@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"fmt"
+	"io"
+	"krillin-ai/internal/response"
+	"krillin-ai/log"
+	apperrors "krillin-ai/pkg/errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// ExportTranslationMemory 以 TMX 文件下载翻译记忆，可通过 source_lang / target_lang 过滤
+func (h Handler) ExportTranslationMemory(c *gin.Context) {
+	data, count, err := h.Service.ExportTranslationMemoryTmx(c.Query("source_lang"), c.Query("target_lang"))
+	if err != nil {
+		log.GetLogger().Error("ExportTranslationMemory err", zap.Error(err))
+		response.ErrorResponse(c, apperrors.Wrap(apperrors.CodeDBError, "导出翻译记忆失败 Failed to export translation memory", err))
+		return
+	}
+	log.GetLogger().Info("ExportTranslationMemory completed", zap.Int("entries", count))
+	fileName := fmt.Sprintf("translation_memory_%s.tmx", time.Now().Format("20060102150405"))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", fileName))
+	c.Data(http.StatusOK, "application/x-tmx+xml", data)
+}
+
+// ImportTranslationMemory 上传 TMX 文件（表单字段 file）导入翻译记忆
+func (h Handler) ImportTranslationMemory(c *gin.Context) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		response.ErrorResponse(c, apperrors.Wrap(apperrors.CodeInvalidParams, "未上传TMX文件 No TMX file uploaded", err))
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		response.ErrorResponse(c, apperrors.Wrap(apperrors.CodeFileNotFound, "读取TMX文件失败 Failed to read TMX file", err))
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		response.ErrorResponse(c, apperrors.Wrap(apperrors.CodeFileNotFound, "读取TMX文件失败 Failed to read TMX file", err))
+		return
+	}
+
+	count, err := h.Service.ImportTranslationMemoryTmx(data)
+	if err != nil {
+		log.GetLogger().Error("ImportTranslationMemory err", zap.String("file", fileHeader.Filename), zap.Error(err))
+		response.ErrorResponse(c, apperrors.Wrap(apperrors.CodeInvalidParams, "导入翻译记忆失败 Failed to import translation memory", err))
+		return
+	}
+	response.Success(c, gin.H{"imported": count})
+}
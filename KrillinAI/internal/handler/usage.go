@@ -0,0 +1,27 @@
+package handler
+
+import (
+	"krillin-ai/internal/dto"
+	"krillin-ai/internal/response"
+	"krillin-ai/log"
+	apperrors "krillin-ai/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// GetUsage 汇总模型用量和费用，可按任务、阶段、时间范围过滤
+func (h Handler) GetUsage(c *gin.Context) {
+	var req dto.GetUsageReq
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.ErrorResponse(c, apperrors.Wrap(apperrors.CodeInvalidParams, "参数错误 Invalid parameters", err))
+		return
+	}
+	data, err := h.Service.GetUsage(req)
+	if err != nil {
+		log.GetLogger().Error("GetUsage err", zap.Any("req", req), zap.Error(err))
+		response.ErrorResponse(c, apperrors.Wrap(apperrors.CodeDBError, "查询用量失败 Failed to query usage", err))
+		return
+	}
+	response.Success(c, data)
+}
@@ -2,6 +2,8 @@
 package mocks
 
 import (
+	"context"
+
 	"krillin-ai/internal/types"
 
 	"github.com/stretchr/testify/mock"
@@ -12,7 +14,7 @@ type MockTranscriber struct {
 	mock.Mock
 }
 
-func (m *MockTranscriber) Transcription(audioFile, language, wordDir string) (*types.TranscriptionData, error) {
+func (m *MockTranscriber) Transcription(ctx context.Context, audioFile, language, wordDir string) (*types.TranscriptionData, error) {
 	args := m.Called(audioFile, language, wordDir)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -25,7 +27,7 @@ type MockChatCompleter struct {
 	mock.Mock
 }
 
-func (m *MockChatCompleter) ChatCompletion(query string) (string, error) {
+func (m *MockChatCompleter) ChatCompletion(ctx context.Context, query string) (string, error) {
 	args := m.Called(query)
 	return args.String(0), args.Error(1)
 }
@@ -35,7 +37,7 @@ type MockTtser struct {
 	mock.Mock
 }
 
-func (m *MockTtser) Text2Speech(text string, voice string, outputFile string) error {
+func (m *MockTtser) Text2Speech(ctx context.Context, text string, voice string, outputFile string) error {
 	args := m.Called(text, voice, outputFile)
 	return args.Error(0)
 }
@@ -42,6 +42,44 @@ func SetupRouter(r *gin.Engine) {
 		api.GET("/cookie/status", hdl.GetCookieStatus)
 		api.POST("/cookie/upload", hdl.UploadCookie)
 		api.POST("/cookie/validate", hdl.ValidateCookie)
+		// Glossary Routes
+		api.GET("/glossary", hdl.ListGlossaries)
+		api.POST("/glossary", hdl.CreateGlossary)
+		api.GET("/glossary/:id", hdl.GetGlossary)
+		api.PUT("/glossary/:id", hdl.UpdateGlossary)
+		api.DELETE("/glossary/:id", hdl.DeleteGlossary)
+		// Translation Memory Routes
+		api.GET("/translation_memory/export", hdl.ExportTranslationMemory)
+		api.POST("/translation_memory/import", hdl.ImportTranslationMemory)
+		// Usage Routes
+		api.GET("/usage", hdl.GetUsage)
+		// Prompt Template Routes
+		api.GET("/prompt_template/kinds", hdl.ListPromptKinds)
+		api.POST("/prompt_template/render", hdl.RenderPromptTemplate)
+		api.GET("/prompt_template", hdl.ListPromptTemplates)
+		api.POST("/prompt_template", hdl.CreatePromptTemplate)
+		api.GET("/prompt_template/:id", hdl.GetPromptTemplate)
+		api.PUT("/prompt_template/:id", hdl.UpdatePromptTemplate)
+		api.DELETE("/prompt_template/:id", hdl.DeletePromptTemplate)
+		api.GET("/prompt_template/:id/versions", hdl.ListPromptTemplateVersions)
+		// Style Profile Routes
+		api.GET("/style_profile", hdl.ListStyleProfiles)
+		api.POST("/style_profile", hdl.CreateStyleProfile)
+		api.GET("/style_profile/:id", hdl.GetStyleProfile)
+		api.PUT("/style_profile/:id", hdl.UpdateStyleProfile)
+		api.DELETE("/style_profile/:id", hdl.DeleteStyleProfile)
+		// Replacement Rule Routes
+		api.GET("/replacement_rule_set", hdl.ListReplacementRuleSets)
+		api.POST("/replacement_rule_set", hdl.CreateReplacementRuleSet)
+		api.GET("/replacement_rule_set/:id", hdl.GetReplacementRuleSet)
+		api.PUT("/replacement_rule_set/:id", hdl.UpdateReplacementRuleSet)
+		api.DELETE("/replacement_rule_set/:id", hdl.DeleteReplacementRuleSet)
+		// Subtitle Style Routes
+		api.GET("/subtitle_style", hdl.ListSubtitleStyles)
+		api.POST("/subtitle_style", hdl.CreateSubtitleStyle)
+		api.GET("/subtitle_style/:id", hdl.GetSubtitleStyle)
+		api.PUT("/subtitle_style/:id", hdl.UpdateSubtitleStyle)
+		api.DELETE("/subtitle_style/:id", hdl.DeleteSubtitleStyle)
 	}
 
 	r.GET("/", func(c *gin.Context) {
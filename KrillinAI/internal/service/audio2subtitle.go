@@ -10,6 +10,7 @@ import (
 	"krillin-ai/internal/storage"
 	"krillin-ai/internal/types"
 	"krillin-ai/log"
+	"krillin-ai/pkg/ratelimit"
 	"krillin-ai/pkg/util"
 	"os"
 	"path/filepath"
@@ -29,18 +30,60 @@ import (
 type TranslatedItem struct {
 	OriginText     string
 	TranslatedText string
+	CondensedText  string `json:",omitempty"` // 译文超出阅读速度时的精简译文，为空表示不需要精简
+}
+
+// SubtitleText 字幕和配音使用的译文，有精简译文时优先使用
+func (t *TranslatedItem) SubtitleText() string {
+	if t.CondensedText != "" {
+		return t.CondensedText
+	}
+	return t.TranslatedText
 }
 
 func (s Service) audioToSubtitle(ctx context.Context, stepParam *types.SubtitleTaskStepParam) error {
 	var err error
-	err = s.audioToSrt(ctx, stepParam) // 这里进度更新到90%了
+	if stepParam.UserTranscriptFilePath != "" {
+		// 用户提供了文稿，按文稿强制对齐
+		err = s.alignTranscriptToSrt(ctx, stepParam)
+		if err != nil {
+			return fmt.Errorf("audioToSubtitle alignTranscriptToSrt error: %w", err)
+		}
+	} else {
+		err = s.audioToSrt(ctx, stepParam) // 这里进度更新到90%了
+		if err != nil {
+			return fmt.Errorf("audioToSubtitle audioToSrt error: %w", err)
+		}
+	}
+	// 翻译质检，失败时保留原译文继续，但不写回翻译记忆
+	qaReport, err := s.reviewBilingualSrt(ctx, stepParam)
 	if err != nil {
-		return fmt.Errorf("audioToSubtitle audioToSrt error: %w", err)
+		log.GetLogger().Error("audioToSubtitle reviewBilingualSrt error", zap.Any("taskId", stepParam.TaskId), zap.Error(err))
+	} else {
+		saveTranslationMemory(stepParam, qaReport)
+	}
+	// 时间轴规整在拆分单语字幕之前，配音和嵌入视频都使用规整后的时间轴
+	err = normalizeSubtitleTiming(stepParam)
+	if err != nil {
+		return fmt.Errorf("audioToSubtitle normalizeSubtitleTiming error: %w", err)
 	}
 	err = splitSrt(stepParam)
 	if err != nil {
 		return fmt.Errorf("audioToSubtitle splitSrt error: %w", err)
 	}
+	err = exportSubtitleFormats(stepParam)
+	if err != nil {
+		return fmt.Errorf("audioToSubtitle exportSubtitleFormats error: %w", err)
+	}
+	// 其他目标语言复用原文字幕并行翻译
+	err = s.translateExtraLanguages(ctx, stepParam)
+	if err != nil {
+		return fmt.Errorf("audioToSubtitle translateExtraLanguages error: %w", err)
+	}
+	// 低置信度复核报告，失败不影响主流程
+	if err = generateConfidenceReview(stepParam); err != nil {
+		log.GetLogger().Error("audioToSubtitle generateConfidenceReview error", zap.Any("taskId", stepParam.TaskId), zap.Error(err))
+	}
 	// 更新字幕任务信息
 	stepParam.TaskPtr.ProcessPct = 95
 	return nil
@@ -92,7 +135,7 @@ func (s Service) audioToSubtitle(ctx context.Context, stepParam *types.SubtitleT
 //	return nil
 //}
 
-func (s Service) transcribeAudio(id int, audioFilePath string, language string, taskBasePath string) (transcriptionData *types.TranscriptionData, err error) {
+func (s Service) transcribeAudio(ctx context.Context, id int, audioFilePath string, language string, taskBasePath string) (transcriptionData *types.TranscriptionData, err error) {
 	defer func() {
 		if r := recover(); r != nil {
 			err = fmt.Errorf("audioToSubtitle transcribeAudio panic recovered: %v", r)
@@ -102,7 +145,7 @@ func (s Service) transcribeAudio(id int, audioFilePath string, language string,
 	if language == "zh_cn" {
 		language = "zh" // 切换一下
 	}
-	transcriptionData, err = s.Transcriber.Transcription(audioFilePath, language, taskBasePath)
+	transcriptionData, err = s.Transcriber.Transcription(ctx, audioFilePath, language, taskBasePath)
 
 	if err != nil {
 		return nil, fmt.Errorf("audioToSubtitle transcribeAudio Transcription err: %w", err)
@@ -142,13 +185,21 @@ func formatDuration(d time.Duration) string {
 	return fmt.Sprintf("%d秒 / %ds", seconds, seconds)
 }
 
-func (s Service) splitTextAndTranslateV2(basePath, inputText string, originLang, targetLang types.StandardLanguageCode, enableModalFilter bool, id int) ([]*TranslatedItem, error) {
-	sentences := util.SplitTextSentences(inputText, config.Conf.App.MaxSentenceLength)
+func (s Service) splitTextAndTranslateV2(ctx context.Context, stepParam *types.SubtitleTaskStepParam, inputText string, words []types.Word, id int) ([]*TranslatedItem, error) {
+	originLang := stepParam.OriginLanguage
+	// 先恢复标点，再基于标点分句；开启停顿分句时结合词级时间戳切分
+	inputText = s.restorePunctuation(ctx, inputText, words, originLang)
+	var sentences []string
+	if pauseSegmentEnabled() && len(words) > 0 {
+		sentences = segmentTextByPause(inputText, words, originLang)
+	} else {
+		sentences = util.SplitTextSentences(inputText, config.Conf.App.MaxSentenceLength)
+	}
 	if len(sentences) == 0 {
 		return []*TranslatedItem{}, nil
 	}
-	// 补丁：whisper转录中文的时候很多句子后面不输出符号，导致上面基于符号的切分失效
-	if s.IsSplitUseSpace(originLang) {
+	// 补丁：whisper转录中文的时候很多句子后面不输出符号，导致上面基于符号的切分失效；开启标点恢复或停顿分句后不再需要
+	if !punctuationEnabled() && !pauseSegmentEnabled() && s.IsSplitUseSpace(originLang) {
 		newSentences := make([]string, 0)
 		for _, sentence := range sentences {
 			newSentences = append(newSentences, strings.Split(sentence, " ")...)
@@ -168,7 +219,7 @@ func (s Service) splitTextAndTranslateV2(basePath, inputText string, originLang,
 		}
 
 		// 递归拆分长句子直到满足长度要求，保持顺序
-		splitSentences, err := s.splitSentenceRecursively(sentence, 0, 5) // 最多5层递归
+		splitSentences, err := s.splitSentenceRecursively(ctx, sentence, 0, 5) // 最多5层递归
 		if err != nil {
 			log.GetLogger().Error("splitSentenceRecursively error", zap.Error(err), zap.Any("sentence", sentence))
 			// 如果拆分失败，直接添加原句子
@@ -178,27 +229,77 @@ func (s Service) splitTextAndTranslateV2(basePath, inputText string, originLang,
 		}
 	}
 
-	sentences = shortSentences
+	// 拆句之后再替换原文，替换后的文字只用于翻译，条目保留识别原文以便对齐时间戳
+	origins, inputs := sourceTranslationInputs(stepParam, shortSentences)
+	items := s.translateSentences(ctx, stepParam, inputs, stepParam.TargetLanguage, id)
+	for i, item := range items {
+		if item != nil {
+			item.OriginText = origins[i]
+		}
+	}
+	return items, nil
+}
 
+// translateSentences 按批次并发翻译已经拆分好的句子，结果与输入顺序一一对应；
+// 任务关联了术语表时，只注入本批次出现的术语，并在译文未使用规定译法时带上违规说明重新翻译。
+// 翻译记忆中完全相同的句子直接复用，相似句子的历史译文作为参考，成功的译文暂存起来，质检之后写回翻译记忆。
+// 开启携带上下文时逐批翻译，每批附上前文及其译文
+func (s Service) translateSentences(ctx context.Context, stepParam *types.SubtitleTaskStepParam, sentences []string, targetLang types.StandardLanguageCode, id int) []*TranslatedItem {
+	parallelNum := config.Conf.App.TranslateParallelNum
+	if translateContextEnabled() {
+		parallelNum = 1 // 需要前一批已采用的译文作为上下文
+	}
 	var (
-		signal           = make(chan struct{}, config.Conf.App.TranslateParallelNum) // 控制最大并发数
+		signal           = make(chan struct{}, parallelNum) // 控制最大并发数
 		wg               sync.WaitGroup
+		mutex            sync.Mutex
 		results          = make([]*TranslatedItem, len(sentences))
-		completedBatches int32 // 原子计数器
+		accepted         = make([]bool, len(sentences)) // 译文是否可信，翻译失败回退原文的不算
+		completedBatches int32                          // 原子计数器
+		memoryItems      = make([]*TranslatedItem, 0)   // 质检后需要写回翻译记忆的译文
+		runningSummary   string                         // 携带上下文时维护的内容摘要
 		// errChan = make(chan error, 1)
-		// mutex   sync.Mutex
 	)
 
+	// 只使用适用于本次目标语言的术语
+	glossaryTerms := glossaryTermsForLanguage(stepParam.GlossaryTerms, targetLang)
+
+	// 翻译记忆精确命中的句子不再交给大模型，命中但不符合当前术语表或风格不翻译规则的不算命中
+	pendingSentences := make([]string, 0, len(sentences))
+	pendingIndexes := make([]int, 0, len(sentences))
+	memoryHits := lookupTranslationMemory(sentences, targetLang, memoryStyleProfileId(stepParam))
+	for i, sentence := range sentences {
+		translated, ok := memoryHits[i]
+		sentenceTerms := append(matchGlossaryTerms([]string{sentence}, glossaryTerms), styleDoNotTranslateTerms([]string{sentence}, stepParam.StyleProfile, glossaryTerms)...)
+		if ok && len(findGlossaryViolations([]string{sentence}, []string{translated}, sentenceTerms)) == 0 {
+			results[i] = &TranslatedItem{
+				OriginText:     sentence,
+				TranslatedText: translated,
+			}
+			accepted[i] = true
+			continue
+		}
+		pendingSentences = append(pendingSentences, sentence)
+		pendingIndexes = append(pendingIndexes, i)
+	}
+	if len(memoryHits) > 0 {
+		log.GetLogger().Info("translateSentences translation memory hits", zap.Int("splitId", id),
+			zap.Int("hits", len(sentences)-len(pendingSentences)), zap.Int("total", len(sentences)))
+	}
+
+	translator := s.translatorFor(stepParam.OriginLanguage, targetLang)
+	_, promptable := translator.(LlmTranslator) // 只有大模型能理解术语表、上下文等附加要求
+
 	batchSize := 20
-	totalBatches := (len(sentences) + batchSize - 1) / batchSize // 向上取整
+	totalBatches := (len(pendingSentences) + batchSize - 1) / batchSize // 向上取整
 
-	for i := 0; i < len(sentences); i += batchSize {
+	for i := 0; i < len(pendingSentences); i += batchSize {
 		end := i + batchSize
-		if end > len(sentences) {
-			end = len(sentences)
+		if end > len(pendingSentences) {
+			end = len(pendingSentences)
 		}
 
-		batchSentences := sentences[i:end]
+		batchSentences := pendingSentences[i:end]
 		wg.Add(1)
 		signal <- struct{}{}
 
@@ -206,38 +307,44 @@ func (s Service) splitTextAndTranslateV2(basePath, inputText string, originLang,
 			defer wg.Done()
 			defer func() { <-signal }()
 
-			// 构造 JSON Input
-			inputBytes, _ := json.Marshal(batch)
-			inputStr := string(inputBytes)
-
-			prompt := fmt.Sprintf(types.BatchTranslatePrompt, types.GetStandardLanguageName(targetLang), inputStr)
+			// 风格中的不翻译规则按保留原文的术语处理，同样会校验和重试
+			batchTerms := append(matchGlossaryTerms(batch, glossaryTerms), styleDoNotTranslateTerms(batch, stepParam.StyleProfile, glossaryTerms)...)
+			extraPrompt := buildStylePrompt(stepParam.StyleProfile) + buildGlossaryPrompt(batchTerms) + buildMemoryPrompt(findFuzzyMemories(batch, targetLang, memoryStyleProfileId(stepParam)))
+			if translateContextEnabled() {
+				// 逐批翻译，此时前面的批次都已经完成
+				contextLines := precedingContextLines(results, accepted, pendingIndexes[startIndex], config.Conf.App.TranslateContextLines)
+				extraPrompt = buildTranslateContextPrompt(contextLines, runningSummary) + extraPrompt
+			}
+			instructions := extraPrompt
 
 			var translatedBatch []string
+			var glossaryMismatchBatch []string // 数量正确但术语不符合的结果，重试都失败时仍然使用
 			var err error
 
 			// Retry logic for the batch
 			for attempt := 0; attempt < config.Conf.App.TranslateMaxAttempts; attempt++ {
-				var responseText string
-				responseText, err = s.ChatCompleter.ChatCompletion(prompt)
+				translatedBatch, err = translator.Translate(ctx, batch, stepParam.OriginLanguage, targetLang, instructions)
 				if err == nil {
-					// 尝试解析JSON
-					// 有时候LLM会返回 markdown 代码块，需要去掉 `json ... `
-					cleanText := strings.TrimSpace(responseText)
-					cleanText = strings.TrimPrefix(cleanText, "```json")
-					cleanText = strings.TrimPrefix(cleanText, "```")
-					cleanText = strings.TrimSuffix(cleanText, "```")
-					cleanText = strings.TrimSpace(cleanText)
-
-					err = json.Unmarshal([]byte(cleanText), &translatedBatch)
-					if err == nil {
-						if len(translatedBatch) == len(batch) {
-							break // Success
-						}
-						err = fmt.Errorf("batch size mismatch: expected %d, got %d", len(batch), len(translatedBatch))
+					violations := findGlossaryViolations(batch, translatedBatch, batchTerms)
+					if len(violations) == 0 {
+						break // Success
 					}
+					glossaryMismatchBatch = translatedBatch
+					err = fmt.Errorf("glossary terms missing: %d", len(violations))
+					if !promptable {
+						break // 机器翻译无法按要求重译，重试没有意义
+					}
+					instructions = extraPrompt + fmt.Sprintf(types.BatchTranslateGlossaryRetryPrompt, strings.Join(violations, "\n"))
 				}
 				log.GetLogger().Warn("batch translate retry", zap.Int("attempt", attempt+1), zap.Error(err))
-				time.Sleep(time.Duration(attempt+1) * time.Second)
+				if attempt+1 < config.Conf.App.TranslateMaxAttempts {
+					time.Sleep(ratelimit.Backoff(attempt))
+				}
+			}
+			glossaryFollowed := err == nil
+			if err != nil && glossaryMismatchBatch != nil {
+				log.GetLogger().Warn("batch translate glossary not fully followed, use last translation", zap.Int("batchIndex", startIndex), zap.Error(err))
+				translatedBatch, err = glossaryMismatchBatch, nil
 			}
 
 			// 更新批次完成计数
@@ -250,7 +357,7 @@ func (s Service) splitTextAndTranslateV2(basePath, inputText string, originLang,
 
 			// Fill results
 			for j, originText := range batch {
-				globalIndex := startIndex + j
+				globalIndex := pendingIndexes[startIndex+j]
 				if err != nil || len(translatedBatch) <= j {
 					// Fallback to original text on failure
 					if err != nil {
@@ -265,15 +372,25 @@ func (s Service) splitTextAndTranslateV2(basePath, inputText string, originLang,
 						OriginText:     originText,
 						TranslatedText: translatedBatch[j],
 					}
+					accepted[globalIndex] = true
+					if glossaryFollowed {
+						mutex.Lock()
+						memoryItems = append(memoryItems, results[globalIndex])
+						mutex.Unlock()
+					}
 				}
 			}
+			if translateContextEnabled() && promptable {
+				runningSummary = s.updateRunningSummary(ctx, runningSummary, batch)
+			}
 		}(i, batchSentences, i/batchSize)
 	}
 
 	wg.Wait()
 	// close(errChan)
 
-	return results, nil
+	queueTranslationMemory(stepParam, targetLang, memoryItems)
+	return results
 }
 
 func (s Service) audioToSrt(ctx context.Context, stepParam *types.SubtitleTaskStepParam) (err error) {
@@ -398,7 +515,7 @@ func (s Service) audioToSrt(ctx context.Context, stepParam *types.SubtitleTaskSt
 					// If no valid existing data, proceed with Whisper
 					log.GetLogger().Info("Begin to transcribe", zap.Any("taskId", stepParam.TaskId), zap.Any("splitId", item.Id))
 					for range config.Conf.App.TranscribeMaxAttempts {
-						transcriptionData, err = s.transcribeAudio(item.Id, item.Data, string(stepParam.OriginLanguage), stepParam.TaskBasePath)
+						transcriptionData, err = s.transcribeAudio(ctx, item.Id, item.Data, string(stepParam.OriginLanguage), stepParam.TaskBasePath)
 						if err == nil {
 							break
 						}
@@ -473,7 +590,7 @@ func (s Service) audioToSrt(ctx context.Context, stepParam *types.SubtitleTaskSt
 					// No valid existing data, perform translation
 					log.GetLogger().Info("Begin to translate", zap.Any("taskId", stepParam.TaskId), zap.Any("splitId", translateItem.Id))
 					for range config.Conf.App.TranslateMaxAttempts {
-						translatedResults, err = s.splitTextAndTranslateV2(stepParam.TaskBasePath, translateItem.Data, stepParam.OriginLanguage, stepParam.TargetLanguage, stepParam.EnableModalFilter, translateItem.Id)
+						translatedResults, err = s.splitTextAndTranslateV2(ctx, stepParam, translateItem.Data, audioSegments[translateItem.Id].TranscriptionData.Words, translateItem.Id)
 						if err == nil {
 							break
 						}
@@ -487,20 +604,28 @@ func (s Service) audioToSrt(ctx context.Context, stepParam *types.SubtitleTaskSt
 				}
 
 				// 二次分割长句
-				splitResults, err := s.splitTranslateItem(translatedResults)
+				splitResults, err := s.splitTranslateItem(ctx, translatedResults)
 				if err != nil {
 					// 不中断
 					log.GetLogger().Error("audioToSubtitle audioToSrt splitTranslateItem err", zap.Any("taskId", stepParam.TaskId), zap.Any("splitId", translateItem.Id), zap.Error(err))
-					translatedQueue <- DataWithId[[]*TranslatedItem]{
-						Data: translatedResults,
-						Id:   translateItem.Id,
-					}
-				} else {
-					translatedQueue <- DataWithId[[]*TranslatedItem]{
-						Data: splitResults,
-						Id:   translateItem.Id,
+					splitResults = translatedResults
+				}
+
+				// 按字幕时长精简超出阅读速度的译文，精简结果单独保存，翻译数据只保存完整译文
+				if condensationEnabled() && stepParam.TargetLanguage != "none" && stepParam.TargetLanguage != stepParam.OriginLanguage {
+					condensedSavePath := filepath.Join(stepParam.TaskBasePath, fmt.Sprintf(types.SubtitleTaskCondensedTranslationPersistenceFileNamePattern, translateItem.Id))
+					loadCondensedTranslations(condensedSavePath, splitResults)
+					durations := translatedItemDurations(splitResults, audioSegments[translateItem.Id].TranscriptionData.Words, stepParam.OriginLanguage)
+					if s.condenseTranslations(ctx, splitResults, durations, stepParam.TargetLanguage) > 0 {
+						_ = util.SaveToDisk(splitResults, condensedSavePath)
 					}
 				}
+				// 替换译文，不写回翻译数据，断点续跑时重新替换
+				replaceTranslatedItems(stepParam, splitResults, stepParam.TargetLanguage)
+				translatedQueue <- DataWithId[[]*TranslatedItem]{
+					Data: splitResults,
+					Id:   translateItem.Id,
+				}
 			}
 		}
 	})
@@ -608,7 +733,7 @@ func (s Service) audioToSrt(ctx context.Context, stepParam *types.SubtitleTaskSt
 						Index:                  i + 1,
 						Timestamp:              "",
 						OriginLanguageSentence: translatedItem.OriginText,
-						TargetLanguageSentence: translatedItem.TranslatedText,
+						TargetLanguageSentence: translatedItem.SubtitleText(),
 					})
 				}
 
@@ -638,6 +763,19 @@ func (s Service) audioToSrt(ctx context.Context, stepParam *types.SubtitleTaskSt
 		return fmt.Errorf("audioToSubtitle audioToSrt errgroup wait err: %w", err)
 	}
 
+	// 汇总整段音频的识别词，供置信度复核使用
+	stepParam.TranscribedWords = make([]types.Word, 0)
+	for i, segment := range audioSegments {
+		if segment.TranscriptionData == nil {
+			continue
+		}
+		for _, word := range segment.TranscriptionData.Words {
+			word.Start += timePoints[i]
+			word.End += timePoints[i]
+			stepParam.TranscribedWords = append(stepParam.TranscribedWords, word)
+		}
+	}
+
 	// 合并文件
 	originNoTsFiles := make([]string, 0)
 	bilingualFiles := make([]string, 0)
@@ -1234,26 +1372,22 @@ func generateSrtWithTimestamps(srtBlocks []*util.SrtBlock, tsOffset float64, wor
 		lastTs = ts
 	}
 
-	// 保存带时间戳的原始字幕
-	finalBilingualSrtFileName := fmt.Sprintf("%s/%s", stepParam.TaskBasePath, fmt.Sprintf(types.SubtitleTaskSplitBilingualSrtFileNamePattern, segmentIdx))
-	finalBilingualSrtFile, err := os.Create(finalBilingualSrtFileName)
-	if err != nil {
-		return fmt.Errorf("audioToSubtitle generateTimestamps create bilingual srt file error: %w", err)
+	// 时间戳已按识别原文对齐，再替换展示用的原文
+	if sourceReplacer := newTextReplacer(stepParam.ReplacementRules, types.ReplacementScopeSource, stepParam.OriginLanguage); !sourceReplacer.Empty() {
+		for _, block := range newSrtBlocks {
+			block.OriginLanguageSentence = sourceReplacer.Replace(block.OriginLanguageSentence)
+		}
+		for _, shortOriginBlocks := range shortOriginSrtMap {
+			for i := range shortOriginBlocks {
+				shortOriginBlocks[i].OriginLanguageSentence = sourceReplacer.Replace(shortOriginBlocks[i].OriginLanguageSentence)
+			}
+		}
 	}
-	defer finalBilingualSrtFile.Close()
 
-	// 写入字幕文件
-	for _, srtBlock := range newSrtBlocks {
-		_, _ = finalBilingualSrtFile.WriteString(fmt.Sprintf("%d\n", srtBlock.Index))
-		_, _ = finalBilingualSrtFile.WriteString(srtBlock.Timestamp + "\n")
-		if stepParam.SubtitleResultType == types.SubtitleResultTypeBilingualTranslationOnTop {
-			_, _ = finalBilingualSrtFile.WriteString(srtBlock.TargetLanguageSentence + "\n")
-			_, _ = finalBilingualSrtFile.WriteString(srtBlock.OriginLanguageSentence + "\n\n")
-		} else {
-			// on bottom 或者单语类型，都用on bottom
-			_, _ = finalBilingualSrtFile.WriteString(srtBlock.OriginLanguageSentence + "\n")
-			_, _ = finalBilingualSrtFile.WriteString(srtBlock.TargetLanguageSentence + "\n\n")
-		}
+	// 保存带时间戳的原始字幕
+	finalBilingualSrtFileName := fmt.Sprintf("%s/%s", stepParam.TaskBasePath, fmt.Sprintf(types.SubtitleTaskSplitBilingualSrtFileNamePattern, segmentIdx))
+	if err = writeBilingualSrtFile(finalBilingualSrtFileName, newSrtBlocks, stepParam.SubtitleResultType); err != nil {
+		return fmt.Errorf("audioToSubtitle generateTimestamps %w", err)
 	}
 
 	// 保存带时间戳的字幕,长中文+短英文（示意，也支持其他语言）
@@ -1297,6 +1431,57 @@ func generateSrtWithTimestamps(srtBlocks []*util.SrtBlock, tsOffset float64, wor
 	return nil
 }
 
+// writeBilingualSrtFile 按字幕类型决定上下顺序，写入带时间戳的双语字幕
+func writeBilingualSrtFile(filePath string, srtBlocks []*util.SrtBlock, resultType types.SubtitleResultType) error {
+	bilingualSrtFile, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("create bilingual srt file error: %w", err)
+	}
+	defer bilingualSrtFile.Close()
+
+	for _, srtBlock := range srtBlocks {
+		_, _ = bilingualSrtFile.WriteString(fmt.Sprintf("%d\n", srtBlock.Index))
+		_, _ = bilingualSrtFile.WriteString(srtBlock.Timestamp + "\n")
+		if resultType == types.SubtitleResultTypeBilingualTranslationOnTop {
+			_, _ = bilingualSrtFile.WriteString(srtBlock.TargetLanguageSentence + "\n")
+			_, _ = bilingualSrtFile.WriteString(srtBlock.OriginLanguageSentence + "\n\n")
+		} else {
+			// on bottom 或者单语类型，都用on bottom
+			_, _ = bilingualSrtFile.WriteString(srtBlock.OriginLanguageSentence + "\n")
+			_, _ = bilingualSrtFile.WriteString(srtBlock.TargetLanguageSentence + "\n\n")
+		}
+	}
+	return nil
+}
+
+// readBilingualSrtFile 读取 writeBilingualSrtFile 写出的双语字幕，每条固定两行文本，允许某一行为空
+func readBilingualSrtFile(filePath string, resultType types.SubtitleResultType) ([]*util.SrtBlock, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("read bilingual srt file error: %w", err)
+	}
+	lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	srtBlocks := make([]*util.SrtBlock, 0)
+	for i := 0; i+3 < len(lines); i++ {
+		index, convErr := strconv.Atoi(strings.TrimSpace(lines[i]))
+		if convErr != nil || !strings.Contains(lines[i+1], "-->") {
+			continue
+		}
+		block := &util.SrtBlock{
+			Index:     index,
+			Timestamp: strings.TrimSpace(lines[i+1]),
+		}
+		if resultType == types.SubtitleResultTypeBilingualTranslationOnTop {
+			block.TargetLanguageSentence, block.OriginLanguageSentence = lines[i+2], lines[i+3]
+		} else {
+			block.OriginLanguageSentence, block.TargetLanguageSentence = lines[i+2], lines[i+3]
+		}
+		srtBlocks = append(srtBlocks, block)
+		i += 3
+	}
+	return srtBlocks, nil
+}
+
 func parseAndCheckContent(splitContent, originalText string) ([]*TranslatedItem, error) {
 	var result []*TranslatedItem
 
@@ -1408,7 +1593,7 @@ func calcLength(text string) float64 {
 }
 
 // splitTranslateItem 根据字符权重和最大长度分割长句
-func (s Service) splitTranslateItem(items []*TranslatedItem) ([]*TranslatedItem, error) {
+func (s Service) splitTranslateItem(ctx context.Context, items []*TranslatedItem) ([]*TranslatedItem, error) {
 	var result []*TranslatedItem
 	maxLength := config.Conf.App.MaxSentenceLength + 30
 
@@ -1421,7 +1606,7 @@ func (s Service) splitTranslateItem(items []*TranslatedItem) ([]*TranslatedItem,
 
 		// 调用大模型进行分割
 		log.GetLogger().Info("splitTranslateItem long sentence detected, need split", zap.Any("item", item))
-		splitItems, err := s.splitLongSentence(item)
+		splitItems, err := s.splitLongSentence(ctx, item)
 		if err != nil {
 			log.GetLogger().Error("splitTranslateItem splitLongSentence error", zap.Error(err), zap.Any("item", item))
 			return nil, fmt.Errorf("split long sentence error: %w", err)
@@ -1433,10 +1618,10 @@ func (s Service) splitTranslateItem(items []*TranslatedItem) ([]*TranslatedItem,
 }
 
 // splitLongSentence 使用大模型分割长句并保持原文和译文对齐
-func (s Service) splitLongSentence(item *TranslatedItem) ([]*TranslatedItem, error) {
-	prompt := fmt.Sprintf(types.SplitLongSentencePrompt, item.OriginText, item.TranslatedText)
+func (s Service) splitLongSentence(ctx context.Context, item *TranslatedItem) ([]*TranslatedItem, error) {
+	prompt := s.renderPrompt(promptKindSplitLongSentence, splitLongSentencePromptVars{OriginText: item.OriginText, TranslatedText: item.TranslatedText})
 
-	response, err := s.ChatCompleter.ChatCompletion(prompt)
+	response, err := s.chat(ctx, llmCallSplit, prompt)
 	if err != nil {
 		return nil, fmt.Errorf("chat completion error: %w", err)
 	}
@@ -1464,10 +1649,10 @@ func (s Service) splitLongSentence(item *TranslatedItem) ([]*TranslatedItem, err
 	return splitItems, nil
 }
 
-func (s Service) splitOriginLongSentence(sentence string) ([]string, error) {
-	prompt := fmt.Sprintf(types.SplitOriginLongSentencePrompt, sentence)
+func (s Service) splitOriginLongSentence(ctx context.Context, sentence string) ([]string, error) {
+	prompt := s.renderPrompt(promptKindSplitOriginLongSentence, sentencePromptVars{Sentence: sentence})
 	if len(sentence) > 200 {
-		prompt = fmt.Sprintf(types.SplitLongTextByMeaningPrompt, sentence)
+		prompt = s.renderPrompt(promptKindSplitLongTextByMeaning, sentencePromptVars{Sentence: sentence})
 	}
 
 	var response string
@@ -1475,7 +1660,10 @@ func (s Service) splitOriginLongSentence(sentence string) ([]string, error) {
 	shortSentences := make([]string, 0)
 	// 尝试调用3次
 	for i := range 3 {
-		response, err = s.ChatCompleter.ChatCompletion(prompt)
+		if i > 0 {
+			time.Sleep(ratelimit.Backoff(i - 1))
+		}
+		response, err = s.chat(ctx, llmCallSplit, prompt)
 		if err != nil {
 			log.GetLogger().Error("splitOriginLongSentence chat completion error", zap.Error(err), zap.String("sentence", sentence), zap.Any("time", i))
 			continue
@@ -1506,7 +1694,7 @@ func (s Service) splitOriginLongSentence(sentence string) ([]string, error) {
 }
 
 // splitSentenceRecursively 递归拆分句子，保持顺序
-func (s Service) splitSentenceRecursively(sentence string, depth int, maxDepth int) ([]string, error) {
+func (s Service) splitSentenceRecursively(ctx context.Context, sentence string, depth int, maxDepth int) ([]string, error) {
 	// 防止无限递归
 	if depth >= maxDepth {
 		log.GetLogger().Warn("reached max split depth", zap.Any("sentence", sentence), zap.Int("depth", depth))
@@ -1520,7 +1708,7 @@ func (s Service) splitSentenceRecursively(sentence string, depth int, maxDepth i
 
 	// 调用大模型进行分割
 	log.GetLogger().Info("use llm split origin long sentence", zap.Any("sentence", sentence), zap.Int("depth", depth))
-	splitItems, err := s.splitOriginLongSentence(sentence)
+	splitItems, err := s.splitOriginLongSentence(ctx, sentence)
 	if err != nil {
 		log.GetLogger().Error("splitSentenceRecursively splitLongSentence error", zap.Error(err), zap.Any("sentence", sentence), zap.Int("depth", depth))
 		return []string{sentence}, nil // 返回原句子而不是错误
@@ -1534,7 +1722,7 @@ func (s Service) splitSentenceRecursively(sentence string, depth int, maxDepth i
 	// 递归处理每个拆分结果，保持顺序
 	var result []string
 	for _, item := range splitItems {
-		subResults, err := s.splitSentenceRecursively(item, depth+1, maxDepth)
+		subResults, err := s.splitSentenceRecursively(ctx, item, depth+1, maxDepth)
 		if err != nil {
 			log.GetLogger().Error("splitSentenceRecursively recursive error", zap.Error(err), zap.Any("item", item), zap.Int("depth", depth))
 			result = append(result, item) // 如果递归失败，添加原项
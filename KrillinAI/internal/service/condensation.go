@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"krillin-ai/config"
+	"krillin-ai/internal/types"
+	"krillin-ai/log"
+	"krillin-ai/pkg/util"
+
+	"go.uber.org/zap"
+)
+
+// 译文精简：按每条字幕的时长计算每秒字符数（CPS），超出阅读速度或长度限制的译文让大模型在字数限制内改写，
+// 完整译文和精简译文都保留在翻译数据中，字幕和配音使用精简译文
+
+const condensationBatchSize = 20
+
+type condensationItem struct {
+	Id       int    `json:"id"`
+	Text     string `json:"text"`
+	MaxChars int    `json:"max_chars"`
+}
+
+func condensationEnabled() bool {
+	return config.Conf.Condensation.Enabled
+}
+
+// cueCharCount 字幕的字符数，不计换行和首尾空白
+func cueCharCount(text string) int {
+	return utf8.RuneCountInString(strings.ReplaceAll(strings.TrimSpace(text), "\n", ""))
+}
+
+// condensationBudget 字幕时长内允许的最大字符数，同时受单条长度限制约束
+func condensationBudget(language types.StandardLanguageCode, duration float64) int {
+	maxCps := config.Conf.Condensation.MaxCps
+	if util.IsAsianLanguage(language) {
+		maxCps = config.Conf.Condensation.MaxCpsAsian
+	}
+	budget := int(maxCps * duration)
+	if maxLineLength := config.Conf.Condensation.MaxLineLength; maxLineLength > 0 && budget > maxLineLength {
+		budget = maxLineLength
+	}
+	return budget
+}
+
+// srtBlockDuration 字幕时间轴的时长（秒），解析失败时返回0
+func srtBlockDuration(timestamp string) float64 {
+	start, end, found := strings.Cut(timestamp, "-->")
+	if !found {
+		return 0
+	}
+	startTime, err := parseSrtTime(strings.TrimSpace(start))
+	if err != nil {
+		return 0
+	}
+	endTime, err := parseSrtTime(strings.TrimSpace(end))
+	if err != nil || endTime <= startTime {
+		return 0
+	}
+	return (endTime - startTime).Seconds()
+}
+
+// translatedItemDurations 按识别词对齐计算每条字幕的时长，与之后生成时间轴的方式一致
+func translatedItemDurations(items []*TranslatedItem, words []types.Word, language types.StandardLanguageCode) []float64 {
+	durations := make([]float64, len(items))
+	if len(words) == 0 {
+		return durations
+	}
+	blocks := make([]*util.SrtBlock, 0, len(items))
+	for i, item := range items {
+		blocks = append(blocks, &util.SrtBlock{Index: i + 1, OriginLanguageSentence: item.OriginText})
+	}
+	blocks, err := NewTimestampGenerator().GenerateTimestamps(blocks, words, language, 0)
+	if err != nil {
+		log.GetLogger().Warn("translatedItemDurations GenerateTimestamps err", zap.Error(err))
+		return durations
+	}
+	for i, block := range blocks {
+		durations[i] = srtBlockDuration(block.Timestamp)
+	}
+	return durations
+}
+
+// condenseTranslations 为超出预算的译文生成精简译文并写入 CondensedText，已有精简译文的跳过，返回精简的条数。
+// 某一批失败或改写后没有变短时保留完整译文
+func (s Service) condenseTranslations(ctx context.Context, items []*TranslatedItem, durations []float64, language types.StandardLanguageCode) int {
+	candidates := make([]condensationItem, 0)
+	for i, item := range items {
+		if item == nil || item.CondensedText != "" || strings.TrimSpace(item.TranslatedText) == "" || i >= len(durations) || durations[i] <= 0 {
+			continue
+		}
+		budget := condensationBudget(language, durations[i])
+		if budget <= 0 || cueCharCount(item.TranslatedText) <= budget {
+			continue
+		}
+		candidates = append(candidates, condensationItem{Id: i, Text: item.TranslatedText, MaxChars: budget})
+	}
+
+	condensed := 0
+	for start := 0; start < len(candidates); start += condensationBatchSize {
+		batch := candidates[start:min(start+condensationBatchSize, len(candidates))]
+		inputBytes, _ := json.Marshal(batch)
+		prompt := s.renderPrompt(promptKindCondenseTranslation, condenseTranslationPromptVars{
+			Language: types.GetStandardLanguageName(language),
+			Input:    string(inputBytes),
+		})
+		response, err := s.chat(ctx, llmCallTranslate, prompt)
+		if err != nil {
+			log.GetLogger().Warn("condenseTranslations chat err, keep full translations", zap.Int("batchStart", start), zap.Error(err))
+			continue
+		}
+		var result struct {
+			Results []struct {
+				Id   int    `json:"id"`
+				Text string `json:"text"`
+			} `json:"results"`
+		}
+		if err = json.Unmarshal([]byte(strings.TrimSpace(util.CleanMarkdownCodeBlock(response))), &result); err != nil {
+			log.GetLogger().Warn("condenseTranslations unmarshal err, keep full translations", zap.Int("batchStart", start), zap.String("response", response), zap.Error(err))
+			continue
+		}
+		requested := make(map[int]condensationItem, len(batch))
+		for _, candidate := range batch {
+			requested[candidate.Id] = candidate
+		}
+		for _, condensedItem := range result.Results {
+			candidate, ok := requested[condensedItem.Id]
+			text := strings.TrimSpace(condensedItem.Text)
+			if !ok || text == "" || cueCharCount(text) >= cueCharCount(candidate.Text) {
+				continue
+			}
+			if cueCharCount(text) > candidate.MaxChars {
+				log.GetLogger().Info("condenseTranslations still over budget", zap.Int("maxChars", candidate.MaxChars), zap.String("text", text))
+			}
+			items[condensedItem.Id].CondensedText = text
+			delete(requested, condensedItem.Id)
+			condensed++
+		}
+	}
+	if len(candidates) > 0 {
+		log.GetLogger().Info("condenseTranslations done", zap.String("language", string(language)), zap.Int("overBudget", len(candidates)), zap.Int("condensed", condensed))
+	}
+	return condensed
+}
+
+// loadCondensedTranslations 断点续跑时复用上次保存的精简译文，按原文和完整译文匹配，拆句结果不同的条目重新精简
+func loadCondensedTranslations(path string, items []*TranslatedItem) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	var saved []*TranslatedItem
+	if err = json.Unmarshal(data, &saved); err != nil {
+		log.GetLogger().Warn("loadCondensedTranslations unmarshal err, condense again", zap.String("path", path), zap.Error(err))
+		return
+	}
+	condensed := make(map[[2]string]string, len(saved))
+	for _, item := range saved {
+		if item != nil && item.CondensedText != "" {
+			condensed[[2]string{item.OriginText, item.TranslatedText}] = item.CondensedText
+		}
+	}
+	for _, item := range items {
+		if text, ok := condensed[[2]string{item.OriginText, item.TranslatedText}]; ok && item.CondensedText == "" {
+			item.CondensedText = text
+		}
+	}
+}
+
+// qaLengthBudget 开启译文精简时字幕允许的最大字符数，质检时据此区分有意精简和漏译，未开启时返回0
+func qaLengthBudget(language types.StandardLanguageCode, timestamp string) int {
+	if !condensationEnabled() {
+		return 0
+	}
+	duration := srtBlockDuration(timestamp)
+	if duration <= 0 {
+		return 0
+	}
+	return max(condensationBudget(language, duration), 0)
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"krillin-ai/config"
+	"krillin-ai/internal/mocks"
+	"krillin-ai/internal/types"
+	"krillin-ai/pkg/util"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCondensationBudget(t *testing.T) {
+	oldCondensation := config.Conf.Condensation
+	defer func() { config.Conf.Condensation = oldCondensation }()
+	config.Conf.Condensation = config.CondensationConfig{MaxCps: 17, MaxCpsAsian: 9, MaxLineLength: 42}
+
+	assert.Equal(t, 34, condensationBudget(types.LanguageNameEnglish, 2))
+	assert.Equal(t, 42, condensationBudget(types.LanguageNameEnglish, 5))
+	assert.Equal(t, 18, condensationBudget(types.LanguageNameSimplifiedChinese, 2))
+	assert.InDelta(t, 2.5, srtBlockDuration("00:00:01,000 --> 00:00:03,500"), 1e-9)
+	assert.Zero(t, srtBlockDuration("00:00:03,500 --> 00:00:01,000"))
+	assert.Zero(t, srtBlockDuration(""))
+}
+
+func TestCondenseTranslations_KeepsFullAndCondensedVariants(t *testing.T) {
+	oldCondensation := config.Conf.Condensation
+	defer func() { config.Conf.Condensation = oldCondensation }()
+	config.Conf.Condensation = config.CondensationConfig{Enabled: true, MaxCps: 17, MaxCpsAsian: 9}
+
+	items := []*TranslatedItem{
+		{OriginText: "Hi", TranslatedText: "你好"},
+		{OriginText: "So what I really wanted to say is that this is important", TranslatedText: "所以我真正想说的其实是这件事情非常非常重要"},
+		{OriginText: "And we will talk about it later in this video", TranslatedText: "我们会在这个视频后面的部分再详细地讨论这个问题"},
+	}
+	mockChatCompleter := new(mocks.MockChatCompleter)
+	mockChatCompleter.On("ChatCompletion", mock.MatchedBy(func(prompt string) bool {
+		return strings.Contains(prompt, `"id":1`) && strings.Contains(prompt, `"max_chars":9`) && !strings.Contains(prompt, `"id":0`)
+	})).Return(`{"results":[{"id":1,"text":"我想说这很重要"},{"id":2,"text":"我们会在这个视频后面的部分再详细地讨论这个问题，敬请期待"}]}`, nil).Once()
+	svc := Service{ChatCompleter: mockChatCompleter}
+
+	condensed := svc.condenseTranslations(context.Background(), items, []float64{1, 1, 2}, types.LanguageNameSimplifiedChinese)
+
+	assert.Equal(t, 1, condensed)
+	assert.Empty(t, items[0].CondensedText)
+	assert.Equal(t, "所以我真正想说的其实是这件事情非常非常重要", items[1].TranslatedText)
+	assert.Equal(t, "我想说这很重要", items[1].SubtitleText())
+	// 改写后没有变短时保留完整译文
+	assert.Empty(t, items[2].CondensedText)
+	assert.Equal(t, items[2].TranslatedText, items[2].SubtitleText())
+	mockChatCompleter.AssertExpectations(t)
+
+	// 已有精简译文的不再重复请求
+	assert.Equal(t, 0, Service{ChatCompleter: new(mocks.MockChatCompleter)}.condenseTranslations(context.Background(), items[:2], []float64{1, 1}, types.LanguageNameSimplifiedChinese))
+}
+
+func TestLoadCondensedTranslations_ReusesMatchingItems(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "condensed_translation_data_0.json")
+	require.NoError(t, util.SaveToDisk([]*TranslatedItem{
+		{OriginText: "First line", TranslatedText: "第一句很长的译文", CondensedText: "第一句"},
+		{OriginText: "Second line", TranslatedText: "第二句", CondensedText: ""},
+	}, path))
+
+	items := []*TranslatedItem{
+		{OriginText: "First line", TranslatedText: "第一句很长的译文"},
+		{OriginText: "Second line", TranslatedText: "第二句"},
+		// 拆句结果变了，不复用
+		{OriginText: "First", TranslatedText: "第一句很长的译文"},
+	}
+	loadCondensedTranslations(path, items)
+
+	assert.Equal(t, "第一句", items[0].CondensedText)
+	assert.Empty(t, items[1].CondensedText)
+	assert.Empty(t, items[2].CondensedText)
+
+	// 没有保存过精简译文时不做任何事
+	loadCondensedTranslations(filepath.Join(t.TempDir(), "missing.json"), items)
+	assert.Equal(t, "第一句", items[0].CondensedText)
+}
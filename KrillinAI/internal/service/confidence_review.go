@@ -0,0 +1,188 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"krillin-ai/config"
+	"krillin-ai/internal/types"
+	"krillin-ai/log"
+	"krillin-ai/pkg/util"
+
+	"go.uber.org/zap"
+)
+
+// LowConfidenceWord 置信度低于阈值的识别词，时间为相对整段音频的秒数
+type LowConfidenceWord struct {
+	Text       string  `json:"text"`
+	Start      float64 `json:"start"`
+	End        float64 `json:"end"`
+	Confidence float64 `json:"confidence"`
+}
+
+// ConfidenceReviewCue 需要人工复核的字幕条目
+type ConfidenceReviewCue struct {
+	Index              int                 `json:"index"`
+	Start              string              `json:"start"`
+	End                string              `json:"end"`
+	Text               string              `json:"text"`
+	AvgConfidence      float64             `json:"avg_confidence"`
+	MinConfidence      float64             `json:"min_confidence"`
+	LowConfidenceWords []LowConfidenceWord `json:"low_confidence_words"`
+}
+
+// ConfidenceReviewReport 低置信度复核报告，只列出需要复核的字幕
+type ConfidenceReviewReport struct {
+	Threshold          float64               `json:"threshold"`
+	TotalCues          int                   `json:"total_cues"`
+	ReviewCues         int                   `json:"review_cues"`
+	TotalWords         int                   `json:"total_words"`
+	LowConfidenceWords int                   `json:"low_confidence_words"`
+	Cues               []ConfidenceReviewCue `json:"cues"`
+}
+
+// generateConfidenceReview 根据识别词的置信度生成复核报告（JSON）以及高亮了低置信度词的原文 SRT/ASS，
+// 转录服务不返回置信度时直接跳过
+func generateConfidenceReview(stepParam *types.SubtitleTaskStepParam) error {
+	words := make([]types.Word, 0, len(stepParam.TranscribedWords))
+	for _, word := range stepParam.TranscribedWords {
+		if word.Confidence > 0 {
+			words = append(words, word)
+		}
+	}
+	if len(words) == 0 {
+		log.GetLogger().Info("generateConfidenceReview no confidence from transcriber, skip", zap.Any("taskId", stepParam.TaskId))
+		return nil
+	}
+
+	originSrtFilePath := filepath.Join(stepParam.TaskBasePath, types.SubtitleTaskOriginLanguageSrtFileName)
+	entries, err := parseSRT(originSrtFilePath)
+	if err != nil {
+		return fmt.Errorf("generateConfidenceReview parseSRT err: %w", err)
+	}
+
+	threshold := config.Conf.App.ConfidenceThreshold
+	report := ConfidenceReviewReport{
+		Threshold:  threshold,
+		TotalCues:  len(entries),
+		TotalWords: len(words),
+		Cues:       make([]ConfidenceReviewCue, 0),
+	}
+
+	var srtBuilder, assBuilder strings.Builder
+	assBuilder.WriteString(types.AssHeaderHorizontal)
+	wordIdx := 0
+	for i, entry := range entries {
+		startDuration, err := parseSrtTime(entry.Start)
+		if err != nil {
+			return fmt.Errorf("generateConfidenceReview parseSrtTime err: %w", err)
+		}
+		endDuration, err := parseSrtTime(entry.End)
+		if err != nil {
+			return fmt.Errorf("generateConfidenceReview parseSrtTime err: %w", err)
+		}
+		start, end := startDuration.Seconds(), endDuration.Seconds()
+
+		// 字幕按时间排序，词的中点落在字幕时间范围内即归属该字幕
+		for wordIdx < len(words) && (words[wordIdx].Start+words[wordIdx].End)/2 < start {
+			wordIdx++
+		}
+		var (
+			sum      float64
+			count    int
+			minConf  = 1.0
+			lowWords []LowConfidenceWord
+		)
+		for j := wordIdx; j < len(words) && (words[j].Start+words[j].End)/2 <= end; j++ {
+			sum += words[j].Confidence
+			count++
+			minConf = min(minConf, words[j].Confidence)
+			if words[j].Confidence < threshold {
+				lowWords = append(lowWords, LowConfidenceWord{
+					Text:       words[j].Text,
+					Start:      words[j].Start,
+					End:        words[j].End,
+					Confidence: words[j].Confidence,
+				})
+			}
+		}
+
+		if len(lowWords) > 0 {
+			report.ReviewCues++
+			report.LowConfidenceWords += len(lowWords)
+			report.Cues = append(report.Cues, ConfidenceReviewCue{
+				Index:              i + 1,
+				Start:              entry.Start,
+				End:                entry.End,
+				Text:               entry.Text,
+				AvgConfidence:      sum / float64(count),
+				MinConfidence:      minConf,
+				LowConfidenceWords: lowWords,
+			})
+		}
+
+		srtBuilder.WriteString(fmt.Sprintf("%d\n%s --> %s\n%s\n\n", i+1, entry.Start, entry.End,
+			highlightLowConfidenceWords(entry.Text, lowWords, `<font color="#FF0000">`, `</font>`)))
+		assBuilder.WriteString(fmt.Sprintf("Dialogue: 0,%s,%s,Major,,0,0,0,,{\\an2}%s\n", formatTimestamp(startDuration), formatTimestamp(endDuration),
+			highlightLowConfidenceWords(entry.Text, lowWords, `{\c&H0000FF&}`, `{\r}`)))
+	}
+
+	outputDir := filepath.Join(stepParam.TaskBasePath, "output")
+	reportPath := filepath.Join(outputDir, types.SubtitleTaskConfidenceReviewReportFileName)
+	if err = util.SaveToDisk(report, reportPath); err != nil {
+		return fmt.Errorf("generateConfidenceReview save report err: %w", err)
+	}
+	srtPath := filepath.Join(outputDir, types.SubtitleTaskConfidenceReviewSrtFileName)
+	if err = os.WriteFile(srtPath, []byte(srtBuilder.String()), 0644); err != nil {
+		return fmt.Errorf("generateConfidenceReview write srt err: %w", err)
+	}
+	assPath := filepath.Join(outputDir, types.SubtitleTaskConfidenceReviewAssFileName)
+	if err = os.WriteFile(assPath, []byte(assBuilder.String()), 0644); err != nil {
+		return fmt.Errorf("generateConfidenceReview write ass err: %w", err)
+	}
+
+	reportName, srtName, assName := "Confidence Review Report", "Confidence Review Subtitle (SRT)", "Confidence Review Subtitle (ASS)"
+	if stepParam.UserUILanguage == types.LanguageNameSimplifiedChinese {
+		reportName, srtName, assName = "低置信度复核报告", "低置信度高亮字幕 (SRT)", "低置信度高亮字幕 (ASS)"
+	}
+	stepParam.SubtitleInfos = append(stepParam.SubtitleInfos,
+		types.SubtitleFileInfo{Name: reportName, Path: reportPath, LanguageIdentifier: "confidence_review"},
+		types.SubtitleFileInfo{Name: srtName, Path: srtPath, LanguageIdentifier: "confidence_review"},
+		types.SubtitleFileInfo{Name: assName, Path: assPath, LanguageIdentifier: "confidence_review"},
+	)
+
+	log.GetLogger().Info("generateConfidenceReview completed", zap.Any("taskId", stepParam.TaskId),
+		zap.Int("totalCues", report.TotalCues), zap.Int("reviewCues", report.ReviewCues), zap.Int("lowConfidenceWords", report.LowConfidenceWords))
+	return nil
+}
+
+// highlightLowConfidenceWords 按顺序在字幕文本中找到低置信度词并用标签包裹，找不到的词忽略
+func highlightLowConfidenceWords(text string, lowWords []LowConfidenceWord, openTag, closeTag string) string {
+	lowerText := strings.ToLower(text)
+	if len(lowWords) == 0 || len(lowerText) != len(text) {
+		return text
+	}
+
+	var builder strings.Builder
+	cursor := 0
+	for _, word := range lowWords {
+		target := strings.ToLower(word.Text)
+		if target == "" {
+			continue
+		}
+		pos := strings.Index(lowerText[cursor:], target)
+		if pos < 0 {
+			continue
+		}
+		begin := cursor + pos
+		builder.WriteString(text[cursor:begin])
+		builder.WriteString(openTag)
+		builder.WriteString(text[begin : begin+len(target)])
+		builder.WriteString(closeTag)
+		cursor = begin + len(target)
+	}
+	builder.WriteString(text[cursor:])
+	return builder.String()
+}
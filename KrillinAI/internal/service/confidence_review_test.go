@@ -0,0 +1,94 @@
+package service
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"krillin-ai/config"
+	"krillin-ai/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHighlightLowConfidenceWords(t *testing.T) {
+	lowWords := []LowConfidenceWord{{Text: "quick"}, {Text: "missing"}, {Text: "dog"}}
+
+	got := highlightLowConfidenceWords("The Quick brown fox jumps over the lazy dog.", lowWords, "<b>", "</b>")
+
+	assert.Equal(t, "The <b>Quick</b> brown fox jumps over the lazy <b>dog</b>.", got)
+}
+
+func TestHighlightLowConfidenceWords_NoLowWords(t *testing.T) {
+	assert.Equal(t, "你好世界", highlightLowConfidenceWords("你好世界", nil, "<b>", "</b>"))
+}
+
+func TestGenerateConfidenceReview(t *testing.T) {
+	oldThreshold := config.Conf.App.ConfidenceThreshold
+	config.Conf.App.ConfidenceThreshold = 0.6
+	defer func() { config.Conf.App.ConfidenceThreshold = oldThreshold }()
+
+	taskBasePath := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(taskBasePath, "output"), os.ModePerm))
+	originSrt := "1\n00:00:00,000 --> 00:00:02,000\nThe quick fox\n\n2\n00:00:02,500 --> 00:00:04,000\nSleeps well\n\n"
+	require.NoError(t, os.WriteFile(filepath.Join(taskBasePath, types.SubtitleTaskOriginLanguageSrtFileName), []byte(originSrt), 0644))
+	stepParam := &types.SubtitleTaskStepParam{
+		TaskBasePath:   taskBasePath,
+		UserUILanguage: types.LanguageNameEnglish,
+		TranscribedWords: []types.Word{
+			{Text: "The", Start: 0, End: 0.4, Confidence: 0.9},
+			{Text: "quick", Start: 0.5, End: 1.0, Confidence: 0.4},
+			{Text: "fox", Start: 1.1, End: 1.8, Confidence: 0.8},
+			// 阈值本身不算低置信度
+			{Text: "Sleeps", Start: 2.6, End: 3.2, Confidence: 0.6},
+			{Text: "well", Start: 3.3, End: 3.9, Confidence: 0.95},
+		},
+	}
+
+	require.NoError(t, generateConfidenceReview(stepParam))
+
+	data, err := os.ReadFile(filepath.Join(taskBasePath, "output", types.SubtitleTaskConfidenceReviewReportFileName))
+	require.NoError(t, err)
+	var report ConfidenceReviewReport
+	require.NoError(t, json.Unmarshal(data, &report))
+	assert.Equal(t, 2, report.TotalCues)
+	assert.Equal(t, 5, report.TotalWords)
+	assert.Equal(t, 1, report.ReviewCues)
+	assert.Equal(t, 1, report.LowConfidenceWords)
+	require.Len(t, report.Cues, 1)
+	assert.Equal(t, 1, report.Cues[0].Index)
+	assert.InDelta(t, 0.7, report.Cues[0].AvgConfidence, 1e-9)
+	assert.InDelta(t, 0.4, report.Cues[0].MinConfidence, 1e-9)
+	assert.Equal(t, []LowConfidenceWord{{Text: "quick", Start: 0.5, End: 1.0, Confidence: 0.4}}, report.Cues[0].LowConfidenceWords)
+
+	srt, err := os.ReadFile(filepath.Join(taskBasePath, "output", types.SubtitleTaskConfidenceReviewSrtFileName))
+	require.NoError(t, err)
+	assert.Contains(t, string(srt), `The <font color="#FF0000">quick</font> fox`)
+	assert.Contains(t, string(srt), "\nSleeps well\n")
+	assert.Len(t, stepParam.SubtitleInfos, 3)
+
+	// 提高阈值后第二条也需要复核
+	config.Conf.App.ConfidenceThreshold = 0.85
+	stepParam.SubtitleInfos = nil
+	require.NoError(t, generateConfidenceReview(stepParam))
+	data, err = os.ReadFile(filepath.Join(taskBasePath, "output", types.SubtitleTaskConfidenceReviewReportFileName))
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &report))
+	assert.Equal(t, 2, report.ReviewCues)
+	assert.Equal(t, 3, report.LowConfidenceWords)
+}
+
+func TestGenerateConfidenceReview_SkipsWithoutConfidence(t *testing.T) {
+	taskBasePath := t.TempDir()
+	stepParam := &types.SubtitleTaskStepParam{
+		TaskBasePath:     taskBasePath,
+		TranscribedWords: []types.Word{{Text: "Hello", Start: 0, End: 0.5}},
+	}
+
+	require.NoError(t, generateConfidenceReview(stepParam))
+
+	assert.Empty(t, stepParam.SubtitleInfos)
+	assert.NoFileExists(t, filepath.Join(taskBasePath, "output", types.SubtitleTaskConfidenceReviewReportFileName))
+}
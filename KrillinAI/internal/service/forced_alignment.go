@@ -0,0 +1,333 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"krillin-ai/config"
+	"krillin-ai/internal/storage"
+	"krillin-ai/internal/types"
+	"krillin-ai/log"
+	"krillin-ai/pkg/util"
+
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+)
+
+// 强制对齐：用户提供了准确文稿，只借用识别结果里的词级时间戳，不采信识别出来的文字
+
+const (
+	alignStatusExact     = "exact"     // 整句在识别文本中精确命中
+	alignStatusAnchored  = "anchored"  // 句首、句尾锚点命中，中间文字与识别结果有出入
+	alignStatusUnaligned = "unaligned" // 未能对齐，时间由前后句插值得到
+)
+
+const (
+	// forcedAlignmentSearchWindow 每句从上一句结束位置向后最多额外搜索的字符数，避免短句误跳到很远的位置
+	forcedAlignmentSearchWindow = 300
+	forcedAlignmentMinAnchorLen = 4
+	forcedAlignmentMaxAnchorLen = 12
+)
+
+// AlignedSentence 文稿中一句话的对齐结果，时间为相对整段音频的秒数
+type AlignedSentence struct {
+	Index  int     `json:"index"`
+	Text   string  `json:"text"`
+	Status string  `json:"status"`
+	Start  float64 `json:"start"`
+	End    float64 `json:"end"`
+}
+
+// ForcedAlignmentReport 对齐报告，供用户检查哪些句子没有对齐上
+type ForcedAlignmentReport struct {
+	Total     int               `json:"total"`
+	Exact     int               `json:"exact"`
+	Anchored  int               `json:"anchored"`
+	Unaligned int               `json:"unaligned"`
+	Sentences []AlignedSentence `json:"sentences"`
+}
+
+// AlignSentences 将文稿句子按顺序对齐到识别出的词上。
+// 与 MatchSentenceTimestamp 不同，这里不做模糊匹配：对不上的句子标记为 unaligned，时间按前后句插值
+func (jlm *BaseLanguageMatcher) AlignSentences(sentences []string, words []types.Word) []AlignedSentence {
+	fullText, runeWordIdx := jlm.buildAlignIndex(words)
+	result := make([]AlignedSentence, len(sentences))
+	cursor := 0
+
+	for i, sentence := range sentences {
+		result[i] = AlignedSentence{Index: i + 1, Text: sentence, Status: alignStatusUnaligned}
+		target := jlm.normalizeAlignText(sentence)
+		if len(target) == 0 || cursor >= len(fullText) {
+			continue
+		}
+
+		limit := min(cursor+forcedAlignmentSearchWindow+2*len(target), len(fullText))
+		begin, end, status := -1, -1, ""
+		if pos := indexRunes(fullText[cursor:limit], target); pos >= 0 {
+			begin, end, status = cursor+pos, cursor+pos+len(target), alignStatusExact
+		} else if anchorLen := min(max(len(target)/3, forcedAlignmentMinAnchorLen), forcedAlignmentMaxAnchorLen); len(target) >= 2*anchorLen {
+			// 句首锚点
+			headPos := indexRunes(fullText[cursor:limit], target[:anchorLen])
+			if headPos >= 0 {
+				headBegin := cursor + headPos
+				tailLimit := min(headBegin+2*len(target), len(fullText))
+				// 句尾锚点必须出现在句首锚点之后，且跨度不超过句子长度的两倍
+				if headBegin+anchorLen < tailLimit {
+					tailPos := indexRunes(fullText[headBegin+anchorLen:tailLimit], target[len(target)-anchorLen:])
+					if tailPos >= 0 {
+						begin, end, status = headBegin, headBegin+anchorLen+tailPos+anchorLen, alignStatusAnchored
+					}
+				}
+			}
+		}
+		if begin < 0 {
+			continue
+		}
+
+		result[i].Start = words[runeWordIdx[begin]].Start
+		result[i].End = words[runeWordIdx[end-1]].End
+		result[i].Status = status
+		cursor = end
+	}
+
+	jlm.fillUnalignedTimestamps(result, words)
+	return result
+}
+
+// buildAlignIndex 拼接所有词的规范化文本，并记录每个字符所属的词下标
+func (jlm *BaseLanguageMatcher) buildAlignIndex(words []types.Word) ([]rune, []int) {
+	var fullText []rune
+	var runeWordIdx []int
+	for i, word := range words {
+		for _, r := range jlm.normalizeAlignText(word.Text) {
+			fullText = append(fullText, r)
+			runeWordIdx = append(runeWordIdx, i)
+		}
+	}
+	return fullText, runeWordIdx
+}
+
+// normalizeAlignText 在 cleanBaseText 的基础上统一大小写，文稿和识别结果的大小写经常不一致
+func (jlm *BaseLanguageMatcher) normalizeAlignText(text string) []rune {
+	runes := []rune(jlm.cleanBaseText(text))
+	for i, r := range runes {
+		runes[i] = unicode.ToLower(r)
+	}
+	return runes
+}
+
+// fillUnalignedTimestamps 保证时间单调递增，并把未对齐的句子按字符数插值到前后已对齐句子之间
+func (jlm *BaseLanguageMatcher) fillUnalignedTimestamps(result []AlignedSentence, words []types.Word) {
+	var audioStart, audioEnd float64
+	if len(words) > 0 {
+		audioStart = words[0].Start
+		audioEnd = words[len(words)-1].End
+	}
+
+	lastEnd := audioStart
+	for i := 0; i < len(result); {
+		if result[i].Status != alignStatusUnaligned {
+			if result[i].Start < lastEnd {
+				result[i].Start = lastEnd
+			}
+			if result[i].End < result[i].Start {
+				result[i].End = result[i].Start
+			}
+			lastEnd = result[i].End
+			i++
+			continue
+		}
+
+		// 找到连续未对齐的一段 [i, j)
+		j := i
+		totalChars := 0
+		for j < len(result) && result[j].Status == alignStatusUnaligned {
+			totalChars += max(len(jlm.normalizeAlignText(result[j].Text)), 1)
+			j++
+		}
+		spanEnd := max(audioEnd, lastEnd)
+		if j < len(result) {
+			spanEnd = max(result[j].Start, lastEnd)
+		}
+
+		cur := lastEnd
+		for k := i; k < j; k++ {
+			chars := max(len(jlm.normalizeAlignText(result[k].Text)), 1)
+			result[k].Start = cur
+			cur += (spanEnd - lastEnd) * float64(chars) / float64(totalChars)
+			result[k].End = cur
+		}
+		lastEnd = cur
+		i = j
+	}
+}
+
+// indexRunes 返回 sub 在 s 中第一次出现的位置，不存在返回 -1
+func indexRunes(s, sub []rune) int {
+	for i := 0; i+len(sub) <= len(s); i++ {
+		match := true
+		for j := range sub {
+			if s[i+j] != sub[j] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return i
+		}
+	}
+	return -1
+}
+
+// alignTranscriptToSrt 用户提供文稿时替代 audioToSrt：识别只用来拿词级时间戳，字幕文字以文稿为准
+func (s Service) alignTranscriptToSrt(ctx context.Context, stepParam *types.SubtitleTaskStepParam) error {
+	log.GetLogger().Info("audioToSubtitle.alignTranscriptToSrt start", zap.Any("taskId", stepParam.TaskId))
+
+	transcript, err := os.ReadFile(stepParam.UserTranscriptFilePath)
+	if err != nil {
+		return fmt.Errorf("alignTranscriptToSrt read transcript err: %w", err)
+	}
+	sentences := util.SplitTextSentences(string(transcript), config.Conf.App.MaxSentenceLength)
+	if len(sentences) == 0 {
+		return fmt.Errorf("alignTranscriptToSrt transcript is empty")
+	}
+
+	stepParam.TaskPtr.ProcessPct = 15
+	words, err := s.transcribeWordsForAlignment(ctx, stepParam)
+	if err != nil {
+		return fmt.Errorf("alignTranscriptToSrt %w", err)
+	}
+	stepParam.TranscribedWords = words
+	stepParam.TaskPtr.ProcessPct = 60
+	_ = storage.SaveTask(stepParam.TaskPtr)
+
+	matcher := &BaseLanguageMatcher{language: stepParam.OriginLanguage}
+	aligned := matcher.AlignSentences(sentences, words)
+
+	report := ForcedAlignmentReport{Total: len(aligned), Sentences: aligned}
+	for _, sentence := range aligned {
+		switch sentence.Status {
+		case alignStatusExact:
+			report.Exact++
+		case alignStatusAnchored:
+			report.Anchored++
+		default:
+			report.Unaligned++
+		}
+	}
+	log.GetLogger().Info("alignTranscriptToSrt alignment finished", zap.Any("taskId", stepParam.TaskId),
+		zap.Int("total", report.Total), zap.Int("exact", report.Exact), zap.Int("anchored", report.Anchored), zap.Int("unaligned", report.Unaligned))
+
+	reportPath := filepath.Join(stepParam.TaskBasePath, "output", types.SubtitleTaskForcedAlignmentReportFileName)
+	if err = util.SaveToDisk(report, reportPath); err != nil {
+		return fmt.Errorf("alignTranscriptToSrt save report err: %w", err)
+	}
+	reportInfo := types.SubtitleFileInfo{
+		Name:               "Forced Alignment Report",
+		Path:               reportPath,
+		LanguageIdentifier: "alignment_report",
+	}
+	if stepParam.UserUILanguage == types.LanguageNameSimplifiedChinese {
+		reportInfo.Name = "强制对齐报告"
+	}
+	stepParam.SubtitleInfos = append(stepParam.SubtitleInfos, reportInfo)
+
+	// 翻译文稿，失败的句子会回退为原文
+	var translated []*TranslatedItem
+	if stepParam.TargetLanguage != "none" {
+		stepParam.TaskPtr.StatusMsg = "正在翻译 Translating..."
+		_ = storage.SaveTask(stepParam.TaskPtr)
+		translated = s.translateSentences(ctx, stepParam, sentences, stepParam.TargetLanguage, 0)
+	}
+
+	srtBlocks := make([]*util.SrtBlock, 0, len(aligned))
+	for i, sentence := range aligned {
+		block := &util.SrtBlock{
+			Index:                  i + 1,
+			Timestamp:              util.ConvertTimes(float32(sentence.Start), float32(sentence.End)),
+			OriginLanguageSentence: sentence.Text,
+			TargetLanguageSentence: sentence.Text,
+		}
+		if i < len(translated) && translated[i] != nil && strings.TrimSpace(translated[i].TranslatedText) != "" {
+			block.TargetLanguageSentence = translated[i].TranslatedText
+		}
+		srtBlocks = append(srtBlocks, block)
+	}
+
+	bilingualFile := filepath.Join(stepParam.TaskBasePath, types.SubtitleTaskBilingualSrtFileName)
+	if err = writeBilingualSrtFile(bilingualFile, srtBlocks, stepParam.SubtitleResultType); err != nil {
+		return fmt.Errorf("alignTranscriptToSrt %w", err)
+	}
+	stepParam.BilingualSrtFilePath = bilingualFile
+	stepParam.TaskPtr.ProcessPct = 90
+
+	log.GetLogger().Info("audioToSubtitle.alignTranscriptToSrt end", zap.Any("taskId", stepParam.TaskId))
+	return nil
+}
+
+// transcribeWordsForAlignment 分段识别整段音频，返回带全局时间偏移的词列表，已有的识别结果会被复用
+func (s Service) transcribeWordsForAlignment(ctx context.Context, stepParam *types.SubtitleTaskStepParam) ([]types.Word, error) {
+	timePoints, err := GetSplitPoints(stepParam.AudioFilePath, float64(config.Conf.App.SegmentDuration)*60)
+	if err != nil {
+		return nil, fmt.Errorf("transcribeWordsForAlignment GetSplitPoints err: %w", err)
+	}
+	segmentNum := len(timePoints) - 1
+	segmentWords := make([][]types.Word, segmentNum)
+
+	eg, ctx := errgroup.WithContext(ctx)
+	eg.SetLimit(max(config.Conf.App.TranscribeParallelNum, 1))
+	for i := range segmentNum {
+		eg.Go(func() error {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+
+			var transcriptionData *types.TranscriptionData
+			savePath := filepath.Join(stepParam.TaskBasePath, fmt.Sprintf(types.SubtitleTaskAudioTranscriptionDataPersistenceFileNamePattern, i))
+			if fileBytes, err := os.ReadFile(savePath); err == nil && json.Unmarshal(fileBytes, &transcriptionData) == nil && transcriptionData != nil && len(transcriptionData.Words) > 0 {
+				log.GetLogger().Info("Resume Capability: Found existing transcription data, skipping Whisper", zap.String("path", savePath))
+			} else {
+				audioFile := filepath.Join(stepParam.TaskBasePath, fmt.Sprintf(types.SubtitleTaskSplitAudioFileNamePattern, i))
+				if err = ClipAudio(stepParam.AudioFilePath, audioFile, timePoints[i], timePoints[i+1]); err != nil {
+					return fmt.Errorf("transcribeWordsForAlignment ClipAudio err: %w", err)
+				}
+				for range config.Conf.App.TranscribeMaxAttempts {
+					transcriptionData, err = s.transcribeAudio(ctx, i, audioFile, string(stepParam.OriginLanguage), stepParam.TaskBasePath)
+					if err == nil {
+						break
+					}
+				}
+				if err != nil || transcriptionData == nil {
+					return fmt.Errorf("transcribeWordsForAlignment transcribeAudio err: %w", err)
+				}
+			}
+
+			words := make([]types.Word, 0, len(transcriptionData.Words))
+			for _, word := range transcriptionData.Words {
+				word.Start += timePoints[i]
+				word.End += timePoints[i]
+				words = append(words, word)
+			}
+			segmentWords[i] = words
+			log.GetLogger().Info("Transcribe completed", zap.Any("taskId", stepParam.TaskId), zap.Any("splitId", i))
+			return nil
+		})
+	}
+	if err = eg.Wait(); err != nil {
+		return nil, err
+	}
+
+	var words []types.Word
+	for _, segment := range segmentWords {
+		for _, word := range segment {
+			word.Num = len(words)
+			words = append(words, word)
+		}
+	}
+	return words, nil
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"testing"
+
+	"krillin-ai/internal/types"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func buildTestWords(texts []string) []types.Word {
+	words := make([]types.Word, 0, len(texts))
+	for i, text := range texts {
+		words = append(words, types.Word{Num: i, Text: text, Start: float64(i), End: float64(i) + 0.8})
+	}
+	return words
+}
+
+func TestAlignSentences_ExactAndCaseInsensitive(t *testing.T) {
+	words := buildTestWords([]string{"hello", "world", "this", "is", "a", "test"})
+	matcher := &BaseLanguageMatcher{}
+
+	aligned := matcher.AlignSentences([]string{"Hello, World!", "This is a test."}, words)
+
+	assert.Len(t, aligned, 2)
+	assert.Equal(t, alignStatusExact, aligned[0].Status)
+	assert.Equal(t, 0.0, aligned[0].Start)
+	assert.Equal(t, 1.8, aligned[0].End)
+	assert.Equal(t, alignStatusExact, aligned[1].Status)
+	assert.Equal(t, 2.0, aligned[1].Start)
+	assert.Equal(t, 5.8, aligned[1].End)
+}
+
+func TestAlignSentences_AnchoredWhenMiddleDiffers(t *testing.T) {
+	// ASR 把 "eighteen" 识别成了 "18"，首尾锚点仍然可以对齐
+	words := buildTestWords([]string{"welcome", "back", "to", "episode", "18", "of", "the", "podcast", "everyone"})
+	matcher := &BaseLanguageMatcher{}
+
+	aligned := matcher.AlignSentences([]string{"Welcome back to episode eighteen of the podcast everyone"}, words)
+
+	assert.Equal(t, alignStatusAnchored, aligned[0].Status)
+	assert.Equal(t, 0.0, aligned[0].Start)
+	assert.Equal(t, 8.8, aligned[0].End)
+}
+
+func TestAlignSentences_UnalignedIsInterpolated(t *testing.T) {
+	words := buildTestWords([]string{"first", "sentence", "zzz", "qqq", "last", "sentence"})
+	matcher := &BaseLanguageMatcher{}
+
+	aligned := matcher.AlignSentences([]string{"First sentence.", "Not spoken.", "Last sentence."}, words)
+
+	assert.Equal(t, alignStatusExact, aligned[0].Status)
+	assert.Equal(t, alignStatusUnaligned, aligned[1].Status)
+	assert.Equal(t, alignStatusExact, aligned[2].Status)
+	assert.Equal(t, aligned[0].End, aligned[1].Start)
+	assert.Equal(t, aligned[2].Start, aligned[1].End)
+}
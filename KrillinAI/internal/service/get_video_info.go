@@ -85,7 +85,7 @@ func (s Service) getVideoInfo(ctx context.Context, stepParam *types.SubtitleTask
 		// 3. AI 总结与翻译
 		var result string
 		// 使用新的 SummarizePrompt
-		result, err = s.ChatCompleter.ChatCompletion(fmt.Sprintf(types.SummaryAndTitlePrompt, title+"####"+description))
+		result, err = s.chat(ctx, llmCallSummary, s.renderPrompt(promptKindSummaryAndTitle, summaryPromptVars{Content: title + "####" + description}))
 		if err != nil {
 			log.GetLogger().Error("getVideoInfo openai chat completion error", zap.Any("stepParam", stepParam), zap.Error(err))
 		}
@@ -95,7 +95,6 @@ func (s Service) getVideoInfo(ctx context.Context, stepParam *types.SubtitleTask
 		taskPtr.Title = title
 		taskPtr.Description = description
 		taskPtr.OriginLanguage = string(stepParam.OriginLanguage)
-		taskPtr.TargetLanguage = string(stepParam.TargetLanguage)
 		taskPtr.ProcessPct = 10
 
 		splitResult := strings.Split(result, "####")
@@ -168,8 +167,8 @@ func (s Service) generateSummaryIfMissing(ctx context.Context, stepParam *types.
 	}
 
 	// 4. 调用LLM生成总结
-	prompt := fmt.Sprintf(types.SummaryTranscriptPrompt, text)
-	result, err := s.ChatCompleter.ChatCompletion(prompt)
+	prompt := s.renderPrompt(promptKindSummaryTranscript, summaryPromptVars{Content: text})
+	result, err := s.chat(ctx, llmCallSummary, prompt)
 	if err != nil {
 		log.GetLogger().Error("generateSummaryIfMissing chat completion error", zap.Error(err))
 		return
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"krillin-ai/internal/types"
+)
+
+// 术语表：翻译前只把本批次中出现的术语注入 prompt，翻译后校验译文是否使用了规定译法
+
+// glossaryTermsForLanguage 返回适用于某个目标语言的术语：未指定目标语言的术语适用于所有语言，
+// 同一原文术语同时有通用译法和该语言的译法时以该语言的为准
+func glossaryTermsForLanguage(terms []types.GlossaryTerm, language types.StandardLanguageCode) []types.GlossaryTerm {
+	specific := make(map[string]bool)
+	for _, term := range terms {
+		if term.TargetLanguage == string(language) {
+			specific[term.Source] = true
+		}
+	}
+	result := make([]types.GlossaryTerm, 0, len(terms))
+	for _, term := range terms {
+		if term.TargetLanguage == string(language) || (term.TargetLanguage == "" && !specific[term.Source]) {
+			result = append(result, term)
+		}
+	}
+	return result
+}
+
+// matchGlossaryTerms 返回在这批句子中出现过的术语
+func matchGlossaryTerms(sentences []string, terms []types.GlossaryTerm) []types.GlossaryTerm {
+	matched := make([]types.GlossaryTerm, 0)
+	for _, term := range terms {
+		for _, sentence := range sentences {
+			if containsGlossaryTerm(sentence, term.Source, term.CaseSensitive, true) {
+				matched = append(matched, term)
+				break
+			}
+		}
+	}
+	return matched
+}
+
+// buildGlossaryPrompt 生成附加在批量翻译 prompt 中的术语要求，没有术语时返回空
+func buildGlossaryPrompt(terms []types.GlossaryTerm) string {
+	if len(terms) == 0 {
+		return ""
+	}
+	lines := make([]string, 0, len(terms))
+	for _, term := range terms {
+		if term.DoNotTranslate || term.Target == "" {
+			lines = append(lines, fmt.Sprintf("- %q -> (keep as is)", term.Source))
+		} else {
+			lines = append(lines, fmt.Sprintf("- %q -> %q", term.Source, term.Target))
+		}
+	}
+	return fmt.Sprintf(types.BatchTranslateGlossaryPrompt, strings.Join(lines, "\n"))
+}
+
+// findGlossaryViolations 逐行检查原文中出现的术语在译文中是否使用了规定译法，返回违规描述
+func findGlossaryViolations(sources, translations []string, terms []types.GlossaryTerm) []string {
+	violations := make([]string, 0)
+	for i, source := range sources {
+		if i >= len(translations) {
+			break
+		}
+		for _, term := range terms {
+			if !containsGlossaryTerm(source, term.Source, term.CaseSensitive, true) {
+				continue
+			}
+			expected := term.ExpectedTranslation()
+			if containsGlossaryTerm(translations[i], expected, term.CaseSensitive, false) {
+				continue
+			}
+			violations = append(violations, fmt.Sprintf("- line %d: %q must be translated as %q", i+1, term.Source, expected))
+		}
+	}
+	return violations
+}
+
+// containsGlossaryTerm 判断文本中是否包含术语，wholeWord 为 true 时术语首尾若是字母数字，要求两侧不能紧接字母数字，
+// 避免 "AI" 命中 "said"；中日文等不以空格分词的文字不做边界判断
+func containsGlossaryTerm(text, term string, caseSensitive, wholeWord bool) bool {
+	if term == "" {
+		return false
+	}
+	if !caseSensitive {
+		text, term = strings.ToLower(text), strings.ToLower(term)
+	}
+	offset := 0
+	for {
+		pos := strings.Index(text[offset:], term)
+		if pos < 0 {
+			return false
+		}
+		begin := offset + pos
+		end := begin + len(term)
+		if !wholeWord || (glossaryBoundaryOk(text[:begin], term, true) && glossaryBoundaryOk(text[end:], term, false)) {
+			return true
+		}
+		offset = begin + 1
+		for offset < len(text) && !utf8.RuneStart(text[offset]) {
+			offset++
+		}
+	}
+}
+
+// glossaryBoundaryOk 检查术语某一侧的边界，before 为 true 时检查术语前面的字符
+func glossaryBoundaryOk(neighbor, term string, before bool) bool {
+	var edge, adjacent rune
+	if before {
+		edge, _ = utf8.DecodeRuneInString(term)
+		adjacent, _ = utf8.DecodeLastRuneInString(neighbor)
+	} else {
+		edge, _ = utf8.DecodeLastRuneInString(term)
+		adjacent, _ = utf8.DecodeRuneInString(neighbor)
+	}
+	if neighbor == "" || !isSpacedWordRune(edge) {
+		return true
+	}
+	return !isSpacedWordRune(adjacent)
+}
+
+func isSpacedWordRune(r rune) bool {
+	if unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Thai) {
+		return false
+	}
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"krillin-ai/config"
+	"krillin-ai/internal/mocks"
+	"krillin-ai/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestMatchGlossaryTerms_OnlyPresentTerms(t *testing.T) {
+	terms := []types.GlossaryTerm{
+		{Source: "AI", Target: "人工智能", CaseSensitive: true},
+		{Source: "krillin", DoNotTranslate: true},
+		{Source: "GPU", Target: "显卡"},
+	}
+
+	matched := matchGlossaryTerms([]string{"He said Krillin is great.", "We use AI here."}, terms)
+
+	assert.Len(t, matched, 2)
+	assert.Equal(t, "AI", matched[0].Source)
+	assert.Equal(t, "krillin", matched[1].Source)
+	assert.Empty(t, matchGlossaryTerms([]string{"He said it is fair."}, terms))
+}
+
+func TestFindGlossaryViolations(t *testing.T) {
+	terms := []types.GlossaryTerm{
+		{Source: "GPU", Target: "显卡"},
+		{Source: "Krillin", DoNotTranslate: true},
+	}
+
+	violations := findGlossaryViolations(
+		[]string{"The GPU is hot.", "Krillin rocks."},
+		[]string{"显卡很热。", "克林很棒。"},
+		terms,
+	)
+
+	assert.Len(t, violations, 1)
+	assert.Contains(t, violations[0], "line 2")
+}
+
+func TestTranslateSentences_RepromptsOnGlossaryViolation(t *testing.T) {
+	oldAttempts := config.Conf.App.TranslateMaxAttempts
+	oldParallel := config.Conf.App.TranslateParallelNum
+	config.Conf.App.TranslateMaxAttempts = 2
+	config.Conf.App.TranslateParallelNum = 1
+	defer func() {
+		config.Conf.App.TranslateMaxAttempts = oldAttempts
+		config.Conf.App.TranslateParallelNum = oldParallel
+	}()
+
+	glossary := []types.GlossaryTerm{{Source: "GPU", Target: "显卡"}, {Source: "TPU", Target: "张量处理器"}}
+	mockChatCompleter := new(mocks.MockChatCompleter)
+	mockChatCompleter.On("ChatCompletion", mock.MatchedBy(func(prompt string) bool {
+		return strings.Contains(prompt, `"GPU" -> "显卡"`) && !strings.Contains(prompt, "TPU") && !strings.Contains(prompt, "Attention")
+	})).Return(`["图形处理器很热。"]`, nil).Once()
+	mockChatCompleter.On("ChatCompletion", mock.MatchedBy(func(prompt string) bool {
+		return strings.Contains(prompt, "Attention") && strings.Contains(prompt, `"GPU" must be translated as "显卡"`)
+	})).Return(`["显卡很热。"]`, nil).Once()
+	svc := Service{ChatCompleter: mockChatCompleter}
+
+	results := svc.translateSentences(context.Background(), &types.SubtitleTaskStepParam{GlossaryTerms: glossary}, []string{"The GPU is hot."}, types.LanguageNameSimplifiedChinese, 0)
+
+	assert.Equal(t, "显卡很热。", results[0].TranslatedText)
+	mockChatCompleter.AssertExpectations(t)
+}
+
+func TestGlossaryTermsForLanguage(t *testing.T) {
+	terms := []types.GlossaryTerm{
+		{Source: "GPU", Target: "显卡", TargetLanguage: string(types.LanguageNameSimplifiedChinese)},
+		{Source: "GPU", Target: "GPU"},
+		{Source: "TPU", Target: "テンソル処理装置", TargetLanguage: string(types.LanguageNameJapanese)},
+		{Source: "Krillin", DoNotTranslate: true},
+	}
+
+	assert.Equal(t, []types.GlossaryTerm{terms[0], terms[3]}, glossaryTermsForLanguage(terms, types.LanguageNameSimplifiedChinese))
+	assert.Equal(t, []types.GlossaryTerm{terms[1], terms[2], terms[3]}, glossaryTermsForLanguage(terms, types.LanguageNameJapanese))
+}
+
+func TestTranslateSentences_IgnoresTermsForOtherLanguages(t *testing.T) {
+	oldParallel := config.Conf.App.TranslateParallelNum
+	config.Conf.App.TranslateParallelNum = 1
+	defer func() { config.Conf.App.TranslateParallelNum = oldParallel }()
+
+	glossary := []types.GlossaryTerm{
+		{Source: "GPU", Target: "显卡", TargetLanguage: string(types.LanguageNameSimplifiedChinese)},
+		{Source: "GPU", Target: "GPU", TargetLanguage: string(types.LanguageNameJapanese)},
+	}
+	mockChatCompleter := new(mocks.MockChatCompleter)
+	mockChatCompleter.On("ChatCompletion", mock.MatchedBy(func(prompt string) bool {
+		return strings.Contains(prompt, `"GPU" -> "GPU"`) && !strings.Contains(prompt, "显卡")
+	})).Return(`["GPUが熱い。"]`, nil).Once()
+	svc := Service{ChatCompleter: mockChatCompleter}
+
+	results := svc.translateSentences(context.Background(), &types.SubtitleTaskStepParam{GlossaryTerms: glossary}, []string{"The GPU is hot."}, types.LanguageNameJapanese, 0)
+
+	// 中文术语不参与日文译文的校验，不会因为缺少“显卡”而重译
+	assert.Equal(t, "GPUが熱い。", results[0].TranslatedText)
+	mockChatCompleter.AssertExpectations(t)
+}
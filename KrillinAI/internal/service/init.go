@@ -7,10 +7,12 @@ import (
 	"krillin-ai/pkg/aliyun"
 	"krillin-ai/pkg/doubao"
 	"krillin-ai/pkg/fasterwhisper"
+	"krillin-ai/pkg/mt"
 	"krillin-ai/pkg/openai"
 	"krillin-ai/pkg/tts"
 	"krillin-ai/pkg/whisper"
 	"krillin-ai/pkg/whispercpp"
+	"krillin-ai/pkg/whispercppserver"
 	"krillin-ai/pkg/whisperkit"
 
 	"go.uber.org/zap"
@@ -19,9 +21,11 @@ import (
 type Service struct {
 	Transcriber      types.Transcriber
 	ChatCompleter    types.ChatCompleter
+	MtTranslator     types.Translator // 通用机器翻译，未配置时为nil
 	TtsClient        types.Ttser
 	OssClient        *aliyun.OssClient
 	VoiceCloneClient *doubao.VoiceCloneClient
+	PromptTemplates  map[string]string // 任务选用的提示词模板，按模板类型索引，未选用的类型使用配置默认或内置模板
 }
 
 func NewService() *Service {
@@ -36,6 +40,8 @@ func NewService() *Service {
 		transcriber = fasterwhisper.NewFastwhisperProcessor(config.Conf.Transcribe.Fasterwhisper.Model)
 	case "whispercpp":
 		transcriber = whispercpp.NewWhispercppProcessor(config.Conf.Transcribe.Whispercpp.Model)
+	case "whispercpp_server":
+		transcriber = whispercppserver.NewClient(config.Conf.Transcribe.WhispercppServer.BaseUrl, config.Conf.Transcribe.WhispercppServer.InferencePath, config.Conf.Transcribe.WhispercppServer.Model)
 	case "whisperkit":
 		transcriber = whisperkit.NewWhisperKitProcessor(config.Conf.Transcribe.Whisperkit.Model)
 	case "aliyun":
@@ -48,7 +54,18 @@ func NewService() *Service {
 	}
 	log.GetLogger().Info("当前选择的转录源： ", zap.String("transcriber", config.Conf.Transcribe.Provider))
 
-	chatCompleter = openai.NewClient(config.Conf.Llm.BaseUrl, config.Conf.Llm.ApiKey, config.Conf.App.Proxy)
+	// 各用途可以通过 llm.routes 使用不同的模型，提供方配置名称同时作为限流的额度名称
+	chatCompleter = newLlmRouter(
+		openai.NewClient(config.Conf.Llm.BaseUrl, config.Conf.Llm.ApiKey, config.Conf.App.Proxy).WithRateLimit("llm"),
+		func(profile config.LlmProfile) types.UsageChatCompleter {
+			return openai.NewClient(profile.BaseUrl, profile.ApiKey, config.Conf.App.Proxy).WithModel(profile.Model).WithRateLimit(profile.Name).WithTimeout(profile.Name)
+		},
+	)
+
+	var mtTranslator types.Translator
+	if config.Conf.Translate.Mt.BaseUrl != "" {
+		mtTranslator = mt.NewClient(config.Conf.Translate.Mt.Api, config.Conf.Translate.Mt.BaseUrl, config.Conf.Translate.Mt.ApiKey, config.Conf.App.Proxy)
+	}
 
 	// Use Composite TTS Client to support multiple providers dynamically
 	ttsClient = tts.NewCompositeTtsClient()
@@ -56,6 +73,7 @@ func NewService() *Service {
 	return &Service{
 		Transcriber:      transcriber,
 		ChatCompleter:    chatCompleter,
+		MtTranslator:     mtTranslator,
 		TtsClient:        ttsClient,
 		OssClient:        aliyun.NewOssClient(config.Conf.Transcribe.Aliyun.Oss.AccessKeyId, config.Conf.Transcribe.Aliyun.Oss.AccessKeySecret, config.Conf.Transcribe.Aliyun.Oss.Bucket),
 		VoiceCloneClient: doubao.NewVoiceCloneClient(config.Conf.Tts.VoiceCloneVolc.AppId, config.Conf.Tts.VoiceCloneVolc.AccessToken, config.Conf.Tts.VoiceCloneVolc.ResourceId),
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"fmt"
+	"os"
+
+	"krillin-ai/internal/types"
+	"krillin-ai/pkg/subtitle"
+	"krillin-ai/pkg/util"
+)
+
+// 卡拉OK字幕：原文按识别词的时间逐词高亮，用于语言学习类视频的字幕嵌入，导出的原文和双语 ASS 也带卡拉OK标签
+
+// parseKaraokeOptions 校验卡拉OK模式并把高亮颜色转为 ASS 格式，未启用时返回空
+func parseKaraokeOptions(mode, color string) (string, string, error) {
+	mode, err := subtitle.ParseKaraokeMode(mode)
+	if err != nil || mode == "" {
+		return "", "", err
+	}
+	if color == "" {
+		color = subtitle.DefaultKaraokeColour
+	}
+	assColor, err := subtitle.AssColour(color)
+	if err != nil {
+		return "", "", err
+	}
+	return mode, assColor, nil
+}
+
+// karaokeSettings 任务的卡拉OK设置，译文轨道没有对应的识别词，不做高亮
+func karaokeSettings(stepParam *types.SubtitleTaskStepParam, track string) *subtitle.Karaoke {
+	if stepParam.KaraokeMode == "" || track == subtitleTrackTarget {
+		return nil
+	}
+	karaoke := &subtitle.Karaoke{
+		Mode:    stepParam.KaraokeMode,
+		Colour:  stepParam.KaraokeColor,
+		SubText: track == subtitleTrackBilingual,
+	}
+	if stepParam.SubtitleStyle != nil {
+		// 未唱到的词使用样式预设中的文字颜色
+		karaoke.BaseColour = subtitle.PrimaryAssColour(stepParam.SubtitleStyle, karaoke.SubText)
+	}
+	return karaoke
+}
+
+// writeKaraokeAss 生成嵌入视频用的卡拉OK字幕，只有原文时用原文轨道，否则用双语轨道
+func writeKaraokeAss(stepParam *types.SubtitleTaskStepParam, assPath string, isHorizontal bool) error {
+	srtBlocks, err := readBilingualSrtFile(stepParam.BilingualSrtFilePath, stepParam.SubtitleResultType)
+	if err != nil {
+		return fmt.Errorf("writeKaraokeAss %w", err)
+	}
+	track := subtitleTrackBilingual
+	if stepParam.SubtitleResultType == types.SubtitleResultTypeOriginOnly {
+		track = subtitleTrackOrigin
+	}
+	subtitleTrack, err := buildSubtitleTrack(stepParam, srtBlocks, track)
+	if err != nil {
+		return fmt.Errorf("writeKaraokeAss %w", err)
+	}
+	// 与普通嵌入字幕一致，译文在上，原文在下并去掉首尾标点
+	subtitleTrack.SubTextOnTop = false
+	for i := range subtitleTrack.Cues {
+		subtitleTrack.Cues[i].SubText = util.CleanPunction(subtitleTrack.Cues[i].SubText)
+	}
+	subtitleTrack.AssHeader = types.AssHeaderHorizontal
+	if !isHorizontal {
+		subtitleTrack.AssHeader = types.AssHeaderVertical
+	}
+	if stepParam.SubtitleStyle != nil {
+		// 样式预设按视频分辨率生成 ASS 头，替代默认的横屏/竖屏样式
+		if subtitleTrack.PlayResX, subtitleTrack.PlayResY, err = getResolution(stepParam.InputVideoPath); err != nil {
+			return fmt.Errorf("writeKaraokeAss getResolution err: %w", err)
+		}
+	}
+	data, err := subtitle.Marshal(subtitleTrack, subtitle.FormatAss)
+	if err != nil {
+		return fmt.Errorf("writeKaraokeAss %w", err)
+	}
+	if err = os.WriteFile(assPath, data, 0644); err != nil {
+		return fmt.Errorf("writeKaraokeAss write ass err: %w", err)
+	}
+	return nil
+}
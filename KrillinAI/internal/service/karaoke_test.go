@@ -0,0 +1,78 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"krillin-ai/internal/types"
+	"krillin-ai/pkg/util"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseKaraokeOptions(t *testing.T) {
+	mode, color, err := parseKaraokeOptions("KF", "")
+	require.NoError(t, err)
+	assert.Equal(t, "kf", mode)
+	assert.Equal(t, "&H00FFFF&", color)
+
+	mode, color, err = parseKaraokeOptions("", "#FF0000")
+	require.NoError(t, err)
+	assert.Empty(t, mode)
+	assert.Empty(t, color)
+
+	_, _, err = parseKaraokeOptions("k", "red")
+	assert.Error(t, err)
+	_, _, err = parseKaraokeOptions("bounce", "")
+	assert.Error(t, err)
+}
+
+func TestWriteKaraokeAss_HighlightsOriginWords(t *testing.T) {
+	taskBasePath := t.TempDir()
+	bilingualFile := filepath.Join(taskBasePath, types.SubtitleTaskBilingualSrtFileName)
+	require.NoError(t, writeBilingualSrtFile(bilingualFile, []*util.SrtBlock{
+		{Index: 1, Timestamp: "00:00:01,000 --> 00:00:02,000", OriginLanguageSentence: "Hello world", TargetLanguageSentence: "你好世界"},
+	}, types.SubtitleResultTypeBilingualTranslationOnTop))
+	stepParam := &types.SubtitleTaskStepParam{
+		BilingualSrtFilePath: bilingualFile,
+		SubtitleResultType:   types.SubtitleResultTypeBilingualTranslationOnTop,
+		OriginLanguage:       types.LanguageNameEnglish,
+		TargetLanguage:       types.LanguageNameSimplifiedChinese,
+		KaraokeMode:          "k",
+		KaraokeColor:         "&H0000FF&",
+		TranscribedWords:     []types.Word{{Text: "Hello", Start: 1.0, End: 1.4}, {Text: "world", Start: 1.5, End: 1.9}},
+	}
+	assPath := filepath.Join(taskBasePath, "formatted_subtitles.ass")
+
+	require.NoError(t, writeKaraokeAss(stepParam, assPath, true))
+
+	content, err := os.ReadFile(assPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "Dialogue: 0,0:00:01.00,0:00:02.00,Major,,0,0,0,,{\\an2}{\\rMajor}你好世界\\N{\\rMinor}{\\1c&H0000FF&\\2c&HFFFFFF&}{\\k40}Hello{\\k50} world\n")
+}
+
+func TestWriteKaraokeAss_KeepsTranslationOnTop(t *testing.T) {
+	taskBasePath := t.TempDir()
+	bilingualFile := filepath.Join(taskBasePath, types.SubtitleTaskBilingualSrtFileName)
+	require.NoError(t, writeBilingualSrtFile(bilingualFile, []*util.SrtBlock{
+		{Index: 1, Timestamp: "00:00:01,000 --> 00:00:02,000", OriginLanguageSentence: "Hello world.", TargetLanguageSentence: "你好世界"},
+	}, types.SubtitleResultTypeBilingualTranslationOnBottom))
+	stepParam := &types.SubtitleTaskStepParam{
+		BilingualSrtFilePath: bilingualFile,
+		SubtitleResultType:   types.SubtitleResultTypeBilingualTranslationOnBottom,
+		OriginLanguage:       types.LanguageNameEnglish,
+		TargetLanguage:       types.LanguageNameSimplifiedChinese,
+		KaraokeMode:          "k",
+		KaraokeColor:         "&H0000FF&",
+		TranscribedWords:     []types.Word{{Text: "Hello", Start: 1.0, End: 1.4}, {Text: "world", Start: 1.5, End: 1.9}},
+	}
+	assPath := filepath.Join(taskBasePath, "formatted_subtitles.ass")
+
+	require.NoError(t, writeKaraokeAss(stepParam, assPath, false))
+
+	content, err := os.ReadFile(assPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "{\\an2}{\\rMajor}你好世界\\N{\\rMinor}{\\1c&H0000FF&\\2c&HFFFFFF&}{\\k40}Hello{\\k50} world\n")
+}
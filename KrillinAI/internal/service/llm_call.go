@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"krillin-ai/config"
+	"krillin-ai/internal/types"
+	"krillin-ai/log"
+
+	"go.uber.org/zap"
+)
+
+// 大模型调用按用途区分参数，ChatCompleter 支持完整参数时按配置构造请求，否则回退到 ChatCompletion
+
+// 用途同时作为用量统计的阶段
+const (
+	llmCallTranslate = types.UsageStageTranslate
+	llmCallSplit     = types.UsageStageSplit
+	llmCallSummary   = types.UsageStageSummary
+	llmCallClipper   = types.UsageStageClipper
+)
+
+// llmCallConfig 用途的调用参数，路由到其他提供方配置时叠加该配置中的参数
+func llmCallConfig(callType string) config.LlmCallConfig {
+	callConfig := baseLlmCallConfig(callType)
+	if profile, ok := llmProfileFor(callType); ok {
+		return mergeLlmCallConfig(callConfig, profile.Params)
+	}
+	return callConfig
+}
+
+func baseLlmCallConfig(callType string) config.LlmCallConfig {
+	switch callType {
+	case llmCallTranslate:
+		return config.Conf.LlmCalls.Translate
+	case llmCallSplit:
+		return config.Conf.LlmCalls.Split
+	case llmCallSummary:
+		return config.Conf.LlmCalls.Summary
+	case llmCallClipper:
+		return config.Conf.LlmCalls.Clipper
+	}
+	return config.LlmCallConfig{}
+}
+
+// newChatRequest 按用途的配置构造请求。配置为 json_schema 但调用方没有提供 schema 时退回 json_object
+func newChatRequest(callType, prompt string, schema *types.ChatJsonSchema) *types.ChatRequest {
+	callConfig := llmCallConfig(callType)
+	req := &types.ChatRequest{
+		Messages:        make([]types.ChatMessage, 0, 2),
+		Temperature:     callConfig.Temperature,
+		MaxTokens:       callConfig.MaxTokens,
+		ResponseFormat:  callConfig.ResponseFormat,
+		ReasoningEffort: callConfig.ReasoningEffort,
+		Stage:           callType,
+	}
+	if callConfig.SystemPrompt != "" {
+		req.Messages = append(req.Messages, types.ChatMessage{Role: types.ChatRoleSystem, Content: callConfig.SystemPrompt})
+	}
+	req.Messages = append(req.Messages, types.ChatMessage{Role: types.ChatRoleUser, Content: prompt})
+	if callConfig.Seed != 0 {
+		seed := callConfig.Seed
+		req.Seed = &seed
+	}
+	if req.ResponseFormat == types.ChatResponseFormatJsonSchema {
+		if schema == nil {
+			req.ResponseFormat = types.ChatResponseFormatJsonObject
+		} else {
+			req.JsonSchema = schema
+		}
+	}
+	return req
+}
+
+// llmResponseFormat 返回某个用途实际使用的输出格式，调用方据此调整提示词
+func llmResponseFormat(chatCompleter types.ChatCompleter, callType string, hasSchema bool) string {
+	if _, ok := chatCompleter.(types.StructuredChatCompleter); !ok {
+		return types.ChatResponseFormatText
+	}
+	responseFormat := llmCallConfig(callType).ResponseFormat
+	if responseFormat == types.ChatResponseFormatJsonSchema && !hasSchema {
+		return types.ChatResponseFormatJsonObject
+	}
+	return responseFormat
+}
+
+// chatWithConfig 按用途调用大模型，接口不支持配置的 JSON 模式时改用 text 重试一次，由调用方从文本中解析 JSON
+func chatWithConfig(ctx context.Context, chatCompleter types.ChatCompleter, callType, prompt string, schema *types.ChatJsonSchema) (string, error) {
+	structured, ok := chatCompleter.(types.StructuredChatCompleter)
+	if !ok {
+		return chatCompleter.ChatCompletion(ctx, prompt)
+	}
+	req := newChatRequest(callType, prompt, schema)
+	response, err := structured.Chat(ctx, req)
+	if err == nil || req.ResponseFormat == "" || req.ResponseFormat == types.ChatResponseFormatText || !isResponseFormatRejected(err) {
+		return response, err
+	}
+	log.GetLogger().Warn("chatWithConfig response_format rejected, retry with text", zap.String("callType", callType), zap.String("responseFormat", req.ResponseFormat), zap.Error(err))
+	textReq := *req
+	textReq.ResponseFormat = types.ChatResponseFormatText
+	textReq.JsonSchema = nil
+	return structured.Chat(ctx, &textReq)
+}
+
+// isResponseFormatRejected 接口是否因为不支持 response_format 拒绝了请求，各家报错不统一，只能按错误信息判断
+func isResponseFormatRejected(err error) bool {
+	message := strings.ToLower(err.Error())
+	for _, keyword := range []string{"response_format", "json_object", "json_schema", "json mode"} {
+		if strings.Contains(message, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s Service) chat(ctx context.Context, callType, prompt string) (string, error) {
+	return chatWithConfig(ctx, s.ChatCompleter, callType, prompt, nil)
+}
+
+// translationsJsonSchema 批量翻译的输出结构，条数由调用方校验
+var translationsJsonSchema = &types.ChatJsonSchema{
+	Name:   "subtitle_translations",
+	Schema: json.RawMessage(`{"type":"object","properties":{"translations":{"type":"array","items":{"type":"string"}}},"required":["translations"],"additionalProperties":false}`),
+	Strict: true,
+}
+
+// parseTranslationsResponse 解析批量翻译结果，兼容 JSON 数组和 JSON 模式下的 {"translations": [...]}
+func parseTranslationsResponse(response string) ([]string, error) {
+	var translated []string
+	if err := json.Unmarshal([]byte(response), &translated); err == nil {
+		return translated, nil
+	}
+	var wrapped struct {
+		Translations []string `json:"translations"`
+	}
+	if err := json.Unmarshal([]byte(response), &wrapped); err != nil {
+		return nil, err
+	}
+	if wrapped.Translations == nil {
+		return nil, fmt.Errorf("translations field is missing")
+	}
+	return wrapped.Translations, nil
+}
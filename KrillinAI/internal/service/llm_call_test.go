@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"krillin-ai/config"
+	"krillin-ai/internal/mocks"
+	"krillin-ai/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type stubStructuredChatCompleter struct {
+	requests       []*types.ChatRequest
+	response       string
+	rejectJsonMode bool // 模拟不支持 JSON 模式的接口
+}
+
+func (c *stubStructuredChatCompleter) ChatCompletion(ctx context.Context, query string) (string, error) {
+	panic("ChatCompletion should not be called when Chat is available")
+}
+
+func (c *stubStructuredChatCompleter) Chat(ctx context.Context, req *types.ChatRequest) (string, error) {
+	c.requests = append(c.requests, req)
+	if c.rejectJsonMode && req.ResponseFormat != types.ChatResponseFormatText {
+		return "", errors.New("error, status code: 400, message: 'response_format.type' json_object is not supported by this model")
+	}
+	return c.response, nil
+}
+
+func TestNewChatRequest_UsesCallTypeConfig(t *testing.T) {
+	oldCalls := config.Conf.LlmCalls
+	defer func() { config.Conf.LlmCalls = oldCalls }()
+	config.Conf.LlmCalls.Split = config.LlmCallConfig{SystemPrompt: "split it", Temperature: 0.2, MaxTokens: 1024, ResponseFormat: "json_schema", Seed: 7, ReasoningEffort: "low"}
+	config.Conf.LlmCalls.Summary = config.LlmCallConfig{}
+
+	req := newChatRequest(llmCallSplit, "hello", nil)
+	assert.Equal(t, []types.ChatMessage{{Role: types.ChatRoleSystem, Content: "split it"}, {Role: types.ChatRoleUser, Content: "hello"}}, req.Messages)
+	assert.Equal(t, float32(0.2), req.Temperature)
+	assert.Equal(t, 1024, req.MaxTokens)
+	require.NotNil(t, req.Seed)
+	assert.Equal(t, 7, *req.Seed)
+	assert.Equal(t, "low", req.ReasoningEffort)
+	// 没有 schema 时 json_schema 退回 json_object
+	assert.Equal(t, types.ChatResponseFormatJsonObject, req.ResponseFormat)
+	assert.Nil(t, req.JsonSchema)
+
+	req = newChatRequest(llmCallSplit, "hello", translationsJsonSchema)
+	assert.Equal(t, types.ChatResponseFormatJsonSchema, req.ResponseFormat)
+	assert.Equal(t, translationsJsonSchema, req.JsonSchema)
+
+	req = newChatRequest(llmCallSummary, "hello", nil)
+	assert.Equal(t, []types.ChatMessage{{Role: types.ChatRoleUser, Content: "hello"}}, req.Messages)
+	assert.Nil(t, req.Seed)
+}
+
+func TestLlmTranslator_JsonModeWrapsTranslations(t *testing.T) {
+	oldCalls := config.Conf.LlmCalls
+	defer func() { config.Conf.LlmCalls = oldCalls }()
+	config.Conf.LlmCalls.Translate = config.LlmCallConfig{Temperature: 0.3, ResponseFormat: "json_object"}
+
+	completer := &stubStructuredChatCompleter{response: `{"translations": ["你好", "再见"]}`}
+	translated, err := LlmTranslator{ChatCompleter: completer}.Translate(context.Background(), []string{"Hello", "Bye"}, types.LanguageNameEnglish, types.LanguageNameSimplifiedChinese, "")
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"你好", "再见"}, translated)
+	require.Len(t, completer.requests, 1)
+	assert.Equal(t, types.ChatResponseFormatJsonObject, completer.requests[0].ResponseFormat)
+	assert.Contains(t, completer.requests[0].Messages[0].Content, `"translations"`)
+}
+
+func TestLlmTranslator_PlainChatCompleterKeepsArrayPrompt(t *testing.T) {
+	mockChatCompleter := new(mocks.MockChatCompleter)
+	mockChatCompleter.On("ChatCompletion", mock.MatchedBy(func(prompt string) bool {
+		return strings.Contains(prompt, "Hello") && !strings.Contains(prompt, "Output Format Override")
+	})).Return("```json\n[\"你好\"]\n```", nil).Once()
+
+	translated, err := LlmTranslator{ChatCompleter: mockChatCompleter}.Translate(context.Background(), []string{"Hello"}, types.LanguageNameEnglish, types.LanguageNameSimplifiedChinese, "")
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"你好"}, translated)
+	mockChatCompleter.AssertExpectations(t)
+}
+
+func TestLlmTranslator_FallsBackToTextWhenJsonModeRejected(t *testing.T) {
+	oldCalls := config.Conf.LlmCalls
+	defer func() { config.Conf.LlmCalls = oldCalls }()
+	config.Conf.LlmCalls.Translate = config.LlmCallConfig{ResponseFormat: "json_object"}
+
+	completer := &stubStructuredChatCompleter{response: "```json\n{\"translations\": [\"你好\"]}\n```", rejectJsonMode: true}
+	translated, err := LlmTranslator{ChatCompleter: completer}.Translate(context.Background(), []string{"Hello"}, types.LanguageNameEnglish, types.LanguageNameSimplifiedChinese, "")
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"你好"}, translated)
+	require.Len(t, completer.requests, 2)
+	assert.Equal(t, types.ChatResponseFormatJsonObject, completer.requests[0].ResponseFormat)
+	assert.Equal(t, types.ChatResponseFormatText, completer.requests[1].ResponseFormat)
+}
+
+func TestChatWithConfig_OnlyRetriesRejectedJsonMode(t *testing.T) {
+	oldCalls := config.Conf.LlmCalls
+	defer func() { config.Conf.LlmCalls = oldCalls }()
+	config.Conf.LlmCalls.Translate = config.LlmCallConfig{ResponseFormat: "text"}
+
+	completer := &stubStructuredChatCompleter{rejectJsonMode: true, response: "ok"}
+	response, err := chatWithConfig(context.Background(), completer, llmCallTranslate, "hello", nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, "ok", response)
+	require.Len(t, completer.requests, 1)
+	assert.False(t, isResponseFormatRejected(errors.New("error, status code: 429, message: rate limit exceeded")))
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"krillin-ai/config"
+	"krillin-ai/internal/types"
+)
+
+// 大模型路由：按调用用途把请求发到 llm.routes 指定的提供方配置，未配置路由的用途使用默认的 [llm] 配置
+
+// llmProfileFor 返回用途路由到的提供方配置，留空的字段已沿用默认配置；没有路由时返回 false
+func llmProfileFor(callType string) (config.LlmProfile, bool) {
+	name := config.Conf.Llm.Routes[callType]
+	if name == "" || name == usageProviderLlm {
+		return config.LlmProfile{}, false
+	}
+	for _, profile := range config.Conf.Llm.Profiles {
+		if profile.Name != name {
+			continue
+		}
+		if profile.BaseUrl == "" {
+			profile.BaseUrl = config.Conf.Llm.BaseUrl
+		}
+		if profile.ApiKey == "" {
+			profile.ApiKey = config.Conf.Llm.ApiKey
+		}
+		if profile.Model == "" {
+			profile.Model = config.Conf.Llm.Model
+		}
+		return profile, true
+	}
+	return config.LlmProfile{}, false
+}
+
+// llmProviderFor 用途实际使用的提供方名称和模型，用于用量统计
+func llmProviderFor(callType string) (string, string) {
+	if profile, ok := llmProfileFor(callType); ok {
+		return profile.Name, profile.Model
+	}
+	return usageProviderLlm, config.Conf.Llm.Model
+}
+
+// mergeLlmCallConfig 用提供方配置中非空的参数覆盖用途的参数
+func mergeLlmCallConfig(base, override config.LlmCallConfig) config.LlmCallConfig {
+	if override.SystemPrompt != "" {
+		base.SystemPrompt = override.SystemPrompt
+	}
+	if override.Temperature != 0 {
+		base.Temperature = override.Temperature
+	}
+	if override.MaxTokens != 0 {
+		base.MaxTokens = override.MaxTokens
+	}
+	if override.ResponseFormat != "" {
+		base.ResponseFormat = override.ResponseFormat
+	}
+	if override.Seed != 0 {
+		base.Seed = override.Seed
+	}
+	if override.ReasoningEffort != "" {
+		base.ReasoningEffort = override.ReasoningEffort
+	}
+	return base
+}
+
+// llmRouter 按请求的用途分发到对应提供方的客户端，ChatCompletion 等其他调用使用默认客户端
+type llmRouter struct {
+	types.UsageChatCompleter
+	routes map[string]types.UsageChatCompleter
+}
+
+// newLlmRouter 为路由到的每个提供方配置创建一个客户端，多个用途指向同一配置时共用客户端；没有路由时直接返回默认客户端
+func newLlmRouter(defaultCompleter types.UsageChatCompleter, newClient func(profile config.LlmProfile) types.UsageChatCompleter) types.UsageChatCompleter {
+	routes := make(map[string]types.UsageChatCompleter)
+	clients := make(map[string]types.UsageChatCompleter)
+	for callType := range config.Conf.Llm.Routes {
+		profile, ok := llmProfileFor(callType)
+		if !ok {
+			continue
+		}
+		if _, ok = clients[profile.Name]; !ok {
+			clients[profile.Name] = newClient(profile)
+		}
+		routes[callType] = clients[profile.Name]
+	}
+	if len(routes) == 0 {
+		return defaultCompleter
+	}
+	return llmRouter{UsageChatCompleter: defaultCompleter, routes: routes}
+}
+
+func (r llmRouter) completerFor(stage string) types.UsageChatCompleter {
+	if completer, ok := r.routes[stage]; ok {
+		return completer
+	}
+	return r.UsageChatCompleter
+}
+
+func (r llmRouter) Chat(ctx context.Context, req *types.ChatRequest) (string, error) {
+	return r.completerFor(req.Stage).Chat(ctx, req)
+}
+
+func (r llmRouter) ChatWithUsage(ctx context.Context, req *types.ChatRequest) (string, *types.TokenUsage, error) {
+	return r.completerFor(req.Stage).ChatWithUsage(ctx, req)
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"krillin-ai/config"
+	"krillin-ai/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLlmRouter_RoutesByCallType(t *testing.T) {
+	setupUsageTestDB(t)
+	oldLlm, oldCalls, oldUsage := config.Conf.Llm, config.Conf.LlmCalls, config.Conf.Usage
+	defer func() { config.Conf.Llm, config.Conf.LlmCalls, config.Conf.Usage = oldLlm, oldCalls, oldUsage }()
+	config.Conf.Llm = config.LlmConfig{
+		OpenaiCompatibleConfig: config.OpenaiCompatibleConfig{BaseUrl: "https://default", ApiKey: "key", Model: "gpt-4o-mini"},
+		Profiles: []config.LlmProfile{
+			{Name: "cheap", Model: "small-model"},
+			{Name: "strong", BaseUrl: "https://strong", ApiKey: "strong-key", Model: "big-model", Params: config.LlmCallConfig{Temperature: 0.2}},
+		},
+		Routes: map[string]string{llmCallSplit: "cheap", llmCallSummary: "cheap", llmCallTranslate: "strong"},
+	}
+	config.Conf.LlmCalls.Translate = config.LlmCallConfig{Temperature: 0.9, MaxTokens: 8192, ResponseFormat: types.ChatResponseFormatJsonObject}
+	config.Conf.Usage = config.UsageConfig{Enabled: true}
+
+	defaultCompleter := &stubUsageChatCompleter{stubStructuredChatCompleter: stubStructuredChatCompleter{response: "default"}}
+	created := make(map[string]config.LlmProfile)
+	completers := make(map[string]*stubUsageChatCompleter)
+	router := newLlmRouter(defaultCompleter, func(profile config.LlmProfile) types.UsageChatCompleter {
+		created[profile.Name] = profile
+		completers[profile.Name] = &stubUsageChatCompleter{stubStructuredChatCompleter: stubStructuredChatCompleter{response: profile.Name}, usage: &types.TokenUsage{PromptTokens: 10}}
+		return completers[profile.Name]
+	})
+
+	// 留空的字段沿用默认配置，同一配置只创建一个客户端
+	require.Len(t, created, 2)
+	assert.Equal(t, config.LlmProfile{Name: "cheap", BaseUrl: "https://default", ApiKey: "key", Model: "small-model"}, created["cheap"])
+	assert.Equal(t, "https://strong", created["strong"].BaseUrl)
+
+	svc := Service{ChatCompleter: router}.withUsageTracking("task_1")
+	for callType, want := range map[string]string{llmCallSplit: "cheap", llmCallSummary: "cheap", llmCallTranslate: "strong", llmCallClipper: "default"} {
+		got, err := svc.chat(context.Background(), callType, "hello")
+		require.NoError(t, err)
+		assert.Equal(t, want, got, callType)
+	}
+	require.Len(t, completers["strong"].requests, 1)
+	assert.Equal(t, float32(0.2), completers["strong"].requests[0].Temperature)
+	assert.Equal(t, 8192, completers["strong"].requests[0].MaxTokens)
+
+	provider, model := llmProviderFor(llmCallSplit)
+	assert.Equal(t, "cheap", provider)
+	assert.Equal(t, "small-model", model)
+	provider, model = llmProviderFor(llmCallClipper)
+	assert.Equal(t, "llm", provider)
+	assert.Equal(t, "gpt-4o-mini", model)
+}
+
+func TestNewLlmRouter_NoRoutesReturnsDefault(t *testing.T) {
+	oldLlm := config.Conf.Llm
+	defer func() { config.Conf.Llm = oldLlm }()
+	config.Conf.Llm.Routes = map[string]string{llmCallSplit: "llm"}
+
+	defaultCompleter := &stubUsageChatCompleter{}
+	router := newLlmRouter(defaultCompleter, func(profile config.LlmProfile) types.UsageChatCompleter {
+		t.Fatalf("unexpected client for profile %s", profile.Name)
+		return nil
+	})
+	assert.Same(t, defaultCompleter, router)
+}
@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"krillin-ai/internal/types"
+	"krillin-ai/log"
+	"krillin-ai/pkg/util"
+
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+)
+
+// 多目标语言：音频只转录一次，主目标语言走原有的分句+翻译流程，其余语言以主流程得到的原文字幕为准
+// 各自并行翻译，沿用相同的时间轴。每个其他语言在任务目录下的 lang_<语言> 子目录中独立生成字幕、配音和视频
+
+// parseTargetLanguages 解析请求中的目标语言，去重并忽略空值和 none，返回空表示只要原文字幕
+func parseTargetLanguages(languages []string) []types.StandardLanguageCode {
+	result := make([]types.StandardLanguageCode, 0, len(languages))
+	seen := make(map[types.StandardLanguageCode]bool)
+	for _, language := range languages {
+		code := types.StandardLanguageCode(strings.TrimSpace(language))
+		if code == "" || code == "none" || seen[code] {
+			continue
+		}
+		seen[code] = true
+		result = append(result, code)
+	}
+	return result
+}
+
+// joinLanguages 目标语言列表转为任务表中保存的逗号分隔形式
+func joinLanguages(languages []types.StandardLanguageCode) string {
+	if len(languages) == 0 {
+		return "none"
+	}
+	codes := make([]string, 0, len(languages))
+	for _, language := range languages {
+		codes = append(codes, string(language))
+	}
+	return strings.Join(codes, ",")
+}
+
+// outputLanguageIdentifier 嵌入字幕的视频、配音等产物对应的语言标识
+func outputLanguageIdentifier(stepParam *types.SubtitleTaskStepParam) string {
+	if stepParam.TargetLanguage == "" || stepParam.TargetLanguage == "none" {
+		return string(stepParam.OriginLanguage)
+	}
+	return string(stepParam.TargetLanguage)
+}
+
+// newLanguageStepParam 基于主任务参数生成某个其他目标语言的任务参数
+func newLanguageStepParam(stepParam *types.SubtitleTaskStepParam, language types.StandardLanguageCode) (*types.SubtitleTaskStepParam, error) {
+	languageParam := *stepParam
+	languageParam.TaskBasePath = filepath.Join(stepParam.TaskBasePath, "lang_"+string(language))
+	if err := os.MkdirAll(filepath.Join(languageParam.TaskBasePath, "output"), os.ModePerm); err != nil {
+		return nil, fmt.Errorf("newLanguageStepParam MkdirAll err: %w", err)
+	}
+	languageParam.TargetLanguage = language
+	// 术语表中其他目标语言的译法不适用
+	languageParam.GlossaryTerms = glossaryTermsForLanguage(stepParam.GlossaryTerms, language)
+	if voiceCode, ok := stepParam.TtsVoiceCodes[string(language)]; ok && voiceCode != "" {
+		languageParam.TtsVoiceCode = voiceCode
+	}
+	languageParam.BilingualSrtFilePath = ""
+	languageParam.ShortOriginMixedSrtFilePath = ""
+	languageParam.SubtitleInfos = nil
+	languageParam.TtsSourceFilePath = ""
+	languageParam.TtsResultFilePath = ""
+	languageParam.PendingMemories = &types.PendingTranslationMemories{}
+	languageParam.VideoWithTtsFilePath = ""
+	languageParam.ExtraTargetLanguages = nil
+	languageParam.LanguageStepParams = nil
+	return &languageParam, nil
+}
+
+// translateExtraLanguages 把主流程生成的原文字幕并行翻译为其他目标语言，生成各语言的字幕文件
+func (s Service) translateExtraLanguages(ctx context.Context, stepParam *types.SubtitleTaskStepParam) error {
+	if len(stepParam.ExtraTargetLanguages) == 0 {
+		return nil
+	}
+	originSrtFilePath := filepath.Join(stepParam.TaskBasePath, types.SubtitleTaskOriginLanguageSrtFileName)
+	cues, err := parseSRT(originSrtFilePath)
+	if err != nil {
+		return fmt.Errorf("translateExtraLanguages parseSRT err: %w", err)
+	}
+	sentences := make([]string, 0, len(cues))
+	for _, cue := range cues {
+		sentences = append(sentences, cue.Text)
+	}
+
+	languageParams := make([]*types.SubtitleTaskStepParam, len(stepParam.ExtraTargetLanguages))
+	eg, _ := errgroup.WithContext(ctx)
+	for i, language := range stepParam.ExtraTargetLanguages {
+		eg.Go(func() error {
+			languageParam, err := newLanguageStepParam(stepParam, language)
+			if err != nil {
+				return err
+			}
+			log.GetLogger().Info("translateExtraLanguages begin", zap.Any("taskId", stepParam.TaskId), zap.String("language", string(language)))
+			translated := s.translateSentences(ctx, languageParam, sentences, language, 0)
+			if condensationEnabled() {
+				durations := make([]float64, len(cues))
+				for j, cue := range cues {
+					durations[j] = srtBlockDuration(fmt.Sprintf("%s --> %s", cue.Start, cue.End))
+				}
+				s.condenseTranslations(ctx, translated, durations, language)
+			}
+			replaceTranslatedItems(languageParam, translated, language)
+
+			srtBlocks := make([]*util.SrtBlock, 0, len(cues))
+			for j, cue := range cues {
+				block := &util.SrtBlock{
+					Index:                  j + 1,
+					Timestamp:              fmt.Sprintf("%s --> %s", cue.Start, cue.End),
+					OriginLanguageSentence: cue.Text,
+					TargetLanguageSentence: cue.Text,
+				}
+				if j < len(translated) && translated[j] != nil && strings.TrimSpace(translated[j].TranslatedText) != "" {
+					block.TargetLanguageSentence = translated[j].SubtitleText()
+				}
+				srtBlocks = append(srtBlocks, block)
+			}
+			var qaReport *TranslationQaReport
+			if translationQaEnabled(languageParam) {
+				qaReport = s.reviewTranslations(ctx, languageParam, srtBlocks)
+			}
+			bilingualFile := filepath.Join(languageParam.TaskBasePath, types.SubtitleTaskBilingualSrtFileName)
+			if err = writeBilingualSrtFile(bilingualFile, srtBlocks, languageParam.SubtitleResultType); err != nil {
+				return fmt.Errorf("translateExtraLanguages %s %w", language, err)
+			}
+			languageParam.BilingualSrtFilePath = bilingualFile
+			if err = splitSrt(languageParam); err != nil {
+				return fmt.Errorf("translateExtraLanguages %s splitSrt err: %w", language, err)
+			}
+			if err = exportSubtitleFormats(languageParam); err != nil {
+				return fmt.Errorf("translateExtraLanguages %s exportSubtitleFormats err: %w", language, err)
+			}
+			languageParam.SubtitleInfos = languageSubtitleInfos(languageParam)
+			if qaReport != nil {
+				if err = saveTranslationQaReport(languageParam, qaReport); err != nil {
+					log.GetLogger().Error("translateExtraLanguages saveTranslationQaReport err", zap.Any("taskId", stepParam.TaskId), zap.String("language", string(language)), zap.Error(err))
+				}
+			}
+			saveTranslationMemory(languageParam, qaReport)
+			languageParams[i] = languageParam
+			log.GetLogger().Info("translateExtraLanguages end", zap.Any("taskId", stepParam.TaskId), zap.String("language", string(language)))
+			return nil
+		})
+	}
+	if err = eg.Wait(); err != nil {
+		return err
+	}
+
+	stepParam.LanguageStepParams = languageParams
+	for _, languageParam := range languageParams {
+		stepParam.SubtitleInfos = append(stepParam.SubtitleInfos, languageParam.SubtitleInfos...)
+	}
+	return nil
+}
+
+// languageSubtitleInfos 只保留某个其他目标语言自己的文件，原文字幕与主语言重复，不再返回
+func languageSubtitleInfos(languageParam *types.SubtitleTaskStepParam) []types.SubtitleFileInfo {
+	language := string(languageParam.TargetLanguage)
+	infos := make([]types.SubtitleFileInfo, 0, len(languageParam.SubtitleInfos))
+	for _, info := range languageParam.SubtitleInfos {
+		switch info.LanguageIdentifier {
+		case language:
+		case "bilingual":
+			info.LanguageIdentifier = language
+			info.Name = fmt.Sprintf("%s (%s)", info.Name, types.GetStandardLanguageName(languageParam.TargetLanguage))
+		default:
+			continue
+		}
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+// generateExtraLanguageMedia 依次为其他目标语言生成配音和嵌入字幕的视频
+func (s Service) generateExtraLanguageMedia(ctx context.Context, stepParam *types.SubtitleTaskStepParam) error {
+	for _, languageParam := range stepParam.LanguageStepParams {
+		language := string(languageParam.TargetLanguage)
+		if err := s.srtFileToSpeech(ctx, languageParam); err != nil {
+			return fmt.Errorf("generateExtraLanguageMedia %s srtFileToSpeech err: %w", language, err)
+		}
+		if languageParam.TtsResultFilePath != "" {
+			stepParam.SubtitleInfos = append(stepParam.SubtitleInfos, types.SubtitleFileInfo{
+				Name:               fmt.Sprintf("%s_%s", language, types.TtsResultAudioFileName),
+				Path:               languageParam.TtsResultFilePath,
+				LanguageIdentifier: language,
+			})
+		}
+
+		embedStart := len(languageParam.SubtitleInfos)
+		if err := s.embedSubtitles(ctx, languageParam); err != nil {
+			return fmt.Errorf("generateExtraLanguageMedia %s embedSubtitles err: %w", language, err)
+		}
+		for _, info := range languageParam.SubtitleInfos[embedStart:] {
+			info.Name = fmt.Sprintf("%s_%s", language, info.Name)
+			stepParam.SubtitleInfos = append(stepParam.SubtitleInfos, info)
+		}
+	}
+	return nil
+}
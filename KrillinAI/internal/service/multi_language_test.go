@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"krillin-ai/config"
+	"krillin-ai/internal/dto"
+	"krillin-ai/internal/mocks"
+	"krillin-ai/internal/storage"
+	"krillin-ai/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLanguageList_AcceptsStringOrArray(t *testing.T) {
+	var req dto.StartVideoSubtitleTaskReq
+	require.NoError(t, json.Unmarshal([]byte(`{"target_lang":"zh_cn"}`), &req))
+	assert.Equal(t, dto.LanguageList{"zh_cn"}, req.TargetLang)
+
+	require.NoError(t, json.Unmarshal([]byte(`{"target_lang":"zh_cn, ja"}`), &req))
+	assert.Equal(t, dto.LanguageList{"zh_cn", "ja"}, req.TargetLang)
+
+	require.NoError(t, json.Unmarshal([]byte(`{"target_lang":["zh_cn","ja","zh_cn","none"]}`), &req))
+	assert.Equal(t, []types.StandardLanguageCode{"zh_cn", "ja"}, parseTargetLanguages(req.TargetLang))
+	assert.Equal(t, "zh_cn,ja", joinLanguages(parseTargetLanguages(req.TargetLang)))
+	assert.Equal(t, "none", joinLanguages(parseTargetLanguages(dto.LanguageList{"none"})))
+}
+
+func TestGetVideoInfo_KeepsAllTargetLanguages(t *testing.T) {
+	oldYtdlpPath := storage.YtdlpPath
+	storage.YtdlpPath = filepath.Join(t.TempDir(), "missing-yt-dlp")
+	defer func() { storage.YtdlpPath = oldYtdlpPath }()
+
+	taskBasePath := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(taskBasePath, "output"), os.ModePerm))
+	mockChatCompleter := new(mocks.MockChatCompleter)
+	mockChatCompleter.On("ChatCompletion", mock.Anything).Return("标题####简介", nil)
+	svc := Service{ChatCompleter: mockChatCompleter}
+	taskPtr := &types.SubtitleTask{TaskId: "task_1", TargetLanguage: "zh_cn,ja"}
+	stepParam := &types.SubtitleTaskStepParam{
+		TaskId:               "task_1",
+		TaskPtr:              taskPtr,
+		TaskBasePath:         taskBasePath,
+		Link:                 "https://www.youtube.com/watch?v=test",
+		OriginLanguage:       types.LanguageNameEnglish,
+		TargetLanguage:       types.LanguageNameSimplifiedChinese,
+		ExtraTargetLanguages: []types.StandardLanguageCode{types.LanguageNameJapanese},
+	}
+
+	require.NoError(t, svc.getVideoInfo(context.Background(), stepParam))
+
+	assert.Equal(t, "zh_cn,ja", taskPtr.TargetLanguage)
+	assert.Equal(t, "标题", taskPtr.TranslatedTitle)
+}
+
+func TestTranslateExtraLanguages_WritesPerLanguageSubtitles(t *testing.T) {
+	oldMemory := config.Conf.TranslationMemory.Enabled
+	config.Conf.TranslationMemory.Enabled = false
+	defer func() { config.Conf.TranslationMemory.Enabled = oldMemory }()
+
+	taskBasePath := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(taskBasePath, "output"), os.ModePerm))
+	originSrt := "1\n00:00:00,000 --> 00:00:01,500\nHello there.\n\n2\n00:00:02,000 --> 00:00:03,000\nGoodbye.\n\n"
+	require.NoError(t, os.WriteFile(filepath.Join(taskBasePath, types.SubtitleTaskOriginLanguageSrtFileName), []byte(originSrt), 0644))
+
+	mockChatCompleter := new(mocks.MockChatCompleter)
+	mockChatCompleter.On("ChatCompletion", mock.MatchedBy(func(prompt string) bool {
+		return strings.Contains(prompt, "Translate a list of subtitles into 日本語")
+	})).Return(`["こんにちは。","さようなら。"]`, nil).Once()
+	mockChatCompleter.On("ChatCompletion", mock.MatchedBy(func(prompt string) bool {
+		return strings.Contains(prompt, "Translate a list of subtitles into Deutsch")
+	})).Return(`["Hallo.","Tschüss."]`, nil).Once()
+	svc := Service{ChatCompleter: mockChatCompleter}
+	stepParam := &types.SubtitleTaskStepParam{
+		TaskId:               "task_1",
+		TaskBasePath:         taskBasePath,
+		OriginLanguage:       types.LanguageNameEnglish,
+		TargetLanguage:       types.LanguageNameSimplifiedChinese,
+		UserUILanguage:       types.LanguageNameEnglish,
+		SubtitleResultType:   types.SubtitleResultTypeBilingualTranslationOnBottom,
+		ExtraTargetLanguages: []types.StandardLanguageCode{types.LanguageNameJapanese, types.LanguageNameGerman},
+		TtsVoiceCodes:        map[string]string{"ja": "ja_voice"},
+		TtsVoiceCode:         "zh_voice",
+	}
+
+	require.NoError(t, svc.translateExtraLanguages(t.Context(), stepParam))
+	mockChatCompleter.AssertExpectations(t)
+
+	require.Len(t, stepParam.LanguageStepParams, 2)
+	jaParam := stepParam.LanguageStepParams[0]
+	assert.Equal(t, "ja_voice", jaParam.TtsVoiceCode)
+	assert.Equal(t, "zh_voice", stepParam.LanguageStepParams[1].TtsVoiceCode)
+	jaTarget, err := os.ReadFile(filepath.Join(taskBasePath, "lang_ja", types.SubtitleTaskTargetLanguageSrtFileName))
+	require.NoError(t, err)
+	assert.Equal(t, "1\n00:00:00,000 --> 00:00:01,500\nこんにちは。\n\n2\n00:00:02,000 --> 00:00:03,000\nさようなら。\n\n", string(jaTarget))
+	assert.Equal(t, filepath.Join(taskBasePath, "lang_ja", types.SubtitleTaskTargetLanguageSrtFileName), jaParam.TtsSourceFilePath)
+
+	for _, info := range stepParam.SubtitleInfos {
+		assert.Contains(t, []string{"ja", "de"}, info.LanguageIdentifier)
+		assert.NotEqual(t, filepath.Join(jaParam.TaskBasePath, types.SubtitleTaskOriginLanguageSrtFileName), info.Path)
+	}
+	names := make([]string, 0, len(stepParam.SubtitleInfos))
+	for _, info := range stepParam.SubtitleInfos {
+		names = append(names, info.Name)
+	}
+	assert.Contains(t, names, "Bilingual Subtitle (日本語)")
+}
+
+func TestNewLanguageStepParam_ScopesGlossaryTerms(t *testing.T) {
+	stepParam := &types.SubtitleTaskStepParam{
+		TaskBasePath:   t.TempDir(),
+		TargetLanguage: types.LanguageNameSimplifiedChinese,
+		GlossaryTerms: []types.GlossaryTerm{
+			{Source: "GPU", Target: "图形处理器", TargetLanguage: "zh_cn"},
+			{Source: "GPU", Target: "グラフィックスカード", TargetLanguage: "ja"},
+			{Source: "Kubernetes", DoNotTranslate: true},
+			{Source: "cluster", Target: "集群", TargetLanguage: "zh_cn"},
+		},
+	}
+
+	jaParam, err := newLanguageStepParam(stepParam, types.LanguageNameJapanese)
+	require.NoError(t, err)
+
+	assert.Equal(t, []types.GlossaryTerm{
+		{Source: "GPU", Target: "グラフィックスカード", TargetLanguage: "ja"},
+		{Source: "Kubernetes", DoNotTranslate: true},
+	}, jaParam.GlossaryTerms)
+	assert.Len(t, stepParam.GlossaryTerms, 4)
+}
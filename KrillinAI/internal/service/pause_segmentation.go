@@ -0,0 +1,128 @@
+package service
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"krillin-ai/config"
+	"krillin-ai/internal/types"
+	"krillin-ai/pkg/util"
+)
+
+// 基于词级时间戳的分句：按句末标点、词间停顿以及每句的时长/字数上限切分字幕单元，
+// 切出来的句子天然贴合说话停顿，也能大幅减少大模型拆长句的调用
+
+const segmentModePause = "pause"
+
+const (
+	segmentPauseGap     = 0.5 // 词间停顿超过该秒数即切分
+	segmentMinChars     = 6   // 因停顿切分时，一句至少包含的有效字符数，避免切出碎片
+	segmentClauseBonus  = 0.3 // 超出上限回退找切分点时，逗号等子句标点处额外加分
+	sentenceEndPunction = ".!?。！？…"
+	clausePunction      = ",;:，、；："
+)
+
+// segmentWord 已在原文中定位到的词，end 为词在原文中的结束字节位置
+type segmentWord struct {
+	word types.Word
+	end  int
+}
+
+// pauseSegmentEnabled 是否使用基于停顿的分句
+func pauseSegmentEnabled() bool {
+	return config.Conf.App.SegmentMode == segmentModePause
+}
+
+// segmentTextByPause 根据词级时间戳把文本切成字幕单元，切分只发生在词边界上，原文的标点和空格保持不变；
+// 词无法在原文中定位时回退到按标点分句
+func segmentTextByPause(text string, words []types.Word, language types.StandardLanguageCode) []string {
+	located := locateSegmentWords(text, words, !isUnspacedLanguage(language))
+	if len(located) == 0 {
+		return util.SplitTextSentences(text, config.Conf.App.MaxSentenceLength)
+	}
+
+	maxChars := config.Conf.App.MaxSentenceLength
+	maxDuration := config.Conf.App.MaxSentenceDuration
+	sentences := make([]string, 0)
+	unitStart, first := 0, 0
+	for i := 0; i < len(located)-1; i++ {
+		cutEnd := skipTrailingPunction(text, located[i].end)
+		chars := util.CountEffectiveChars(text[unitStart:cutEnd])
+		gap := located[i+1].word.Start - located[i].word.End
+
+		cut := false
+		if strings.ContainsAny(text[located[i].end:cutEnd], sentenceEndPunction) {
+			cut = true
+		} else if gap >= segmentPauseGap && chars >= segmentMinChars {
+			cut = true
+		} else {
+			// 再加一个词就超出字数或时长上限时，回退到当前单元内最合适的位置切分
+			nextChars := util.CountEffectiveChars(text[unitStart:skipTrailingPunction(text, located[i+1].end)])
+			nextDuration := located[i+1].word.End - located[first].word.Start
+			if nextChars > maxChars || (maxDuration > 0 && nextDuration > maxDuration) {
+				i = bestSegmentBreak(text, located, unitStart, first, i)
+				cutEnd = skipTrailingPunction(text, located[i].end)
+				cut = true
+			}
+		}
+		if !cut {
+			continue
+		}
+		if sentence := strings.TrimSpace(text[unitStart:cutEnd]); sentence != "" {
+			sentences = append(sentences, sentence)
+		}
+		unitStart, first = cutEnd, i+1
+	}
+	if sentence := strings.TrimSpace(text[unitStart:]); sentence != "" {
+		sentences = append(sentences, sentence)
+	}
+	return sentences
+}
+
+// locateSegmentWords 按顺序在原文中定位每个词，定位不到的词跳过
+func locateSegmentWords(text string, words []types.Word, wholeWord bool) []segmentWord {
+	located := make([]segmentWord, 0, len(words))
+	cursor := 0
+	for _, word := range words {
+		end := findWordEnd(text, cursor, word.Text, wholeWord)
+		if end < 0 {
+			continue
+		}
+		located = append(located, segmentWord{word: word, end: end})
+		cursor = end
+	}
+	return located
+}
+
+// bestSegmentBreak 在 [first, last] 之间挑选停顿最长（子句标点处加分）的词作为句尾，
+// 切出的前半句太短的位置不考虑，都不满足时直接在 last 处切分
+func bestSegmentBreak(text string, located []segmentWord, unitStart, first, last int) int {
+	best, bestScore := last, -1.0
+	for j := first; j <= last; j++ {
+		cutEnd := skipTrailingPunction(text, located[j].end)
+		if util.CountEffectiveChars(text[unitStart:cutEnd]) < segmentMinChars {
+			continue
+		}
+		score := located[j+1].word.Start - located[j].word.End
+		if strings.ContainsAny(text[located[j].end:cutEnd], clausePunction) {
+			score += segmentClauseBonus
+		}
+		if score > bestScore {
+			best, bestScore = j, score
+		}
+	}
+	return best
+}
+
+// skipTrailingPunction 跳过紧跟在词后面的标点，返回切分位置
+func skipTrailingPunction(text string, pos int) int {
+	for pos < len(text) {
+		r, size := utf8.DecodeRuneInString(text[pos:])
+		if !unicode.IsPunct(r) {
+			break
+		}
+		pos += size
+	}
+	return pos
+}
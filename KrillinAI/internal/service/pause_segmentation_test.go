@@ -0,0 +1,57 @@
+package service
+
+import (
+	"testing"
+
+	"krillin-ai/config"
+	"krillin-ai/internal/types"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func buildTimedWords(texts []string, gaps []float64) []types.Word {
+	words := make([]types.Word, 0, len(texts))
+	cursor := 0.0
+	for i, text := range texts {
+		words = append(words, types.Word{Num: i, Text: text, Start: cursor, End: cursor + 0.3})
+		cursor += 0.3
+		if i < len(gaps) {
+			cursor += gaps[i]
+		}
+	}
+	return words
+}
+
+func TestSegmentTextByPause_PunctuationAndPause(t *testing.T) {
+	text := "Hello there. So today we are going to talk about something new"
+	words := buildTimedWords(
+		[]string{"Hello", "there", "So", "today", "we", "are", "going", "to", "talk", "about", "something", "new"},
+		[]float64{0.05, 0.1, 0.05, 0.05, 0.05, 0.8, 0.05, 0.05, 0.05, 0.05, 0.05},
+	)
+
+	sentences := segmentTextByPause(text, words, types.LanguageNameEnglish)
+
+	assert.Equal(t, []string{"Hello there.", "So today we are", "going to talk about something new"}, sentences)
+}
+
+func TestSegmentTextByPause_BudgetPrefersComma(t *testing.T) {
+	original := config.Conf.App.MaxSentenceDuration
+	config.Conf.App.MaxSentenceDuration = 2
+	defer func() { config.Conf.App.MaxSentenceDuration = original }()
+
+	text := "one two three, four five six seven"
+	words := buildTimedWords([]string{"one", "two", "three", "four", "five", "six", "seven"}, []float64{0.05, 0.05, 0.1, 0.05, 0.05, 0.05})
+
+	sentences := segmentTextByPause(text, words, types.LanguageNameEnglish)
+
+	assert.Equal(t, []string{"one two three,", "four five six seven"}, sentences)
+}
+
+func TestSegmentTextByPause_Chinese(t *testing.T) {
+	text := "大家好今天我们来聊一聊天气"
+	words := buildTimedWords([]string{"大家", "好", "今天", "我们", "来", "聊一聊", "天气"}, []float64{0.05, 0.05, 0.05, 0.7, 0.05, 0.05})
+
+	sentences := segmentTextByPause(text, words, types.LanguageNameSimplifiedChinese)
+
+	assert.Equal(t, []string{"大家好今天我们", "来聊一聊天气"}, sentences)
+}
@@ -0,0 +1,453 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"krillin-ai/config"
+	"krillin-ai/internal/dto"
+	"krillin-ai/internal/storage"
+	"krillin-ai/internal/types"
+	"krillin-ai/log"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// 提示词模板：流程中每处大模型调用对应一种模板类型，变量由类型对应的结构体决定，
+// 内置模板由 types 中原有的 printf 格式提示词按变量顺序转换而来。
+// 任务按 请求指定 > 配置默认 > 内置 的顺序选用模板，模板出错时回退到内置模板
+
+const (
+	promptKindBatchTranslate          = "batch_translate"
+	promptKindSplitLongSentence       = "split_long_sentence"
+	promptKindSplitOriginLongSentence = "split_origin_long_sentence"
+	promptKindSplitLongTextByMeaning  = "split_long_text_by_meaning"
+	promptKindSummaryAndTitle         = "summary_and_title"
+	promptKindSummaryTranscript       = "summary_transcript"
+	promptKindRestorePunctuation      = "restore_punctuation"
+	promptKindRunningSummary          = "running_summary"
+	promptKindTranslationQaJudge      = "translation_qa_judge"
+	promptKindSmartClipper            = "smart_clipper"
+	promptKindCondenseTranslation     = "condense_translation"
+)
+
+// 各模板类型的变量，字段顺序与原 printf 提示词中占位符的顺序一致
+type batchTranslatePromptVars struct {
+	TargetLanguage string `prompt:"目标语言名称 Target language name" required:"true"`
+	Instructions   string `prompt:"附加要求（术语表、上下文、翻译记忆、输出格式等），可能为空 Extra requirements such as glossary, context, memory and output format, may be empty" required:"true"`
+	Input          string `prompt:"待翻译字幕的 JSON 数组 JSON array of subtitle lines to translate" required:"true"`
+}
+
+type splitLongSentencePromptVars struct {
+	OriginText     string `prompt:"原文 Original text" required:"true"`
+	TranslatedText string `prompt:"译文 Translated text" required:"true"`
+}
+
+type sentencePromptVars struct {
+	Sentence string `prompt:"待拆分的原文 Text to split" required:"true"`
+}
+
+type summaryPromptVars struct {
+	Content string `prompt:"视频标题和描述（以####分隔）或字幕文本 Video title and description separated by ####, or transcript text" required:"true"`
+}
+
+type restorePunctuationPromptVars struct {
+	Language string `prompt:"语言名称 Language name"`
+	Text     string `prompt:"缺少标点的识别文本 Transcription without punctuation" required:"true"`
+}
+
+type runningSummaryPromptVars struct {
+	Summary string `prompt:"当前摘要，首次为 (empty) Current summary, (empty) at first" required:"true"`
+	Lines   string `prompt:"新字幕的 JSON 数组 JSON array of new subtitle lines" required:"true"`
+}
+
+type translationQaJudgePromptVars struct {
+	SourceLanguage string `prompt:"源语言名称 Source language name"`
+	TargetLanguage string `prompt:"目标语言名称 Target language name"`
+	Input          string `prompt:"待评审条目的 JSON 数组 JSON array of items to review" required:"true"`
+}
+
+type smartClipperPromptVars struct {
+	MinClipDuration int    `prompt:"片段最短秒数 Minimum clip duration in seconds"`
+	MaxClipDuration int    `prompt:"片段最长秒数 Maximum clip duration in seconds"`
+	Transcript      string `prompt:"视频字幕文本 Video transcript" required:"true"`
+}
+
+type condenseTranslationPromptVars struct {
+	Language string `prompt:"译文语言名称 Language name of the lines"`
+	Input    string `prompt:"待精简条目的 JSON 数组 JSON array of lines to condense" required:"true"`
+}
+
+type promptKind struct {
+	description string
+	printf      string // 原 printf 格式的提示词，用于生成内置模板
+	sample      any    // 变量示例值，用于校验和试渲染
+}
+
+var promptKindOrder = []string{
+	promptKindBatchTranslate,
+	promptKindSplitLongSentence,
+	promptKindSplitOriginLongSentence,
+	promptKindSplitLongTextByMeaning,
+	promptKindSummaryAndTitle,
+	promptKindSummaryTranscript,
+	promptKindRestorePunctuation,
+	promptKindRunningSummary,
+	promptKindTranslationQaJudge,
+	promptKindSmartClipper,
+	promptKindCondenseTranslation,
+}
+
+var promptKinds = map[string]promptKind{
+	promptKindBatchTranslate: {
+		description: "批量翻译字幕 Batch subtitle translation",
+		printf:      types.BatchTranslatePrompt,
+		sample:      batchTranslatePromptVars{TargetLanguage: "简体中文", Input: `["Hello world", "This is a test"]`},
+	},
+	promptKindSplitLongSentence: {
+		description: "拆分过长的原文和译文 Split long bilingual sentence",
+		printf:      types.SplitLongSentencePrompt,
+		sample:      splitLongSentencePromptVars{OriginText: "This is a long sentence, and it needs to be split.", TranslatedText: "这是一个很长的句子，需要拆分。"},
+	},
+	promptKindSplitOriginLongSentence: {
+		description: "拆分过长的原文 Split long original sentence",
+		printf:      types.SplitOriginLongSentencePrompt,
+		sample:      sentencePromptVars{Sentence: "This is a long sentence, and it needs to be split."},
+	},
+	promptKindSplitLongTextByMeaning: {
+		description: "按语义拆分没有标点的长文本 Split long text without punctuation by meaning",
+		printf:      types.SplitLongTextByMeaningPrompt,
+		sample:      sentencePromptVars{Sentence: "this is a long sentence without punctuation it needs to be split"},
+	},
+	promptKindSummaryAndTitle: {
+		description: "根据标题和描述生成视频简介 Summarize video from title and description",
+		printf:      types.SummaryAndTitlePrompt,
+		sample:      summaryPromptVars{Content: "Video title####Video description"},
+	},
+	promptKindSummaryTranscript: {
+		description: "根据字幕生成视频简介 Summarize video from transcript",
+		printf:      types.SummaryTranscriptPrompt,
+		sample:      summaryPromptVars{Content: "Hello everyone, welcome to this video."},
+	},
+	promptKindRestorePunctuation: {
+		description: "恢复识别文本的标点 Restore punctuation",
+		printf:      types.RestorePunctuationPrompt,
+		sample:      restorePunctuationPromptVars{Language: "English", Text: "hello everyone welcome to this video"},
+	},
+	promptKindRunningSummary: {
+		description: "翻译时更新内容摘要 Update running summary during translation",
+		printf:      types.RunningSummaryPrompt,
+		sample:      runningSummaryPromptVars{Summary: "(empty)", Lines: `["Hello everyone"]`},
+	},
+	promptKindTranslationQaJudge: {
+		description: "翻译质检打分 Translation quality review",
+		printf:      types.TranslationQaJudgePrompt,
+		sample:      translationQaJudgePromptVars{SourceLanguage: "English", TargetLanguage: "简体中文", Input: `[{"id":1,"source":"Hello","translation":"你好"}]`},
+	},
+	promptKindSmartClipper: {
+		description: "智能切片分析 Smart clipper analysis",
+		printf:      types.SmartClipperPrompt,
+		sample:      smartClipperPromptVars{MinClipDuration: 60, MaxClipDuration: 180, Transcript: "Hello everyone, welcome to this video."},
+	},
+	promptKindCondenseTranslation: {
+		description: "精简超出阅读速度的译文 Condense translations that exceed reading speed",
+		printf:      types.CondenseTranslationPrompt,
+		sample:      condenseTranslationPromptVars{Language: "简体中文", Input: `[{"id":1,"text":"这是一句在屏幕上停留时间很短但内容很长的字幕","max_chars":12}]`},
+	},
+}
+
+// builtinPromptTemplates 内置模板，启动时由 printf 提示词转换生成
+var builtinPromptTemplates = func() map[string]string {
+	templates := make(map[string]string, len(promptKinds))
+	for name, kind := range promptKinds {
+		content, err := printfToTemplate(kind.printf, promptVariableNames(kind.sample))
+		if err != nil {
+			panic(fmt.Sprintf("builtin prompt %s: %v", name, err))
+		}
+		templates[name] = content
+	}
+	return templates
+}()
+
+// printfToTemplate 把 printf 格式的提示词按顺序替换为模板变量，占位符数量必须与变量数量一致
+func printfToTemplate(format string, names []string) (string, error) {
+	var builder strings.Builder
+	next := 0
+	for i := 0; i < len(format); i++ {
+		if strings.HasPrefix(format[i:], "{{") {
+			builder.WriteString(`{{"{{"}}`)
+			i++
+			continue
+		}
+		if format[i] != '%' {
+			builder.WriteByte(format[i])
+			continue
+		}
+		if i+1 >= len(format) {
+			return "", errors.New("dangling % at end of prompt")
+		}
+		i++
+		switch format[i] {
+		case '%':
+			builder.WriteByte('%')
+		case 's', 'd', 'v':
+			if next >= len(names) {
+				return "", fmt.Errorf("prompt has more placeholders than the %d variables", len(names))
+			}
+			builder.WriteString("{{." + names[next] + "}}")
+			next++
+		default:
+			return "", fmt.Errorf("unsupported placeholder %%%c", format[i])
+		}
+	}
+	if next != len(names) {
+		return "", fmt.Errorf("prompt has %d placeholders, expected %d", next, len(names))
+	}
+	return builder.String(), nil
+}
+
+func promptVariableNames(sample any) []string {
+	t := reflect.TypeOf(sample)
+	names := make([]string, 0, t.NumField())
+	for i := range t.NumField() {
+		names = append(names, t.Field(i).Name)
+	}
+	return names
+}
+
+func promptVariables(sample any) []dto.PromptVariable {
+	t := reflect.TypeOf(sample)
+	variables := make([]dto.PromptVariable, 0, t.NumField())
+	for i := range t.NumField() {
+		field := t.Field(i)
+		variables = append(variables, dto.PromptVariable{
+			Name:        field.Name,
+			Type:        field.Type.Kind().String(),
+			Description: field.Tag.Get("prompt"),
+			Required:    field.Tag.Get("required") == "true",
+		})
+	}
+	return variables
+}
+
+func renderPromptTemplate(content string, vars any) (string, error) {
+	tmpl, err := template.New("prompt").Option("missingkey=error").Parse(content)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err = tmpl.Execute(&buf, vars); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// validatePromptTemplate 校验模板语法、引用的变量是否存在，以及必填变量是否都被引用
+func validatePromptTemplate(kind, content string) error {
+	promptKind, ok := promptKinds[kind]
+	if !ok {
+		return fmt.Errorf("unknown prompt kind %q", kind)
+	}
+	if strings.TrimSpace(content) == "" {
+		return errors.New("prompt template content is empty")
+	}
+	// 必填的字符串变量替换为标记值，渲染结果中缺少标记说明模板没有引用该变量
+	value := reflect.New(reflect.TypeOf(promptKind.sample)).Elem()
+	value.Set(reflect.ValueOf(promptKind.sample))
+	markers := make(map[string]string)
+	for i := range value.NumField() {
+		field := value.Type().Field(i)
+		if field.Tag.Get("required") == "true" && field.Type.Kind() == reflect.String {
+			markers[field.Name] = "__PROMPT_VAR_" + field.Name + "__"
+			value.Field(i).SetString(markers[field.Name])
+		}
+	}
+	rendered, err := renderPromptTemplate(content, value.Interface())
+	if err != nil {
+		return err
+	}
+	for _, variable := range promptVariables(promptKind.sample) {
+		if marker, ok := markers[variable.Name]; ok && !strings.Contains(rendered, marker) {
+			return fmt.Errorf("required variable {{.%s}} is not used", variable.Name)
+		}
+	}
+	return nil
+}
+
+// parsePromptSelector 解析 name 或 name@version
+func parsePromptSelector(selector string) (string, int, error) {
+	name, versionText, found := strings.Cut(strings.TrimSpace(selector), "@")
+	if !found {
+		return name, 0, nil
+	}
+	version, err := strconv.Atoi(versionText)
+	if err != nil || version <= 0 {
+		return "", 0, fmt.Errorf("invalid prompt template version %q", selector)
+	}
+	return name, version, nil
+}
+
+// findPromptTemplate 按名称查找模板内容，配置文件中的模板优先，并校验类型和内容
+func findPromptTemplate(kind, selector string) (string, error) {
+	name, version, err := parsePromptSelector(selector)
+	if err != nil {
+		return "", err
+	}
+	content, templateKind := "", ""
+	for _, tmpl := range config.Conf.Prompt.Templates {
+		if tmpl.Name == name {
+			if version > 0 {
+				return "", fmt.Errorf("prompt template %q is defined in config and has no versions", name)
+			}
+			content, templateKind = tmpl.Content, tmpl.Kind
+			break
+		}
+	}
+	if templateKind == "" {
+		tmpl, err := storage.GetPromptTemplateByName(name)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return "", fmt.Errorf("prompt template %q not found", name)
+			}
+			return "", err
+		}
+		content, templateKind = tmpl.Content, tmpl.Kind
+		if version > 0 && version != tmpl.Version {
+			v, err := storage.GetPromptTemplateVersion(tmpl.Id, version)
+			if err != nil {
+				return "", fmt.Errorf("prompt template %q version %d not found: %w", name, version, err)
+			}
+			content = v.Content
+		}
+	}
+	if templateKind != kind {
+		return "", fmt.Errorf("prompt template %q is of kind %q, not %q", name, templateKind, kind)
+	}
+	if err = validatePromptTemplate(kind, content); err != nil {
+		return "", fmt.Errorf("prompt template %q is invalid: %w", selector, err)
+	}
+	return content, nil
+}
+
+// resolvePromptTemplates 解析任务选用的模板，请求未指定的类型使用配置的默认模板，都没有时不放入结果
+func resolvePromptTemplates(selection map[string]string) (map[string]string, error) {
+	for kind := range selection {
+		if _, ok := promptKinds[kind]; !ok {
+			return nil, fmt.Errorf("unknown prompt kind %q", kind)
+		}
+	}
+	templates := make(map[string]string)
+	for _, kind := range promptKindOrder {
+		selector := selection[kind]
+		if selector == "" {
+			selector = config.Conf.Prompt.Defaults[kind]
+		}
+		if selector == "" {
+			continue
+		}
+		content, err := findPromptTemplate(kind, selector)
+		if err != nil {
+			return nil, err
+		}
+		templates[kind] = content
+	}
+	return templates, nil
+}
+
+// defaultPromptTemplate 没有按任务选用模板时的模板：配置默认 > 智能切片旧版 printf 配置 > 内置
+func defaultPromptTemplate(kind string) string {
+	if selector := config.Conf.Prompt.Defaults[kind]; selector != "" {
+		content, err := findPromptTemplate(kind, selector)
+		if err == nil {
+			return content
+		}
+		log.GetLogger().Warn("defaultPromptTemplate fallback to builtin", zap.String("kind", kind), zap.Error(err))
+	}
+	if kind == promptKindSmartClipper && config.Conf.SmartClipper.Prompt != "" {
+		content, err := printfToTemplate(config.Conf.SmartClipper.Prompt, promptVariableNames(promptKinds[kind].sample))
+		if err == nil {
+			return content
+		}
+		log.GetLogger().Warn("smart_clipper.prompt is invalid, fallback to builtin", zap.Error(err))
+	}
+	return builtinPromptTemplates[kind]
+}
+
+// renderPrompt 按选用的模板渲染提示词，渲染失败时回退到内置模板
+func renderPrompt(templates map[string]string, kind string, vars any) string {
+	content, ok := templates[kind]
+	if !ok {
+		content = defaultPromptTemplate(kind)
+	}
+	prompt, err := renderPromptTemplate(content, vars)
+	if err == nil {
+		return prompt
+	}
+	log.GetLogger().Warn("renderPrompt failed, fallback to builtin", zap.String("kind", kind), zap.Error(err))
+	prompt, _ = renderPromptTemplate(builtinPromptTemplates[kind], vars)
+	return prompt
+}
+
+func (s Service) renderPrompt(kind string, vars any) string {
+	return renderPrompt(s.PromptTemplates, kind, vars)
+}
+
+// PromptKinds 所有模板类型及其变量和内置模板
+func (s Service) PromptKinds() []dto.PromptKind {
+	kinds := make([]dto.PromptKind, 0, len(promptKindOrder))
+	for _, name := range promptKindOrder {
+		kind := promptKinds[name]
+		kinds = append(kinds, dto.PromptKind{
+			Kind:        name,
+			Description: kind.description,
+			Variables:   promptVariables(kind.sample),
+			Builtin:     builtinPromptTemplates[name],
+		})
+	}
+	return kinds
+}
+
+// ValidatePromptTemplate 校验模板，供保存前调用
+func (s Service) ValidatePromptTemplate(kind, content string) error {
+	return validatePromptTemplate(kind, content)
+}
+
+// RenderPromptTemplate 校验模板后用示例值（可被请求中的变量覆盖）试渲染，内容为空时渲染内置模板
+func (s Service) RenderPromptTemplate(req dto.RenderPromptTemplateReq) (*dto.RenderPromptTemplateResData, error) {
+	kind, ok := promptKinds[req.Kind]
+	if !ok {
+		return nil, fmt.Errorf("unknown prompt kind %q", req.Kind)
+	}
+	content := req.Content
+	if content == "" {
+		content = builtinPromptTemplates[req.Kind]
+	}
+	if err := validatePromptTemplate(req.Kind, content); err != nil {
+		return nil, err
+	}
+	vars := reflect.New(reflect.TypeOf(kind.sample))
+	vars.Elem().Set(reflect.ValueOf(kind.sample))
+	if len(req.Variables) > 0 {
+		data, err := json.Marshal(req.Variables)
+		if err != nil {
+			return nil, err
+		}
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err = decoder.Decode(vars.Interface()); err != nil {
+			return nil, fmt.Errorf("invalid variables: %w", err)
+		}
+	}
+	prompt, err := renderPromptTemplate(content, vars.Elem().Interface())
+	if err != nil {
+		return nil, err
+	}
+	return &dto.RenderPromptTemplateResData{Prompt: prompt}, nil
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"krillin-ai/config"
+	"krillin-ai/internal/dto"
+	"krillin-ai/internal/storage"
+	"krillin-ai/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuiltinPromptTemplates_RenderSameAsPrintf(t *testing.T) {
+	for name, kind := range promptKinds {
+		value := reflect.ValueOf(kind.sample)
+		args := make([]any, 0, value.NumField())
+		for i := range value.NumField() {
+			args = append(args, value.Field(i).Interface())
+		}
+		rendered, err := renderPromptTemplate(builtinPromptTemplates[name], kind.sample)
+		require.NoError(t, err, name)
+		assert.Equal(t, fmt.Sprintf(kind.printf, args...), rendered, name)
+		assert.NoError(t, validatePromptTemplate(name, builtinPromptTemplates[name]), name)
+	}
+}
+
+func TestPrintfToTemplate(t *testing.T) {
+	content, err := printfToTemplate("Translate into %s, 100%% {{literal}}:\n%s", []string{"TargetLanguage", "Input"})
+	require.NoError(t, err)
+	rendered, err := renderPromptTemplate(content, batchTranslatePromptVars{TargetLanguage: "English", Input: "[]"})
+	require.NoError(t, err)
+	assert.Equal(t, "Translate into English, 100% {{literal}}:\n[]", rendered)
+
+	_, err = printfToTemplate("%d %s", []string{"MinClipDuration", "MaxClipDuration", "Transcript"})
+	assert.Error(t, err)
+}
+
+func TestValidatePromptTemplate(t *testing.T) {
+	assert.NoError(t, validatePromptTemplate(promptKindSplitOriginLongSentence, "Split: {{.Sentence}}"))
+	assert.ErrorContains(t, validatePromptTemplate(promptKindSplitOriginLongSentence, "Split: {{.Text}}"), "can't evaluate field Text")
+	assert.ErrorContains(t, validatePromptTemplate(promptKindSplitOriginLongSentence, "Split this"), "{{.Sentence}}")
+	assert.Error(t, validatePromptTemplate(promptKindSplitOriginLongSentence, "Split: {{.Sentence"))
+	assert.Error(t, validatePromptTemplate("unknown", "{{.Sentence}}"))
+}
+
+func setupPromptTemplateTestDB(t *testing.T) {
+	t.Helper()
+	setupTestDB(t, &types.PromptTemplate{}, &types.PromptTemplateVersion{})
+}
+
+func TestResolvePromptTemplates(t *testing.T) {
+	setupPromptTemplateTestDB(t)
+	originalConf := config.Conf
+	defer func() { config.Conf = originalConf }()
+	config.Conf.Prompt = config.PromptConfig{
+		Defaults:  map[string]string{promptKindRunningSummary: "short_summary"},
+		Templates: []config.PromptTemplateConfig{{Name: "short_summary", Kind: promptKindRunningSummary, Content: "{{.Summary}} + {{.Lines}}"}},
+	}
+
+	tmpl := &types.PromptTemplate{Name: "casual", Kind: promptKindBatchTranslate, Content: "v1 {{.TargetLanguage}}{{.Instructions}}{{.Input}}"}
+	require.NoError(t, storage.CreatePromptTemplate(tmpl))
+	tmpl.Content = "v2 {{.TargetLanguage}}{{.Instructions}}{{.Input}}"
+	require.NoError(t, storage.UpdatePromptTemplate(tmpl))
+	assert.Equal(t, 2, tmpl.Version)
+
+	templates, err := resolvePromptTemplates(map[string]string{promptKindBatchTranslate: "casual"})
+	require.NoError(t, err)
+	assert.Equal(t, "v2 {{.TargetLanguage}}{{.Instructions}}{{.Input}}", templates[promptKindBatchTranslate])
+	assert.Equal(t, "{{.Summary}} + {{.Lines}}", templates[promptKindRunningSummary])
+	assert.NotContains(t, templates, promptKindSummaryTranscript)
+
+	templates, err = resolvePromptTemplates(map[string]string{promptKindBatchTranslate: "casual@1"})
+	require.NoError(t, err)
+	assert.Equal(t, "v1 {{.TargetLanguage}}{{.Instructions}}{{.Input}}", templates[promptKindBatchTranslate])
+
+	_, err = resolvePromptTemplates(map[string]string{promptKindSplitLongSentence: "casual"})
+	assert.ErrorContains(t, err, "kind")
+	_, err = resolvePromptTemplates(map[string]string{promptKindBatchTranslate: "missing"})
+	assert.ErrorContains(t, err, "not found")
+	_, err = resolvePromptTemplates(map[string]string{"no_such_kind": "casual"})
+	assert.Error(t, err)
+
+	svc := Service{PromptTemplates: templates}
+	prompt := svc.renderPrompt(promptKindBatchTranslate, batchTranslatePromptVars{TargetLanguage: "English", Input: "[]"})
+	assert.Equal(t, "v1 English[]", prompt)
+}
+
+func TestRenderPrompt_LegacySmartClipperPrompt(t *testing.T) {
+	originalConf := config.Conf
+	defer func() { config.Conf = originalConf }()
+	vars := smartClipperPromptVars{MinClipDuration: 30, MaxClipDuration: 90, Transcript: "hello"}
+
+	config.Conf.SmartClipper.Prompt = "clips between %d and %d seconds: %s"
+	assert.Equal(t, "clips between 30 and 90 seconds: hello", renderPrompt(nil, promptKindSmartClipper, vars))
+
+	// 占位符数量不对时回退到内置模板，而不是生成带 %!d(MISSING) 的提示词
+	config.Conf.SmartClipper.Prompt = "clips: %s"
+	assert.Equal(t, fmt.Sprintf(types.SmartClipperPrompt, 30, 90, "hello"), renderPrompt(nil, promptKindSmartClipper, vars))
+}
+
+func TestRenderPromptTemplate_Variables(t *testing.T) {
+	svc := Service{}
+	data, err := svc.RenderPromptTemplate(dto.RenderPromptTemplateReq{
+		Kind:      promptKindSmartClipper,
+		Content:   "{{.MinClipDuration}}-{{.MaxClipDuration}}: {{.Transcript}}",
+		Variables: map[string]any{"MaxClipDuration": 120},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "60-120: Hello everyone, welcome to this video.", data.Prompt)
+
+	_, err = svc.RenderPromptTemplate(dto.RenderPromptTemplateReq{Kind: promptKindSmartClipper, Variables: map[string]any{"Unknown": "x"}})
+	assert.Error(t, err)
+	_, err = svc.RenderPromptTemplate(dto.RenderPromptTemplateReq{Kind: promptKindSmartClipper, Variables: map[string]any{"MaxClipDuration": "long"}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"krillin-ai/config"
+	"krillin-ai/internal/types"
+	"krillin-ai/log"
+	"krillin-ai/pkg/ratelimit"
+	"krillin-ai/pkg/util"
+
+	"go.uber.org/zap"
+)
+
+// 标点恢复：whisper 等模型经常输出没有标点的文本，导致基于标点的分句失效，
+// 在 util.SplitTextSentences 之前先把标点补上
+
+const (
+	punctuationModeLlm   = "llm"
+	punctuationModePause = "pause"
+)
+
+const (
+	pauseSentenceGap     = 0.6  // 词间停顿超过该秒数视为句末
+	pauseCommaGap        = 0.3  // 词间停顿超过该秒数视为逗号
+	punctuationChunkSize = 1500 // 每次交给大模型补标点的最大字符数
+	pauseWordSearchLimit = 64   // 在文本中查找下一个词时允许跳过的最大字节数
+)
+
+// punctuationEnabled 是否开启了标点恢复，开启后不再需要按空格切分中日文的补丁
+func punctuationEnabled() bool {
+	mode := config.Conf.App.PunctuationMode
+	return mode == punctuationModeLlm || mode == punctuationModePause
+}
+
+// restorePunctuation 按配置恢复标点，大模型方式失败时回退到按停顿补标点
+func (s Service) restorePunctuation(ctx context.Context, text string, words []types.Word, language types.StandardLanguageCode) string {
+	if strings.TrimSpace(text) == "" {
+		return text
+	}
+	switch config.Conf.App.PunctuationMode {
+	case punctuationModeLlm:
+		restored, err := s.restorePunctuationByLlm(ctx, text, language)
+		if err == nil {
+			return restored
+		}
+		log.GetLogger().Warn("restorePunctuation llm failed, fallback to pause based", zap.Error(err))
+		return restorePunctuationByPause(text, words, language)
+	case punctuationModePause:
+		return restorePunctuationByPause(text, words, language)
+	}
+	return text
+}
+
+// restorePunctuationByLlm 分块让大模型补标点，并严格校验除标点和空格外的内容没有被改动
+func (s Service) restorePunctuationByLlm(ctx context.Context, text string, language types.StandardLanguageCode) (string, error) {
+	matcher := &BaseLanguageMatcher{}
+	restoredChunks := make([]string, 0)
+	for _, chunk := range splitTextIntoChunks(text, punctuationChunkSize) {
+		prompt := s.renderPrompt(promptKindRestorePunctuation, restorePunctuationPromptVars{Language: types.GetStandardLanguageName(language), Text: chunk})
+		var (
+			restored string
+			err      error
+		)
+		for attempt := range config.Conf.App.TranslateMaxAttempts {
+			restored, err = s.chat(ctx, llmCallSplit, prompt)
+			if err == nil {
+				restored = strings.TrimSpace(util.CleanMarkdownCodeBlock(restored))
+				if string(matcher.normalizeAlignText(restored)) == string(matcher.normalizeAlignText(chunk)) {
+					break
+				}
+				err = fmt.Errorf("restorePunctuationByLlm words were changed by llm")
+			}
+			log.GetLogger().Warn("restorePunctuationByLlm retry", zap.Int("attempt", attempt+1), zap.Error(err))
+			if attempt+1 < config.Conf.App.TranslateMaxAttempts {
+				time.Sleep(ratelimit.Backoff(attempt))
+			}
+		}
+		if err != nil {
+			return "", err
+		}
+		restoredChunks = append(restoredChunks, restored)
+	}
+
+	if isUnspacedLanguage(language) {
+		return strings.Join(restoredChunks, ""), nil
+	}
+	return strings.Join(restoredChunks, " "), nil
+}
+
+// restorePunctuationByPause 根据词间停顿在原文中插入句号或逗号，已有标点的位置不重复添加
+func restorePunctuationByPause(text string, words []types.Word, language types.StandardLanguageCode) string {
+	if len(words) < 2 {
+		return text
+	}
+	unspaced := isUnspacedLanguage(language)
+	sentenceMark, commaMark := ".", ","
+	if language == types.LanguageNameSimplifiedChinese || language == types.LanguageNameTraditionalChinese || language == types.LanguageNameJapanese {
+		sentenceMark, commaMark = "。", "，"
+	}
+
+	var builder strings.Builder
+	cursor := 0
+	for i, word := range words[:len(words)-1] {
+		end := findWordEnd(text, cursor, word.Text, !unspaced)
+		if end < 0 {
+			continue
+		}
+		builder.WriteString(text[cursor:end])
+		cursor = end
+
+		gap := words[i+1].Start - word.End
+		mark := ""
+		if gap >= pauseSentenceGap {
+			mark = sentenceMark
+		} else if gap >= pauseCommaGap {
+			mark = commaMark
+		}
+		if mark == "" {
+			continue
+		}
+		rest := text[cursor:]
+		trimmed := strings.TrimLeft(rest, " ")
+		if trimmed == "" {
+			continue
+		}
+		if r, _ := utf8.DecodeRuneInString(trimmed); unicode.IsPunct(r) {
+			continue
+		}
+		builder.WriteString(mark)
+		if unspaced {
+			// 中日文标点后不需要保留空格
+			cursor += len(rest) - len(trimmed)
+		}
+	}
+	builder.WriteString(text[cursor:])
+	return builder.String()
+}
+
+// findWordEnd 从 cursor 开始查找词在文本中的结束位置，以空格分词的语言要求命中完整单词
+func findWordEnd(text string, cursor int, word string, wholeWord bool) int {
+	if word == "" {
+		return -1
+	}
+	offset := cursor
+	for offset-cursor <= pauseWordSearchLimit {
+		pos := strings.Index(text[offset:], word)
+		if pos < 0 || offset+pos-cursor > pauseWordSearchLimit {
+			return -1
+		}
+		end := offset + pos + len(word)
+		if !wholeWord || end == len(text) {
+			return end
+		}
+		if r, _ := utf8.DecodeRuneInString(text[end:]); !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return end
+		}
+		offset = end
+	}
+	return -1
+}
+
+// splitTextIntoChunks 按最大字符数切块，尽量在空白处断开
+func splitTextIntoChunks(text string, size int) []string {
+	runes := []rune(strings.TrimSpace(text))
+	chunks := make([]string, 0)
+	for len(runes) > size {
+		cut := size
+		for i := size; i > size/2; i-- {
+			if unicode.IsSpace(runes[i]) {
+				cut = i
+				break
+			}
+		}
+		chunks = append(chunks, strings.TrimSpace(string(runes[:cut])))
+		runes = runes[cut:]
+	}
+	if len(runes) > 0 {
+		chunks = append(chunks, strings.TrimSpace(string(runes)))
+	}
+	return chunks
+}
+
+// isUnspacedLanguage 词与词之间不用空格分隔的语言
+func isUnspacedLanguage(language types.StandardLanguageCode) bool {
+	return language == types.LanguageNameSimplifiedChinese || language == types.LanguageNameTraditionalChinese ||
+		language == types.LanguageNameJapanese || language == types.LanguageNameThai
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"krillin-ai/config"
+	"krillin-ai/internal/mocks"
+	"krillin-ai/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRestorePunctuationByPause_English(t *testing.T) {
+	words := []types.Word{
+		{Text: "hello", Start: 0, End: 0.4},
+		{Text: "there", Start: 0.5, End: 0.9},
+		{Text: "and", Start: 1.3, End: 1.5},
+		{Text: "welcome", Start: 1.6, End: 2.0},
+		{Text: "back", Start: 3.0, End: 3.3},
+	}
+
+	got := restorePunctuationByPause("hello there and welcome back", words, types.LanguageNameEnglish)
+
+	assert.Equal(t, "hello there, and welcome. back", got)
+}
+
+func TestRestorePunctuationByPause_ChineseDropsSpace(t *testing.T) {
+	words := []types.Word{
+		{Text: "大家好", Start: 0, End: 0.8},
+		{Text: "今天", Start: 1.6, End: 2.0},
+		{Text: "我们", Start: 2.0, End: 2.3},
+	}
+
+	got := restorePunctuationByPause("大家好 今天我们", words, types.LanguageNameSimplifiedChinese)
+
+	assert.Equal(t, "大家好。今天我们", got)
+}
+
+func TestRestorePunctuationByLlm_RejectsChangedWords(t *testing.T) {
+	oldAttempts := config.Conf.App.TranslateMaxAttempts
+	config.Conf.App.TranslateMaxAttempts = 2
+	defer func() { config.Conf.App.TranslateMaxAttempts = oldAttempts }()
+
+	mockChatCompleter := new(mocks.MockChatCompleter)
+	mockChatCompleter.On("ChatCompletion", mock.Anything).Return("Hello there, and welcome to the show.", nil).Once()
+	mockChatCompleter.On("ChatCompletion", mock.Anything).Return("Hello there, and welcome back.", nil).Once()
+	svc := Service{ChatCompleter: mockChatCompleter}
+
+	got, err := svc.restorePunctuationByLlm(context.Background(), "hello there and welcome back", types.LanguageNameEnglish)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello there, and welcome back.", got)
+	mockChatCompleter.AssertExpectations(t)
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+
+	"krillin-ai/internal/types"
+	"krillin-ai/log"
+
+	"go.uber.org/zap"
+)
+
+// 文字替换规则：source 规则修正识别文本，翻译输入和时间戳生成后展示的原文使用替换结果，target 规则在翻译后修改译文，tts 规则只作用于送去配音的文字。
+// 规则按关联规则集的顺序、规则集内按 position 顺序依次执行
+
+// textReplacer 某个环节、某个语言下生效的规则，正则已预先编译
+type textReplacer struct {
+	rules []compiledReplacementRule
+}
+
+type compiledReplacementRule struct {
+	literal     string         // 区分大小写的普通文本规则直接替换
+	re          *regexp.Regexp // 正则规则和不区分大小写的普通文本规则
+	replacement string
+}
+
+// newTextReplacer 筛选出指定环节和语言下生效的规则，非法的正则跳过
+func newTextReplacer(rules []types.ReplacementRule, scope string, language types.StandardLanguageCode) *textReplacer {
+	replacer := &textReplacer{}
+	for _, rule := range rules {
+		if rule.Scope != scope || rule.Pattern == "" {
+			continue
+		}
+		if len(rule.Languages) > 0 && !slices.Contains(rule.Languages, string(language)) {
+			continue
+		}
+		if !rule.IsRegex && rule.CaseSensitive {
+			replacer.rules = append(replacer.rules, compiledReplacementRule{literal: rule.Pattern, replacement: rule.Replacement})
+			continue
+		}
+		pattern := rule.Pattern
+		if !rule.IsRegex {
+			pattern = regexp.QuoteMeta(pattern)
+		}
+		if !rule.CaseSensitive {
+			pattern = "(?i)" + pattern
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			log.GetLogger().Warn("newTextReplacer invalid pattern", zap.String("pattern", rule.Pattern), zap.Error(err))
+			continue
+		}
+		replacement := rule.Replacement
+		if !rule.IsRegex {
+			replacement = strings.ReplaceAll(replacement, "$", "$$")
+		}
+		replacer.rules = append(replacer.rules, compiledReplacementRule{re: re, replacement: replacement})
+	}
+	return replacer
+}
+
+func (r *textReplacer) Empty() bool {
+	return len(r.rules) == 0
+}
+
+// Replace 依次执行所有规则，前面规则的结果作为后面规则的输入
+func (r *textReplacer) Replace(text string) string {
+	for _, rule := range r.rules {
+		if rule.re != nil {
+			text = rule.re.ReplaceAllString(text, rule.replacement)
+		} else {
+			text = strings.ReplaceAll(text, rule.literal, rule.replacement)
+		}
+	}
+	return text
+}
+
+// sourceTranslationInputs 翻译前替换原文，返回保留下来的识别原文和替换后的翻译输入，两者一一对应，替换后为空的句子丢弃。
+// 识别原文用于按识别词对齐时间戳，替换后的文字只作为翻译输入
+func sourceTranslationInputs(stepParam *types.SubtitleTaskStepParam, sentences []string) (origins []string, inputs []string) {
+	replacer := newTextReplacer(stepParam.ReplacementRules, types.ReplacementScopeSource, stepParam.OriginLanguage)
+	if replacer.Empty() {
+		return sentences, sentences
+	}
+	origins = make([]string, 0, len(sentences))
+	inputs = make([]string, 0, len(sentences))
+	for _, sentence := range sentences {
+		if replaced := strings.TrimSpace(replacer.Replace(sentence)); replaced != "" {
+			origins = append(origins, sentence)
+			inputs = append(inputs, replaced)
+		}
+	}
+	return origins, inputs
+}
+
+// replaceTranslatedItems 翻译后替换译文，完整译文和精简译文都会替换
+func replaceTranslatedItems(stepParam *types.SubtitleTaskStepParam, items []*TranslatedItem, language types.StandardLanguageCode) {
+	replacer := newTextReplacer(stepParam.ReplacementRules, types.ReplacementScopeTarget, language)
+	if replacer.Empty() {
+		return
+	}
+	for _, item := range items {
+		if item == nil {
+			continue
+		}
+		item.TranslatedText = replacer.Replace(item.TranslatedText)
+		if item.CondensedText != "" {
+			item.CondensedText = replacer.Replace(item.CondensedText)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"testing"
+
+	"krillin-ai/internal/types"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTextReplacer_AppliesRulesInOrder(t *testing.T) {
+	rules := []types.ReplacementRule{
+		{Scope: types.ReplacementScopeSource, Pattern: "krilin", Replacement: "Krillin"},
+		{Scope: types.ReplacementScopeSource, Pattern: `(\d+)\s*percent`, Replacement: "$1%", IsRegex: true, CaseSensitive: true},
+		{Scope: types.ReplacementScopeSource, Pattern: "Krillin", Replacement: "Krillin AI", CaseSensitive: true},
+		{Scope: types.ReplacementScopeSource, Pattern: "$5", Replacement: "five $", CaseSensitive: true},
+		{Scope: types.ReplacementScopeTarget, Pattern: "Krillin", Replacement: "克林"},
+	}
+
+	replacer := newTextReplacer(rules, types.ReplacementScopeSource, types.LanguageNameEnglish)
+
+	assert.Equal(t, "Krillin AI is 99% ready for five $", replacer.Replace("KRILIN is 99 percent ready for $5"))
+}
+
+func TestTextReplacer_FiltersByLanguage(t *testing.T) {
+	rules := []types.ReplacementRule{
+		{Scope: types.ReplacementScopeTts, Pattern: "AI", Replacement: "A I", CaseSensitive: true, Languages: []string{string(types.LanguageNameSimplifiedChinese)}},
+		{Scope: types.ReplacementScopeTts, Pattern: "(", Replacement: "", IsRegex: true},
+	}
+
+	assert.Equal(t, "A I 字幕", newTextReplacer(rules, types.ReplacementScopeTts, types.LanguageNameSimplifiedChinese).Replace("AI 字幕"))
+	assert.True(t, newTextReplacer(rules, types.ReplacementScopeTts, types.LanguageNameEnglish).Empty())
+}
+
+func TestSourceTranslationInputs_KeepsRecognizedOrigin(t *testing.T) {
+	stepParam := &types.SubtitleTaskStepParam{
+		OriginLanguage: types.LanguageNameEnglish,
+		ReplacementRules: []types.ReplacementRule{
+			{Scope: types.ReplacementScopeSource, Pattern: `^\s*(um|uh)[.,]?\s*$`, IsRegex: true},
+			{Scope: types.ReplacementScopeSource, Pattern: "krilin", Replacement: "Krillin"},
+		},
+	}
+
+	origins, inputs := sourceTranslationInputs(stepParam, []string{"Um.", "Hello krilin."})
+
+	assert.Equal(t, []string{"Hello krilin."}, origins)
+	assert.Equal(t, []string{"Hello Krillin."}, inputs)
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"krillin-ai/config"
+	"krillin-ai/internal/storage"
+	"krillin-ai/log"
+
+	"go.uber.org/zap"
+)
+
+// 镜头切换检测：用 ffmpeg 的 scene 滤镜给每帧打场景变化分数，分数超过阈值的帧视为镜头切换，
+// 从 showinfo 的输出中读取这些帧的时间，供时间轴规整把字幕起止时间对齐到镜头切换
+
+var showinfoPtsTimeRegex = regexp.MustCompile(`pts_time:\s*([0-9]+(?:\.[0-9]+)?)`)
+
+// detectShotChanges 检测视频的镜头切换时间点（升序）
+func detectShotChanges(inputVideo string, threshold float64) ([]time.Duration, error) {
+	cmd := exec.Command(storage.FfmpegPath, "-hide_banner", "-i", inputVideo, "-an", "-sn",
+		"-vf", fmt.Sprintf("select='gt(scene,%.3f)',showinfo", threshold), "-f", "null", "-")
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	if err := cmd.Run(); err != nil {
+		log.GetLogger().Error("detectShotChanges ffmpeg error", zap.String("input", inputVideo), zap.String("output", out.String()), zap.Error(err))
+		return nil, fmt.Errorf("detectShotChanges ffmpeg error: %w", err)
+	}
+	return parseShotChanges(out.String()), nil
+}
+
+// parseShotChanges 从 showinfo 输出中解析被选中帧的时间
+func parseShotChanges(output string) []time.Duration {
+	shotChanges := make([]time.Duration, 0)
+	for _, line := range strings.Split(output, "\n") {
+		if !strings.Contains(line, "Parsed_showinfo") {
+			continue
+		}
+		match := showinfoPtsTimeRegex.FindStringSubmatch(line)
+		if len(match) != 2 {
+			continue
+		}
+		seconds, err := strconv.ParseFloat(match[1], 64)
+		if err != nil {
+			continue
+		}
+		shotChanges = append(shotChanges, time.Duration(seconds*float64(time.Second)))
+	}
+	sort.Slice(shotChanges, func(i, j int) bool { return shotChanges[i] < shotChanges[j] })
+	return shotChanges
+}
+
+// getFrameRate 获取视频的帧率
+func getFrameRate(inputVideo string) (float64, error) {
+	cmd := exec.Command(storage.FfprobePath, "-v", "error", "-select_streams", "v:0",
+		"-show_entries", "stream=r_frame_rate", "-of", "csv=p=0", inputVideo)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return 0, fmt.Errorf("getFrameRate ffprobe error: %w, output: %s", err, string(output))
+	}
+	return parseFrameRate(string(output))
+}
+
+// parseFrameRate 解析 ffprobe 输出的帧率，如 30000/1001 或 25
+func parseFrameRate(value string) (float64, error) {
+	value = strings.TrimSuffix(strings.TrimSpace(value), ",")
+	numerator, denominator, found := strings.Cut(value, "/")
+	num, err := strconv.ParseFloat(numerator, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid frame rate: %s", value)
+	}
+	den := 1.0
+	if found {
+		if den, err = strconv.ParseFloat(denominator, 64); err != nil || den == 0 {
+			return 0, fmt.Errorf("invalid frame rate: %s", value)
+		}
+	}
+	if num <= 0 {
+		return 0, fmt.Errorf("invalid frame rate: %s", value)
+	}
+	return num / den, nil
+}
+
+// shotChangeTiming 开启镜头切换对齐时返回镜头切换时间点和结束时间需要留在切换前的间隔，
+// 没有视频或检测失败时返回空，不影响其他时间轴规整
+func shotChangeTiming(inputVideo string) ([]time.Duration, time.Duration) {
+	timing := config.Conf.SubtitleTiming
+	if !timing.ShotChange || inputVideo == "" {
+		return nil, 0
+	}
+	if _, err := os.Stat(inputVideo); err != nil {
+		log.GetLogger().Info("shotChangeTiming skipped, input video not found", zap.String("input", inputVideo))
+		return nil, 0
+	}
+	frameRate, err := getFrameRate(inputVideo)
+	if err != nil {
+		log.GetLogger().Warn("shotChangeTiming getFrameRate failed, skip shot change snapping", zap.String("input", inputVideo), zap.Error(err))
+		return nil, 0
+	}
+	shotChanges, err := detectShotChanges(inputVideo, timing.SceneThreshold)
+	if err != nil {
+		log.GetLogger().Warn("shotChangeTiming detectShotChanges failed, skip shot change snapping", zap.String("input", inputVideo), zap.Error(err))
+		return nil, 0
+	}
+	log.GetLogger().Info("shotChangeTiming detected shot changes", zap.String("input", inputVideo), zap.Int("count", len(shotChanges)), zap.Float64("frameRate", frameRate))
+	return shotChanges, time.Duration(float64(timing.FramesBeforeCut) / frameRate * float64(time.Second))
+}
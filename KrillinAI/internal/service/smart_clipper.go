@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"krillin-ai/config"
@@ -25,7 +26,7 @@ import (
 var analysisCache sync.Map
 
 // AnalyzeVideo download subtitles and ask AI to split video
-func (s *Service) AnalyzeVideo(req dto.SmartClipperAnalyzeReq) (*dto.SmartClipperAnalyzeResData, error) {
+func (s *Service) AnalyzeVideo(ctx context.Context, req dto.SmartClipperAnalyzeReq) (*dto.SmartClipperAnalyzeResData, error) {
 	log.GetLogger().Info("SmartClipper: AnalyzeVideo", zap.String("url", req.Url))
 
 	// 1. Create temporary directory for analysis
@@ -106,17 +107,24 @@ func (s *Service) AnalyzeVideo(req dto.SmartClipperAnalyzeReq) (*dto.SmartClippe
 	cleanText := cleanVttContent(string(subContent))
 
 	// 5. Call Kimi (LLM) for Analysis
-	basePrompt := config.Conf.SmartClipper.Prompt
-	if basePrompt == "" {
-		basePrompt = types.SmartClipperPrompt
-	}
-	prompt := fmt.Sprintf(basePrompt, config.Conf.SmartClipper.MinClipDuration, config.Conf.SmartClipper.MaxClipDuration, cleanText)
+	prompt := s.renderPrompt(promptKindSmartClipper, smartClipperPromptVars{
+		MinClipDuration: config.Conf.SmartClipper.MinClipDuration,
+		MaxClipDuration: config.Conf.SmartClipper.MaxClipDuration,
+		Transcript:      cleanText,
+	})
 
 	// Determine Config (Fallback to global LLM config if specific one is empty)
 	scBaseUrl := config.Conf.SmartClipper.BaseUrl
 	scApiKey := config.Conf.SmartClipper.ApiKey
-
-	if scApiKey == "" {
+	scModel := "" // 为空时使用 llm.model
+	rateLimitProvider := "smart_clipper"
+
+	if profile, ok := llmProfileFor(llmCallClipper); ok {
+		// llm.routes 为智能切片指定了模型配置时优先使用
+		scBaseUrl, scApiKey, scModel, rateLimitProvider = profile.BaseUrl, profile.ApiKey, profile.Model, profile.Name
+		log.GetLogger().Info("SmartClipper: Using routed LLM profile", zap.String("profile", profile.Name), zap.String("model", profile.Model))
+	} else if scApiKey == "" {
+		rateLimitProvider = "llm" // 与主模型共用额度
 		// Fallback to main LLM config
 		// Use Transcribe.Openai if provider is openai? Or Llm?
 		// User mentioned "Kimi API Key already provided" for translation.
@@ -135,11 +143,20 @@ func (s *Service) AnalyzeVideo(req dto.SmartClipperAnalyzeReq) (*dto.SmartClippe
 		scBaseUrl,
 		scApiKey,
 		config.Conf.App.Proxy,
-	)
+	).WithModel(scModel).WithRateLimit(rateLimitProvider).WithTimeout(rateLimitProvider)
 
 	// Call ChatCompletion (Non-streaming for simplicity in backend logic, but client only has streaming implemented in openai.go?
 	// openai.go: ChatCompletion returns string but uses stream internally. That's fine.)
-	llmResp, err := kimiClient.ChatCompletion(prompt)
+	var clipperCompleter types.StructuredChatCompleter = kimiClient
+	if config.Conf.Usage.Enabled {
+		// 分析时还没有子任务，用量记到该视频的主任务下
+		usageModel := scModel
+		if usageModel == "" {
+			usageModel = config.Conf.Llm.Model
+		}
+		clipperCompleter = usageChatCompleter{UsageChatCompleter: kimiClient, taskId: smartClipperMasterTaskId(videoInfo.ID), provider: rateLimitProvider, model: usageModel}
+	}
+	llmResp, err := clipperCompleter.Chat(ctx, newChatRequest(llmCallClipper, prompt, nil))
 	if err != nil {
 		return nil, fmt.Errorf("LLM analysis failed: %w", err)
 	}
@@ -173,6 +190,11 @@ func (s *Service) AnalyzeVideo(req dto.SmartClipperAnalyzeReq) (*dto.SmartClippe
 	}, nil
 }
 
+// smartClipperMasterTaskId 同一个视频的完整视频下载目录，也用来记录切片分析的用量
+func smartClipperMasterTaskId(videoId string) string {
+	return fmt.Sprintf("master_%s", videoId)
+}
+
 // SubmitClips processes selected clips
 func (s *Service) SubmitClips(req dto.SmartClipperSubmitReq) (*dto.SmartClipperSubmitResData, error) {
 	// 1. Retrieve Cache
@@ -185,7 +207,7 @@ func (s *Service) SubmitClips(req dto.SmartClipperSubmitReq) (*dto.SmartClipperS
 	// 2. Download Full Video (if not present)
 	// Currently data.VideoPath is empty. We need to download it.
 	// We create a "master" task folder for this video source
-	masterTaskId := fmt.Sprintf("master_%s", data.VideoId)
+	masterTaskId := smartClipperMasterTaskId(data.VideoId)
 	masterTaskDir, err := resolveTaskDir(masterTaskId)
 	if err != nil {
 		return nil, fmt.Errorf("failed to resolve master task dir: %w", err)
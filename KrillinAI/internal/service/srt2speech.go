@@ -30,6 +30,7 @@ func (s Service) srtFileToSpeech(ctx context.Context, stepParam *types.SubtitleT
 		return fmt.Errorf("srtFileToSpeech parseSRT error: %w", err)
 	}
 
+	ttsReplacer := newTextReplacer(stepParam.ReplacementRules, types.ReplacementScopeTts, stepParam.TargetLanguage)
 	var audioFiles []string
 	// Track the actual physical duration of the generated audio stream
 	var currentAudioCursor float64 = 0
@@ -91,7 +92,7 @@ func (s Service) srtFileToSpeech(ctx context.Context, stepParam *types.SubtitleT
 
 		// 3. Generate TTS Audio
 		// Call TTS Service to generate audio file
-		err = s.TtsClient.Text2Speech(sub.Text, stepParam.TtsVoiceCode, outputFile)
+		err = s.TtsClient.Text2Speech(ctx, ttsReplacer.Replace(sub.Text), stepParam.TtsVoiceCode, outputFile)
 		if err != nil {
 			log.GetLogger().Error("srtFileToSpeech TTS generation error", 
 				zap.Int("index", i+1),
@@ -180,7 +181,7 @@ func (s Service) srtFileToSpeech(ctx context.Context, stepParam *types.SubtitleT
 	return nil
 }
 
-func (s Service) processSubtitlesConcurrently(subtitles []types.SrtSentenceWithStrTime, voiceCode string, stepParam *types.SubtitleTaskStepParam) error {
+func (s Service) processSubtitlesConcurrently(ctx context.Context, subtitles []types.SrtSentenceWithStrTime, voiceCode string, stepParam *types.SubtitleTaskStepParam) error {
 	// 创建一个结果数组来存储每个字幕的处理结果
 	type processingResult struct {
 		index int
@@ -191,6 +192,7 @@ func (s Service) processSubtitlesConcurrently(subtitles []types.SrtSentenceWithS
 	semaphore := make(chan struct{}, maxConcurrency)
 	var wg sync.WaitGroup
 	resultCh := make(chan processingResult, len(subtitles))
+	ttsReplacer := newTextReplacer(stepParam.ReplacementRules, types.ReplacementScopeTts, stepParam.TargetLanguage)
 
 	// 并发生成所有音频文件
 	for i, sub := range subtitles {
@@ -203,7 +205,7 @@ func (s Service) processSubtitlesConcurrently(subtitles []types.SrtSentenceWithS
 			defer func() { <-semaphore }()
 
 			outputFile := filepath.Join(stepParam.TaskBasePath, fmt.Sprintf("subtitle_%d.wav", index+1))
-			err := s.TtsClient.Text2Speech(subtitle.Text, voiceCode, outputFile)
+			err := s.TtsClient.Text2Speech(ctx, ttsReplacer.Replace(subtitle.Text), voiceCode, outputFile)
 			if err != nil {
 				log.GetLogger().Error("processSubtitlesConcurrently Text2Speech error",
 					zap.Any("index", index+1),
@@ -8,6 +8,7 @@ import (
 	"krillin-ai/internal/storage"
 	"krillin-ai/internal/types"
 	"krillin-ai/log"
+	"krillin-ai/pkg/subtitle"
 	"krillin-ai/pkg/util"
 	"os"
 	"os/exec"
@@ -48,8 +49,9 @@ func (s Service) embedSubtitles(ctx context.Context, stepParam *types.SubtitleTa
 					return fmt.Errorf("embedSubtitles embedSubtitles error: %w", err)
 				}
 				stepParam.SubtitleInfos = append(stepParam.SubtitleInfos, types.SubtitleFileInfo{
-					Name: "horizontal_embed.mp4",
-					Path: filepath.Join(stepParam.TaskBasePath, "output", types.SubtitleTaskHorizontalEmbedVideoFileName),
+					Name:               "horizontal_embed.mp4",
+					Path:               filepath.Join(stepParam.TaskBasePath, "output", types.SubtitleTaskHorizontalEmbedVideoFileName),
+					LanguageIdentifier: outputLanguageIdentifier(stepParam),
 				})
 			}
 		}
@@ -71,8 +73,9 @@ func (s Service) embedSubtitles(ctx context.Context, stepParam *types.SubtitleTa
 				return fmt.Errorf("embedSubtitles embedSubtitles error: %w", err)
 			}
 			stepParam.SubtitleInfos = append(stepParam.SubtitleInfos, types.SubtitleFileInfo{
-				Name: "vertical_embed.mp4",
-				Path: filepath.Join(stepParam.TaskBasePath, "output", types.SubtitleTaskVerticalEmbedVideoFileName),
+				Name:               "vertical_embed.mp4",
+				Path:               filepath.Join(stepParam.TaskBasePath, "output", types.SubtitleTaskVerticalEmbedVideoFileName),
+				LanguageIdentifier: outputLanguageIdentifier(stepParam),
 			})
 		}
 		log.GetLogger().Info("字幕嵌入视频成功")
@@ -200,8 +203,13 @@ func srtToAss(inputSRT, outputASS string, isHorizontal bool, stepParam *types.Su
 	defer assFile.Close()
 	scanner := bufio.NewScanner(file)
 
+	header, alignment, err := burnInAssHeader(stepParam, isHorizontal)
+	if err != nil {
+		log.GetLogger().Error("srtToAss burnInAssHeader error", zap.Error(err))
+		return fmt.Errorf("srtToAss %w", err)
+	}
+	_, _ = assFile.WriteString(header)
 	if isHorizontal {
-		_, _ = assFile.WriteString(types.AssHeaderHorizontal)
 		for scanner.Scan() {
 			line := scanner.Text()
 			if line == "" {
@@ -258,12 +266,11 @@ func srtToAss(inputSRT, outputASS string, isHorizontal bool, stepParam *types.Su
 				majorText = subtitleLines[1]                     // 中文
 				minorText = util.CleanPunction(subtitleLines[0]) // 英文
 			}
-			combinedText := fmt.Sprintf("{\\an2}{\\rMajor}%s\\N{\\rMinor}%s", majorText, minorText)
+			combinedText := fmt.Sprintf("%s{\\rMajor}%s\\N{\\rMinor}%s", alignment, majorText, minorText)
 			_, _ = assFile.WriteString(fmt.Sprintf("Dialogue: 0,%s,%s,Major,,0,0,0,,%s\n", startFormatted, endFormatted, combinedText))
 		}
 	} else {
 		// TODO 竖屏拆分调优
-		_, _ = assFile.WriteString(types.AssHeaderVertical)
 		for scanner.Scan() {
 			line := scanner.Text()
 			if line == "" {
@@ -320,7 +327,7 @@ func srtToAss(inputSRT, outputASS string, isHorizontal bool, stepParam *types.Su
 					minorText = util.CleanPunction(subtitleLines[0])
 				}
 				// 竖屏双语：直接显示，不做时间切分，防止错位
-				combinedText := fmt.Sprintf("{\\an2}{\\rMajor}%s\\N{\\rMinor}%s", majorText, minorText)
+				combinedText := fmt.Sprintf("%s{\\rMajor}%s\\N{\\rMinor}%s", alignment, majorText, minorText)
 				_, _ = assFile.WriteString(fmt.Sprintf("Dialogue: 0,%s,%s,Major,,0,0,0,,%s\n", startFormatted, endFormatted, combinedText))
 				continue
 			}
@@ -342,13 +349,13 @@ func srtToAss(inputSRT, outputASS string, isHorizontal bool, stepParam *types.Su
 					startFormatted := formatTimestamp(iStart)
 					endFormatted := formatTimestamp(iEnd)
 					cleanedText := util.CleanPunction(line)
-					combinedText := fmt.Sprintf("{\\an2}{\\rMajor}%s", cleanedText)
+					combinedText := fmt.Sprintf("%s{\\rMajor}%s", alignment, cleanedText)
 					_, _ = assFile.WriteString(fmt.Sprintf("Dialogue: 0,%s,%s,Major,,0,0,0,,%s\n", startFormatted, endFormatted, combinedText))
 				}
 			} else {
 				// 处理英文字幕
 				cleanedText := util.CleanPunction(content)
-				combinedText := fmt.Sprintf("{\\an2}{\\rMinor}%s", cleanedText)
+				combinedText := fmt.Sprintf("%s{\\rMinor}%s", alignment, cleanedText)
 				_, _ = assFile.WriteString(fmt.Sprintf("Dialogue: 0,%s,%s,Minor,,0,0,0,,%s\n", startFormatted, endFormatted, combinedText))
 			}
 		}
@@ -356,6 +363,22 @@ func srtToAss(inputSRT, outputASS string, isHorizontal bool, stepParam *types.Su
 	return nil
 }
 
+// burnInAssHeader 嵌入视频用的 ASS 头和对齐标签。选用了样式预设时按视频分辨率生成 ASS 头，位置由样式决定，
+// 否则沿用默认的横屏/竖屏样式，固定在底部居中
+func burnInAssHeader(stepParam *types.SubtitleTaskStepParam, isHorizontal bool) (string, string, error) {
+	if stepParam.SubtitleStyle != nil {
+		width, height, err := getResolution(stepParam.InputVideoPath)
+		if err != nil {
+			return "", "", fmt.Errorf("burnInAssHeader getResolution err: %w", err)
+		}
+		return subtitle.StyledAssHeader(stepParam.SubtitleStyle, width, height), "", nil
+	}
+	if isHorizontal {
+		return types.AssHeaderHorizontal, "{\\an2}", nil
+	}
+	return types.AssHeaderVertical, "{\\an2}", nil
+}
+
 func embedSubtitles(stepParam *types.SubtitleTaskStepParam, isHorizontal bool, withTts bool) error {
 	outputFileName := types.SubtitleTaskVerticalEmbedVideoFileName
 	if isHorizontal {
@@ -363,7 +386,13 @@ func embedSubtitles(stepParam *types.SubtitleTaskStepParam, isHorizontal bool, w
 	}
 	assPath := filepath.Join(stepParam.TaskBasePath, "formatted_subtitles.ass")
 
-	if err := srtToAss(stepParam.BilingualSrtFilePath, assPath, isHorizontal, stepParam); err != nil {
+	if stepParam.KaraokeMode != "" {
+		// 卡拉OK字幕按识别词逐词高亮原文
+		if err := writeKaraokeAss(stepParam, assPath, isHorizontal); err != nil {
+			log.GetLogger().Error("embedSubtitles writeKaraokeAss error", zap.Any("step param", stepParam), zap.Error(err))
+			return fmt.Errorf("embedSubtitles writeKaraokeAss error: %w", err)
+		}
+	} else if err := srtToAss(stepParam.BilingualSrtFilePath, assPath, isHorizontal, stepParam); err != nil {
 		log.GetLogger().Error("embedSubtitles srtToAss error", zap.Any("step param", stepParam), zap.Error(err))
 		return fmt.Errorf("embedSubtitles srtToAss error: %w", err)
 	}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"krillin-ai/internal/types"
+	"krillin-ai/log"
+
+	"go.uber.org/zap"
+)
+
+// 翻译风格：把语气、正式程度、受众等要求注入批量翻译 prompt，
+// 不翻译规则匹配到的原文按术语表中的"保留原文"术语处理，复用术语表的注入和校验
+
+var styleFormalityDescriptions = map[string]string{
+	types.StyleFormalityFormal:   "formal, polite register",
+	types.StyleFormalityNeutral:  "neutral register, neither stiff nor colloquial",
+	types.StyleFormalityInformal: "informal, conversational register",
+}
+
+// buildStylePrompt 生成附加在批量翻译 prompt 中的风格要求，没有任何要求时返回空
+func buildStylePrompt(profile *types.StyleProfile) string {
+	if profile == nil {
+		return ""
+	}
+	lines := make([]string, 0, 5)
+	if profile.Tone != "" {
+		lines = append(lines, "- Tone: "+profile.Tone)
+	}
+	if profile.Formality != "" {
+		formality := profile.Formality
+		if description, ok := styleFormalityDescriptions[formality]; ok {
+			formality = description
+		}
+		lines = append(lines, "- Formality: "+formality)
+	}
+	if profile.Audience != "" {
+		lines = append(lines, "- Audience: "+profile.Audience)
+	}
+	if profile.Honorifics != "" {
+		lines = append(lines, "- Honorifics and forms of address: "+profile.Honorifics)
+	}
+	if profile.CustomInstructions != "" {
+		lines = append(lines, "- Additional instructions: "+profile.CustomInstructions)
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(types.BatchTranslateStylePrompt, strings.Join(lines, "\n"))
+}
+
+// styleDoNotTranslateTerms 返回这批句子中被不翻译规则匹配到的内容，作为保留原文的术语，已在术语表中的跳过
+func styleDoNotTranslateTerms(sentences []string, profile *types.StyleProfile, glossaryTerms []types.GlossaryTerm) []types.GlossaryTerm {
+	terms := make([]types.GlossaryTerm, 0)
+	if profile == nil {
+		return terms
+	}
+	seen := make(map[string]bool, len(glossaryTerms))
+	for _, term := range glossaryTerms {
+		seen[term.Source] = true
+	}
+	for _, pattern := range profile.DoNotTranslatePatterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			log.GetLogger().Warn("styleDoNotTranslateTerms invalid pattern", zap.String("pattern", pattern), zap.Error(err))
+			continue
+		}
+		for _, sentence := range sentences {
+			for _, match := range re.FindAllString(sentence, -1) {
+				match = strings.TrimSpace(match)
+				if match == "" || seen[match] {
+					continue
+				}
+				seen[match] = true
+				terms = append(terms, types.GlossaryTerm{Source: match, CaseSensitive: true, DoNotTranslate: true})
+			}
+		}
+	}
+	return terms
+}
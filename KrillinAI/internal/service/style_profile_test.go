@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"krillin-ai/config"
+	"krillin-ai/internal/mocks"
+	"krillin-ai/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestBuildStylePrompt(t *testing.T) {
+	assert.Empty(t, buildStylePrompt(nil))
+	assert.Empty(t, buildStylePrompt(&types.StyleProfile{Name: "empty"}))
+
+	prompt := buildStylePrompt(&types.StyleProfile{
+		Tone:               "casual gaming slang",
+		Formality:          types.StyleFormalityInformal,
+		Audience:           "teenage gamers",
+		CustomInstructions: "Keep jokes short.",
+	})
+
+	assert.Contains(t, prompt, "**Style**")
+	assert.Contains(t, prompt, "- Tone: casual gaming slang")
+	assert.Contains(t, prompt, "- Formality: informal, conversational register")
+	assert.Contains(t, prompt, "- Audience: teenage gamers")
+	assert.Contains(t, prompt, "- Additional instructions: Keep jokes short.")
+	assert.NotContains(t, prompt, "Honorifics")
+}
+
+func TestStyleDoNotTranslateTerms(t *testing.T) {
+	profile := &types.StyleProfile{DoNotTranslatePatterns: []string{`v\d+\.\d+`, `[A-Z]{2,}`, `(`}}
+	glossary := []types.GlossaryTerm{{Source: "GPU", Target: "显卡"}}
+
+	terms := styleDoNotTranslateTerms([]string{"Version v1.2 runs on GPU and CPU.", "CPU load is high."}, profile, glossary)
+
+	assert.Len(t, terms, 2)
+	assert.Equal(t, "v1.2", terms[0].Source)
+	assert.Equal(t, "CPU", terms[1].Source)
+	assert.True(t, terms[1].DoNotTranslate)
+	assert.Empty(t, styleDoNotTranslateTerms([]string{"CPU"}, nil, nil))
+}
+
+func TestTranslateSentences_InjectsStyleProfile(t *testing.T) {
+	oldAttempts := config.Conf.App.TranslateMaxAttempts
+	oldParallel := config.Conf.App.TranslateParallelNum
+	config.Conf.App.TranslateMaxAttempts = 2
+	config.Conf.App.TranslateParallelNum = 1
+	defer func() {
+		config.Conf.App.TranslateMaxAttempts = oldAttempts
+		config.Conf.App.TranslateParallelNum = oldParallel
+	}()
+
+	profile := &types.StyleProfile{Tone: "formal corporate", DoNotTranslatePatterns: []string{`Acme\w*`}}
+	mockChatCompleter := new(mocks.MockChatCompleter)
+	mockChatCompleter.On("ChatCompletion", mock.MatchedBy(func(prompt string) bool {
+		return strings.Contains(prompt, "- Tone: formal corporate") && strings.Contains(prompt, `"AcmeCloud" -> (keep as is)`) && !strings.Contains(prompt, "Attention")
+	})).Return(`["欢迎使用顶点云。"]`, nil).Once()
+	mockChatCompleter.On("ChatCompletion", mock.MatchedBy(func(prompt string) bool {
+		return strings.Contains(prompt, "Attention") && strings.Contains(prompt, `"AcmeCloud" must be translated as "AcmeCloud"`)
+	})).Return(`["欢迎使用 AcmeCloud。"]`, nil).Once()
+	svc := Service{ChatCompleter: mockChatCompleter}
+
+	results := svc.translateSentences(context.Background(), &types.SubtitleTaskStepParam{StyleProfile: profile}, []string{"Welcome to AcmeCloud."}, types.LanguageNameSimplifiedChinese, 0)
+
+	assert.Equal(t, "欢迎使用 AcmeCloud。", results[0].TranslatedText)
+	mockChatCompleter.AssertExpectations(t)
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"krillin-ai/internal/types"
+	"krillin-ai/log"
+	"krillin-ai/pkg/subtitle"
+	"krillin-ai/pkg/util"
+
+	"go.uber.org/zap"
+)
+
+// 多格式字幕导出：SRT 始终由 splitSrt 生成，请求可以为原文、译文、双语三条轨道分别指定额外的格式，
+// 各格式都由双语字幕构造的同一个字幕模型序列化
+
+const (
+	subtitleTrackOrigin    = "origin"
+	subtitleTrackTarget    = "target"
+	subtitleTrackBilingual = "bilingual"
+)
+
+var subtitleTracks = []string{subtitleTrackOrigin, subtitleTrackTarget, subtitleTrackBilingual}
+
+// parseSubtitleFormats 校验请求中的导出格式，统一为小写格式名并去重，srt 已默认生成不再重复导出
+func parseSubtitleFormats(formats map[string][]string) (map[string][]string, error) {
+	result := make(map[string][]string)
+	for track, names := range formats {
+		if !slices.Contains(subtitleTracks, track) {
+			return nil, fmt.Errorf("unsupported subtitle track: %s", track)
+		}
+		seen := make(map[subtitle.Format]bool)
+		for _, name := range names {
+			format, err := subtitle.ParseFormat(name)
+			if err != nil {
+				return nil, err
+			}
+			if format == subtitle.FormatSrt || seen[format] {
+				continue
+			}
+			seen[format] = true
+			result[track] = append(result[track], string(format))
+		}
+	}
+	return result, nil
+}
+
+// buildSubtitleTrack 从双语字幕构造某条轨道的字幕模型，双语轨道以译文为主文本
+func buildSubtitleTrack(stepParam *types.SubtitleTaskStepParam, srtBlocks []*util.SrtBlock, track string) (*subtitle.Track, error) {
+	result := &subtitle.Track{Language: string(stepParam.OriginLanguage), Cues: make([]subtitle.Cue, 0, len(srtBlocks))}
+	if track != subtitleTrackOrigin && stepParam.TargetLanguage != "none" {
+		result.Language = string(stepParam.TargetLanguage)
+	}
+	result.SubTextOnTop = track == subtitleTrackBilingual && stepParam.SubtitleResultType != types.SubtitleResultTypeBilingualTranslationOnTop
+	result.AssStyle = stepParam.SubtitleStyle
+	result.Karaoke = karaokeSettings(stepParam, track)
+	for _, block := range srtBlocks {
+		start, end, found := strings.Cut(block.Timestamp, "-->")
+		if !found {
+			continue
+		}
+		startTime, err := parseSrtTime(strings.TrimSpace(start))
+		if err != nil {
+			return nil, fmt.Errorf("buildSubtitleTrack parseSrtTime err: %w", err)
+		}
+		endTime, err := parseSrtTime(strings.TrimSpace(end))
+		if err != nil {
+			return nil, fmt.Errorf("buildSubtitleTrack parseSrtTime err: %w", err)
+		}
+		cue := subtitle.Cue{Start: startTime, End: endTime}
+		switch track {
+		case subtitleTrackOrigin:
+			cue.Text = block.OriginLanguageSentence
+		case subtitleTrackTarget:
+			cue.Text = block.TargetLanguageSentence
+		default:
+			cue.Text, cue.SubText = block.TargetLanguageSentence, block.OriginLanguageSentence
+		}
+		result.Cues = append(result.Cues, cue)
+	}
+
+	// 识别词是原文的词，译文轨道不附带
+	if track == subtitleTrackTarget {
+		return result, nil
+	}
+	words := make([]subtitle.Word, 0, len(stepParam.TranscribedWords))
+	for _, word := range stepParam.TranscribedWords {
+		words = append(words, subtitle.Word{Text: word.Text, Start: word.Start, End: word.End, Confidence: word.Confidence})
+	}
+	subtitle.AttachWords(result.Cues, words)
+	return result, nil
+}
+
+// exportSubtitleFormats 按任务指定的格式导出各轨道的字幕，并加入字幕文件列表
+func exportSubtitleFormats(stepParam *types.SubtitleTaskStepParam) error {
+	if len(stepParam.SubtitleFormats) == 0 {
+		return nil
+	}
+	srtBlocks, err := readBilingualSrtFile(stepParam.BilingualSrtFilePath, stepParam.SubtitleResultType)
+	if err != nil {
+		return fmt.Errorf("exportSubtitleFormats %w", err)
+	}
+
+	for _, track := range subtitleTracks {
+		formats := stepParam.SubtitleFormats[track]
+		if len(formats) == 0 || (track == subtitleTrackTarget && stepParam.SubtitleResultType == types.SubtitleResultTypeOriginOnly) {
+			continue
+		}
+		subtitleTrack, err := buildSubtitleTrack(stepParam, srtBlocks, track)
+		if err != nil {
+			return fmt.Errorf("exportSubtitleFormats %w", err)
+		}
+		for _, name := range formats {
+			format := subtitle.Format(name)
+			data, err := subtitle.Marshal(subtitleTrack, format)
+			if err != nil {
+				return fmt.Errorf("exportSubtitleFormats %s %w", track, err)
+			}
+			filePath := filepath.Join(stepParam.TaskBasePath, "output", fmt.Sprintf(types.SubtitleTaskExportSubtitleFileNamePattern, track, format.Extension()))
+			if err = os.WriteFile(filePath, data, 0644); err != nil {
+				return fmt.Errorf("exportSubtitleFormats write %s err: %w", filePath, err)
+			}
+			stepParam.SubtitleInfos = append(stepParam.SubtitleInfos, exportedSubtitleInfo(stepParam, track, format, filePath))
+		}
+	}
+	log.GetLogger().Info("exportSubtitleFormats completed", zap.Any("taskId", stepParam.TaskId), zap.Any("formats", stepParam.SubtitleFormats))
+	return nil
+}
+
+// exportedSubtitleInfo 导出文件的名称和语言标识与同一轨道的 SRT 保持一致
+func exportedSubtitleInfo(stepParam *types.SubtitleTaskStepParam, track string, format subtitle.Format, filePath string) types.SubtitleFileInfo {
+	info := types.SubtitleFileInfo{Path: filePath}
+	formatName := strings.ToUpper(string(format))
+	chinese := stepParam.UserUILanguage == types.LanguageNameSimplifiedChinese
+	switch track {
+	case subtitleTrackOrigin, subtitleTrackTarget:
+		language := stepParam.OriginLanguage
+		if track == subtitleTrackTarget {
+			language = stepParam.TargetLanguage
+		}
+		info.LanguageIdentifier = string(language)
+		info.Name = fmt.Sprintf("%s Subtitle (%s)", types.GetStandardLanguageName(language), formatName)
+		if chinese {
+			info.Name = fmt.Sprintf("%s 单语字幕 (%s)", types.GetStandardLanguageName(language), formatName)
+		}
+	default:
+		info.LanguageIdentifier = "bilingual"
+		info.Name = fmt.Sprintf("Bilingual Subtitle (%s)", formatName)
+		if chinese {
+			info.Name = fmt.Sprintf("双语字幕 (%s)", formatName)
+		}
+	}
+	return info
+}
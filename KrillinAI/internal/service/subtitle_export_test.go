@@ -0,0 +1,88 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"krillin-ai/internal/types"
+	"krillin-ai/pkg/util"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSubtitleFormats(t *testing.T) {
+	formats, err := parseSubtitleFormats(map[string][]string{
+		"origin":    {"VTT", "srt", "json"},
+		"bilingual": {"dfxp", "ttml", "ass"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{"origin": {"vtt", "json"}, "bilingual": {"ttml", "ass"}}, formats)
+
+	_, err = parseSubtitleFormats(map[string][]string{"origin": {"docx"}})
+	assert.Error(t, err)
+	_, err = parseSubtitleFormats(map[string][]string{"subtitle": {"vtt"}})
+	assert.Error(t, err)
+}
+
+func TestExportSubtitleFormats(t *testing.T) {
+	taskBasePath := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(taskBasePath, "output"), os.ModePerm))
+	bilingualFile := filepath.Join(taskBasePath, types.SubtitleTaskBilingualSrtFileName)
+	require.NoError(t, writeBilingualSrtFile(bilingualFile, []*util.SrtBlock{
+		{Index: 1, Timestamp: "00:00:01,000 --> 00:00:02,500", OriginLanguageSentence: "Hello world", TargetLanguageSentence: "你好世界"},
+	}, types.SubtitleResultTypeBilingualTranslationOnBottom))
+	stepParam := &types.SubtitleTaskStepParam{
+		TaskBasePath:         taskBasePath,
+		BilingualSrtFilePath: bilingualFile,
+		SubtitleResultType:   types.SubtitleResultTypeBilingualTranslationOnBottom,
+		OriginLanguage:       types.LanguageNameEnglish,
+		TargetLanguage:       types.LanguageNameSimplifiedChinese,
+		UserUILanguage:       types.LanguageNameEnglish,
+		SubtitleFormats:      map[string][]string{"target": {"vtt"}, "bilingual": {"sbv", "json"}},
+		TranscribedWords:     []types.Word{{Text: "Hello", Start: 1.0, End: 1.4}, {Text: "world", Start: 1.5, End: 2.0}, {Text: "later", Start: 5, End: 6}},
+	}
+
+	require.NoError(t, exportSubtitleFormats(stepParam))
+
+	require.Len(t, stepParam.SubtitleInfos, 3)
+	assert.Equal(t, string(types.LanguageNameSimplifiedChinese), stepParam.SubtitleInfos[0].LanguageIdentifier)
+	assert.Equal(t, "bilingual", stepParam.SubtitleInfos[1].LanguageIdentifier)
+	assert.Equal(t, "Bilingual Subtitle (SBV)", stepParam.SubtitleInfos[1].Name)
+
+	vtt, err := os.ReadFile(filepath.Join(taskBasePath, "output", "target_subtitle.vtt"))
+	require.NoError(t, err)
+	assert.Equal(t, "WEBVTT\n\n1\n00:00:01.000 --> 00:00:02.500\n你好世界\n\n", string(vtt))
+	sbv, err := os.ReadFile(filepath.Join(taskBasePath, "output", "bilingual_subtitle.sbv"))
+	require.NoError(t, err)
+	assert.Equal(t, "0:00:01.000,0:00:02.500\nHello world\n你好世界\n\n", string(sbv))
+	timeline, err := os.ReadFile(filepath.Join(taskBasePath, "output", "bilingual_subtitle.json"))
+	require.NoError(t, err)
+	assert.Contains(t, string(timeline), `"text": "world"`)
+	assert.NotContains(t, string(timeline), "later")
+}
+
+func TestBuildSubtitleTrack_AttachesWordsOnlyToOriginTracks(t *testing.T) {
+	stepParam := &types.SubtitleTaskStepParam{
+		SubtitleResultType: types.SubtitleResultTypeBilingualTranslationOnBottom,
+		OriginLanguage:     types.LanguageNameEnglish,
+		TargetLanguage:     types.LanguageNameSimplifiedChinese,
+		TranscribedWords:   []types.Word{{Text: "Hello", Start: 1.0, End: 1.4}, {Text: "world", Start: 1.5, End: 2.0}},
+	}
+	srtBlocks := []*util.SrtBlock{
+		{Index: 1, Timestamp: "00:00:01,000 --> 00:00:02,500", OriginLanguageSentence: "Hello world", TargetLanguageSentence: "你好世界"},
+	}
+
+	target, err := buildSubtitleTrack(stepParam, srtBlocks, subtitleTrackTarget)
+	require.NoError(t, err)
+	require.Len(t, target.Cues, 1)
+	assert.Empty(t, target.Cues[0].Words)
+
+	for _, track := range []string{subtitleTrackOrigin, subtitleTrackBilingual} {
+		result, err := buildSubtitleTrack(stepParam, srtBlocks, track)
+		require.NoError(t, err)
+		require.Len(t, result.Cues, 1)
+		assert.Len(t, result.Cues[0].Words, 2, track)
+	}
+}
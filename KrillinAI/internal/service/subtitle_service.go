@@ -43,9 +43,15 @@ func (s Service) StartSubtitleTask(req dto.StartVideoSubtitleTaskReq) (*dto.Star
 		taskId = strings.ReplaceAll(taskId, "?", "") // 问号影响ffmpeg处理
 	}
 	// 构造任务所需参数
+	// 目标语言可以有多个，第一个为主语言，其余语言复用同一份转录结果单独翻译
+	targetLanguages := parseTargetLanguages(req.TargetLang)
+	targetLanguage := types.StandardLanguageCode("none")
+	if len(targetLanguages) > 0 {
+		targetLanguage = targetLanguages[0]
+	}
 	var resultType types.SubtitleResultType
 	// 根据入参选项确定要返回的字幕类型
-	if req.TargetLang == "none" {
+	if targetLanguage == "none" {
 		resultType = types.SubtitleResultTypeOriginOnly
 	} else {
 		if req.Bilingual == types.SubtitleTaskBilingualYes {
@@ -108,6 +114,8 @@ func (s Service) StartSubtitleTask(req dto.StartVideoSubtitleTaskReq) (*dto.Star
 			taskPtr.TtsVoiceCode = req.TtsVoiceCode // Update voice code if provided
 		}
 	}
+	taskPtr.OriginLanguage = req.OriginLanguage
+	taskPtr.TargetLanguage = joinLanguages(targetLanguages)
 	// Migrate to DB: storage.SubtitleTasks.Store(taskId, taskPtr) -> SaveTask
 	if err := storage.SaveTask(taskPtr); err != nil {
 		log.GetLogger().Error("StartVideoSubtitleTask SaveTask err", zap.Error(err))
@@ -127,6 +135,72 @@ func (s Service) StartSubtitleTask(req dto.StartVideoSubtitleTaskReq) (*dto.Star
 		}
 	}
 
+	// 用户提供文稿时，复制到任务目录，后续按文稿做强制对齐
+	var userTranscriptFilePath string
+	if req.TranscriptFileUrl != "" {
+		if !strings.HasPrefix(req.TranscriptFileUrl, "local:") {
+			return nil, apperrors.New(apperrors.CodeInvalidParams, "文稿地址必须为上传后的 local: 路径 Transcript url must be an uploaded local: path")
+		}
+		userTranscriptFilePath = filepath.Join(taskBasePath, types.SubtitleTaskUserTranscriptFileName)
+		if err = util.CopyFile(strings.TrimPrefix(req.TranscriptFileUrl, "local:"), userTranscriptFilePath); err != nil {
+			log.GetLogger().Error("StartVideoSubtitleTask copy transcript err", zap.Any("req", req), zap.Error(err))
+			return nil, apperrors.Wrap(apperrors.CodeFileNotFound, "读取文稿失败 Failed to read transcript file", err)
+		}
+	}
+
+	// 加载任务关联的术语表
+	glossaryTerms, err := storage.GetGlossaryTerms(req.GlossaryIds)
+	if err != nil {
+		log.GetLogger().Error("StartVideoSubtitleTask GetGlossaryTerms err", zap.Any("glossaryIds", req.GlossaryIds), zap.Error(err))
+		return nil, apperrors.Wrap(apperrors.CodeInvalidParams, "术语表不存在或读取失败 Glossary not found or failed to load", err)
+	}
+
+	// 加载任务关联的替换规则
+	replacementRules, err := storage.GetReplacementRules(req.ReplacementRuleSetIds)
+	if err != nil {
+		log.GetLogger().Error("StartVideoSubtitleTask GetReplacementRules err", zap.Any("replacementRuleSetIds", req.ReplacementRuleSetIds), zap.Error(err))
+		return nil, apperrors.Wrap(apperrors.CodeInvalidParams, "替换规则集不存在或读取失败 Replacement rule set not found or failed to load", err)
+	}
+
+	// 加载任务选用的翻译风格
+	var styleProfile *types.StyleProfile
+	if req.StyleProfileId != 0 {
+		if styleProfile, err = storage.GetStyleProfile(req.StyleProfileId); err != nil {
+			log.GetLogger().Error("StartVideoSubtitleTask GetStyleProfile err", zap.Uint64("styleProfileId", req.StyleProfileId), zap.Error(err))
+			return nil, apperrors.Wrap(apperrors.CodeInvalidParams, "翻译风格不存在或读取失败 Style profile not found or failed to load", err)
+		}
+	}
+
+	// 加载任务选用的字幕样式预设
+	var subtitleStyle *types.SubtitleStyle
+	if req.SubtitleStyleId != 0 {
+		if subtitleStyle, err = storage.GetSubtitleStyle(req.SubtitleStyleId); err != nil {
+			log.GetLogger().Error("StartVideoSubtitleTask GetSubtitleStyle err", zap.Uint64("subtitleStyleId", req.SubtitleStyleId), zap.Error(err))
+			return nil, apperrors.Wrap(apperrors.CodeInvalidParams, "字幕样式不存在或读取失败 Subtitle style not found or failed to load", err)
+		}
+	}
+
+	// 解析任务选用的提示词模板，模板不存在或校验失败时直接拒绝任务
+	promptTemplates, err := resolvePromptTemplates(req.PromptTemplates)
+	if err != nil {
+		log.GetLogger().Error("StartVideoSubtitleTask resolvePromptTemplates err", zap.Any("promptTemplates", req.PromptTemplates), zap.Error(err))
+		return nil, apperrors.Wrap(apperrors.CodeInvalidParams, "提示词模板不存在或校验失败 Prompt template not found or invalid", err)
+	}
+
+	// 校验任务额外导出的字幕格式
+	subtitleFormats, err := parseSubtitleFormats(req.SubtitleFormats)
+	if err != nil {
+		log.GetLogger().Error("StartVideoSubtitleTask parseSubtitleFormats err", zap.Any("subtitleFormats", req.SubtitleFormats), zap.Error(err))
+		return nil, apperrors.Wrap(apperrors.CodeInvalidParams, "字幕导出格式不支持 Unsupported subtitle export format", err)
+	}
+
+	// 校验卡拉OK字幕的模式和高亮颜色
+	karaokeMode, karaokeColor, err := parseKaraokeOptions(req.KaraokeMode, req.KaraokeColor)
+	if err != nil {
+		log.GetLogger().Error("StartVideoSubtitleTask parseKaraokeOptions err", zap.String("karaokeMode", req.KaraokeMode), zap.String("karaokeColor", req.KaraokeColor), zap.Error(err))
+		return nil, apperrors.Wrap(apperrors.CodeInvalidParams, "卡拉OK字幕参数不合法 Invalid karaoke subtitle options", err)
+	}
+
 	stepParam := &types.SubtitleTaskStepParam{
 		TaskId:                  taskId,
 		TaskPtr:                 taskPtr,
@@ -138,13 +212,26 @@ func (s Service) StartSubtitleTask(req dto.StartVideoSubtitleTaskReq) (*dto.Star
 		EnableTts:               req.Tts == types.SubtitleTaskTtsYes,
 		TtsVoiceCode:            req.TtsVoiceCode,
 		ReplaceWordsMap:         replaceWordsMap,
+		ReplacementRules:        replacementRules,
 		OriginLanguage:          types.StandardLanguageCode(req.OriginLanguage),
-		TargetLanguage:          types.StandardLanguageCode(req.TargetLang),
+		TargetLanguage:          targetLanguage,
 		UserUILanguage:          types.StandardLanguageCode(req.Language),
 		EmbedSubtitleVideoType:  req.EmbedSubtitleVideoType,
 		VerticalVideoMajorTitle: req.VerticalMajorTitle,
 		VerticalVideoMinorTitle: req.VerticalMinorTitle,
 		MaxWordOneLine:          12, // 默认值
+		UserTranscriptFilePath:  userTranscriptFilePath,
+		GlossaryTerms:           glossaryTerms,
+		StyleProfile:            styleProfile,
+		TtsVoiceCodes:           req.TtsVoiceCodes,
+		SubtitleFormats:         subtitleFormats,
+		KaraokeMode:             karaokeMode,
+		KaraokeColor:            karaokeColor,
+		SubtitleStyle:           subtitleStyle,
+		PendingMemories:         &types.PendingTranslationMemories{},
+	}
+	if len(targetLanguages) > 1 {
+		stepParam.ExtraTargetLanguages = targetLanguages[1:]
 	}
 	log.GetLogger().Info("StartVideoSubtitleTask stepParam initialized",
 		zap.Bool("EnableTts", stepParam.EnableTts),
@@ -157,6 +244,9 @@ func (s Service) StartSubtitleTask(req dto.StartVideoSubtitleTaskReq) (*dto.Star
 	log.GetLogger().Info("current task info", zap.String("taskId", taskId), zap.Any("param", stepParam))
 
 	go func() {
+		// 任务内的模型调用都记录到该任务的用量
+		s := s.withUsageTracking(taskId)
+		s.PromptTemplates = promptTemplates
 		defer func() {
 			if r := recover(); r != nil {
 				const size = 64 << 10
@@ -240,6 +330,21 @@ func (s Service) StartSubtitleTask(req dto.StartVideoSubtitleTaskReq) (*dto.Star
 			return
 		}
 
+		if len(stepParam.LanguageStepParams) > 0 {
+			stepParam.TaskPtr.StatusMsg = "正在处理其他目标语言 Processing Other Target Languages..."
+			_ = storage.SaveTask(stepParam.TaskPtr)
+		}
+
+		err = s.generateExtraLanguageMedia(ctx, stepParam)
+		if err != nil {
+			log.GetLogger().Error("StartVideoSubtitleTask generateExtraLanguageMedia err", zap.Any("req", req), zap.Error(err))
+			stepParam.TaskPtr.Status = types.SubtitleTaskStatusFailed
+			stepParam.TaskPtr.FailReason = err.Error()
+			stepParam.TaskPtr.StatusMsg = "其他目标语言处理失败 Other Target Languages Failed"
+			_ = storage.SaveTask(stepParam.TaskPtr)
+			return
+		}
+
 		stepParam.TaskPtr.StatusMsg = "正在完成 Finalizing..."
 		_ = storage.SaveTask(stepParam.TaskPtr)
 
@@ -286,9 +391,12 @@ func (s Service) GetTaskStatus(req dto.GetVideoSubtitleTaskReq) (*dto.GetVideoSu
 			return &dto.SubtitleInfo{
 				Name:        item.Name,
 				DownloadUrl: item.DownloadUrl,
+				Language:    item.Language,
 			}
 		}),
 		TargetLanguage:    taskPtr.TargetLanguage,
+		TargetLanguages:   dto.NewLanguageList(taskPtr.TargetLanguage),
 		SpeechDownloadUrl: taskPtr.SpeechDownloadUrl,
+		Usage:             taskUsageSummary(taskPtr.TaskId),
 	}, nil
 }
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"krillin-ai/config"
+	"krillin-ai/internal/types"
+	"krillin-ai/log"
+	"krillin-ai/pkg/subtitle"
+	"krillin-ai/pkg/util"
+
+	"go.uber.org/zap"
+)
+
+// subtitleTimingOptions 把 subtitle_timing 配置换算为时间轴规整参数，双语轨道中译文是主文本、原文是副文本
+func subtitleTimingOptions(stepParam *types.SubtitleTaskStepParam) subtitle.TimingOptions {
+	timing := config.Conf.SubtitleTiming
+	seconds := func(value float64) time.Duration {
+		return time.Duration(value * float64(time.Second))
+	}
+	maxCps := func(language types.StandardLanguageCode) float64 {
+		if util.IsAsianLanguage(language) {
+			return timing.MaxCpsAsian
+		}
+		return timing.MaxCps
+	}
+	return subtitle.TimingOptions{
+		MinDuration:   seconds(timing.MinDuration),
+		MaxDuration:   seconds(timing.MaxDuration),
+		MinGap:        seconds(timing.MinGap),
+		MergeUnder:    seconds(timing.MergeUnder),
+		MergeMaxGap:   seconds(timing.MergeMaxGap),
+		FillGap:       seconds(timing.FillGap),
+		ChainGap:      seconds(timing.ChainGap),
+		MaxCps:        maxCps(stepParam.TargetLanguage),
+		MaxSubTextCps: maxCps(stepParam.OriginLanguage),
+		SnapTolerance: seconds(timing.SnapTolerance),
+	}
+}
+
+// normalizeSubtitleTiming 规整双语字幕的时间轴，在拆分单语字幕之前执行，配音、嵌入视频和导出都使用规整后的时间轴
+func normalizeSubtitleTiming(stepParam *types.SubtitleTaskStepParam) error {
+	if !config.Conf.SubtitleTiming.Enabled {
+		return nil
+	}
+	srtBlocks, err := readBilingualSrtFile(stepParam.BilingualSrtFilePath, stepParam.SubtitleResultType)
+	if err != nil {
+		return fmt.Errorf("normalizeSubtitleTiming %w", err)
+	}
+	track, err := buildSubtitleTrack(stepParam, srtBlocks, subtitleTrackBilingual)
+	if err != nil {
+		return fmt.Errorf("normalizeSubtitleTiming %w", err)
+	}
+
+	opts := subtitleTimingOptions(stepParam)
+	opts.ShotChanges, opts.CutGap = shotChangeTiming(stepParam.InputVideoPath)
+	cues := subtitle.NormalizeTiming(track.Cues, opts)
+	normalized := make([]*util.SrtBlock, 0, len(cues))
+	for i, cue := range cues {
+		normalized = append(normalized, &util.SrtBlock{
+			Index:                  i + 1,
+			Timestamp:              fmt.Sprintf("%s --> %s", subtitle.SrtTimestamp(cue.Start), subtitle.SrtTimestamp(cue.End)),
+			OriginLanguageSentence: cue.SubText,
+			TargetLanguageSentence: cue.Text,
+		})
+	}
+	if err = writeBilingualSrtFile(stepParam.BilingualSrtFilePath, normalized, stepParam.SubtitleResultType); err != nil {
+		return fmt.Errorf("normalizeSubtitleTiming %w", err)
+	}
+	log.GetLogger().Info("normalizeSubtitleTiming completed", zap.Any("taskId", stepParam.TaskId), zap.Int("before", len(srtBlocks)), zap.Int("after", len(normalized)))
+	return nil
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"krillin-ai/config"
+	"krillin-ai/internal/types"
+	"krillin-ai/pkg/util"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeSubtitleTiming(t *testing.T) {
+	oldTiming := config.Conf.SubtitleTiming
+	defer func() { config.Conf.SubtitleTiming = oldTiming }()
+	config.Conf.SubtitleTiming = config.SubtitleTimingConfig{Enabled: true, MinDuration: 1, MinGap: 0.1, MergeUnder: 0.3, MergeMaxGap: 0.2, MaxCpsAsian: 5}
+
+	bilingualFile := filepath.Join(t.TempDir(), types.SubtitleTaskBilingualSrtFileName)
+	resultType := types.SubtitleResultTypeBilingualTranslationOnTop
+	require.NoError(t, writeBilingualSrtFile(bilingualFile, []*util.SrtBlock{
+		{Index: 1, Timestamp: "00:00:01,000 --> 00:00:02,000", OriginLanguageSentence: "Hello", TargetLanguageSentence: "你好"},
+		{Index: 2, Timestamp: "00:00:02,050 --> 00:00:02,250", OriginLanguageSentence: "world", TargetLanguageSentence: "世界"},
+		{Index: 3, Timestamp: "00:00:03,000 --> 00:00:03,500", OriginLanguageSentence: "Nice to meet you", TargetLanguageSentence: "很高兴认识你们"},
+	}, resultType))
+	stepParam := &types.SubtitleTaskStepParam{
+		BilingualSrtFilePath: bilingualFile,
+		SubtitleResultType:   resultType,
+		OriginLanguage:       types.LanguageNameEnglish,
+		TargetLanguage:       types.LanguageNameSimplifiedChinese,
+	}
+
+	require.NoError(t, normalizeSubtitleTiming(stepParam))
+
+	srtBlocks, err := readBilingualSrtFile(bilingualFile, resultType)
+	require.NoError(t, err)
+	assert.Equal(t, []*util.SrtBlock{
+		{Index: 1, Timestamp: "00:00:01,000 --> 00:00:02,250", OriginLanguageSentence: "Hello world", TargetLanguageSentence: "你好世界"},
+		// 7个字按每秒5字需要1.4秒
+		{Index: 2, Timestamp: "00:00:03,000 --> 00:00:04,400", OriginLanguageSentence: "Nice to meet you", TargetLanguageSentence: "很高兴认识你们"},
+	}, srtBlocks)
+}
+
+func TestParseShotChanges(t *testing.T) {
+	output := `Input #0, mov,mp4,m4a,3gp,3g2,mj2, from 'input.mp4':
+[Parsed_showinfo_1 @ 0x600000b04000] n:   0 pts:  30720 pts_time:2.4     duration:    512 fmt:yuv420p
+[Parsed_showinfo_1 @ 0x600000b04000] n:   1 pts: 153600 pts_time:12.04   duration:    512 fmt:yuv420p
+frame=    2 fps=0.0 q=-0.0 Lsize=N/A time=00:00:12.04 bitrate=N/A speed=  40x`
+	assert.Equal(t, []time.Duration{2400 * time.Millisecond, 12040 * time.Millisecond}, parseShotChanges(output))
+
+	frameRate, err := parseFrameRate("30000/1001\n")
+	require.NoError(t, err)
+	assert.InDelta(t, 29.97, frameRate, 0.001)
+	frameRate, err = parseFrameRate("25")
+	require.NoError(t, err)
+	assert.InDelta(t, 25, frameRate, 0.001)
+	_, err = parseFrameRate("0/0")
+	assert.Error(t, err)
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"krillin-ai/internal/storage"
+	"krillin-ai/internal/types"
+	"krillin-ai/log"
+
+	"go.uber.org/zap"
+)
+
+// TMX 1.4 翻译记忆交换格式的导入导出。导出时每条记忆一个 tu，包含原文和译文两个 tuv；
+// 导入时以 header 或 tu 的 srclang 作为原文，其余每个 tuv 各生成一条记忆，未指定 srclang 时取第一个 tuv
+
+type tmxDocument struct {
+	XMLName xml.Name  `xml:"tmx"`
+	Version string    `xml:"version,attr"`
+	Header  tmxHeader `xml:"header"`
+	Units   []tmxUnit `xml:"body>tu"`
+}
+
+type tmxHeader struct {
+	CreationTool        string `xml:"creationtool,attr"`
+	CreationToolVersion string `xml:"creationtoolversion,attr"`
+	SegType             string `xml:"segtype,attr"`
+	OTmf                string `xml:"o-tmf,attr"`
+	AdminLang           string `xml:"adminlang,attr"`
+	SrcLang             string `xml:"srclang,attr"`
+	DataType            string `xml:"datatype,attr"`
+}
+
+type tmxUnit struct {
+	SrcLang  string       `xml:"srclang,attr,omitempty"`
+	Variants []tmxVariant `xml:"tuv"`
+}
+
+type tmxVariant struct {
+	Lang string `xml:"lang,attr"` // 同时匹配 TMX 1.4 的 xml:lang 和 TMX 1.1 的 lang
+	Seg  string `xml:"seg"`
+}
+
+// 导出专用结构，encoding/xml 无法直接输出 xml:lang 前缀
+type tmxExportDocument struct {
+	XMLName xml.Name        `xml:"tmx"`
+	Version string          `xml:"version,attr"`
+	Header  tmxHeader       `xml:"header"`
+	Units   []tmxExportUnit `xml:"body>tu"`
+}
+
+type tmxExportUnit struct {
+	SrcLang  string             `xml:"srclang,attr"`
+	Variants []tmxExportVariant `xml:"tuv"`
+}
+
+type tmxExportVariant struct {
+	Lang string `xml:"xml:lang,attr"`
+	Seg  string `xml:"seg"`
+}
+
+// ExportTranslationMemoryTmx 导出翻译记忆为 TMX，语言为空时不过滤。按翻译风格保存的译文只在同一风格的任务中复用，不导出
+func (s Service) ExportTranslationMemoryTmx(sourceLang, targetLang string) ([]byte, int, error) {
+	entries, err := storage.ListTranslationMemories(sourceLang, targetLang)
+	if err != nil {
+		return nil, 0, fmt.Errorf("ExportTranslationMemoryTmx ListTranslationMemories err: %w", err)
+	}
+
+	doc := tmxExportDocument{
+		Version: "1.4",
+		Header: tmxHeader{
+			CreationTool:        "KrillinAI",
+			CreationToolVersion: "1.0",
+			SegType:             "sentence",
+			OTmf:                "KrillinAI",
+			AdminLang:           "en",
+			SrcLang:             "*all*",
+			DataType:            "plaintext",
+		},
+		Units: make([]tmxExportUnit, 0, len(entries)),
+	}
+	for _, entry := range entries {
+		if entry.StyleProfileId != 0 {
+			continue
+		}
+		sourceTag := codeToTmxLang(entry.SourceLanguage)
+		doc.Units = append(doc.Units, tmxExportUnit{
+			SrcLang: sourceTag,
+			Variants: []tmxExportVariant{
+				{Lang: sourceTag, Seg: entry.SourceText},
+				{Lang: codeToTmxLang(entry.TargetLanguage), Seg: entry.TargetText},
+			},
+		})
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	encoder := xml.NewEncoder(&buf)
+	encoder.Indent("", "  ")
+	if err = encoder.Encode(doc); err != nil {
+		return nil, 0, fmt.Errorf("ExportTranslationMemoryTmx encode err: %w", err)
+	}
+	buf.WriteString("\n")
+	return buf.Bytes(), len(doc.Units), nil
+}
+
+// ImportTranslationMemoryTmx 导入 TMX，已存在的原文和目标语言组合会被覆盖，返回导入条数
+func (s Service) ImportTranslationMemoryTmx(data []byte) (int, error) {
+	var doc tmxDocument
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return 0, fmt.Errorf("ImportTranslationMemoryTmx unmarshal err: %w", err)
+	}
+
+	entries := make([]types.TranslationMemory, 0, len(doc.Units))
+	for _, unit := range doc.Units {
+		entries = append(entries, tmxUnitToMemories(unit, doc.Header.SrcLang)...)
+	}
+	if len(entries) == 0 {
+		return 0, nil
+	}
+	if err := storage.SaveTranslationMemories(entries); err != nil {
+		return 0, fmt.Errorf("ImportTranslationMemoryTmx SaveTranslationMemories err: %w", err)
+	}
+	log.GetLogger().Info("ImportTranslationMemoryTmx completed", zap.Int("units", len(doc.Units)), zap.Int("entries", len(entries)))
+	return len(entries), nil
+}
+
+func tmxUnitToMemories(unit tmxUnit, headerSrcLang string) []types.TranslationMemory {
+	if len(unit.Variants) < 2 {
+		return nil
+	}
+	srcLang := unit.SrcLang
+	if srcLang == "" {
+		srcLang = headerSrcLang
+	}
+	sourceIdx := 0
+	for i, variant := range unit.Variants {
+		if srcLang != "" && srcLang != "*all*" && strings.EqualFold(variant.Lang, srcLang) {
+			sourceIdx = i
+			break
+		}
+	}
+
+	source := unit.Variants[sourceIdx]
+	key := normalizeMemoryKey(source.Seg)
+	if key == "" {
+		return nil
+	}
+	entries := make([]types.TranslationMemory, 0, len(unit.Variants)-1)
+	for i, variant := range unit.Variants {
+		if i == sourceIdx || strings.TrimSpace(variant.Seg) == "" {
+			continue
+		}
+		entries = append(entries, types.TranslationMemory{
+			SourceKey:      key,
+			TargetLanguage: tmxLangToCode(variant.Lang),
+			SourceLanguage: tmxLangToCode(source.Lang),
+			SourceText:     strings.TrimSpace(source.Seg),
+			TargetText:     strings.TrimSpace(variant.Seg),
+			SourceLength:   len([]rune(key)),
+		})
+	}
+	return entries
+}
+
+// codeToTmxLang 内部语言代码转为 TMX 使用的 BCP 47 标签，如 zh_cn -> zh-CN
+func codeToTmxLang(code string) string {
+	parts := strings.SplitN(code, "_", 2)
+	if len(parts) == 2 {
+		return parts[0] + "-" + strings.ToUpper(parts[1])
+	}
+	return code
+}
+
+// tmxLangToCode BCP 47 标签转为内部语言代码，如 zh-CN -> zh_cn
+func tmxLangToCode(lang string) string {
+	return strings.ToLower(strings.ReplaceAll(lang, "-", "_"))
+}
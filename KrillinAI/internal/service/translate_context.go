@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"krillin-ai/config"
+	"krillin-ai/internal/types"
+	"krillin-ai/log"
+	"krillin-ai/pkg/util"
+
+	"go.uber.org/zap"
+)
+
+// 携带上下文的批量翻译：每批翻译时附上前 N 行原文及已采用的译文（以及可选的内容摘要），
+// 避免代词、称呼和术语在批次边界处前后不一致。需要前一批的结果，因此该模式下逐批翻译
+
+// translateContextEnabled 是否开启携带上下文的批量翻译
+func translateContextEnabled() bool {
+	return config.Conf.App.TranslateContextLines > 0
+}
+
+// precedingContextLines 取 before 之前最近的至多 n 行已采用的译文，按原顺序返回
+func precedingContextLines(results []*TranslatedItem, accepted []bool, before, n int) []*TranslatedItem {
+	lines := make([]*TranslatedItem, 0, n)
+	for i := before - 1; i >= 0 && len(lines) < n; i-- {
+		if accepted[i] && results[i] != nil {
+			lines = append(lines, results[i])
+		}
+	}
+	for i, j := 0, len(lines)-1; i < j; i, j = i+1, j-1 {
+		lines[i], lines[j] = lines[j], lines[i]
+	}
+	return lines
+}
+
+// buildTranslateContextPrompt 生成附加在批量翻译 prompt 中的上下文，没有前文和摘要时返回空
+func buildTranslateContextPrompt(lines []*TranslatedItem, summary string) string {
+	if len(lines) == 0 && summary == "" {
+		return ""
+	}
+	var builder strings.Builder
+	if summary != "" {
+		builder.WriteString("Summary of the video so far: ")
+		builder.WriteString(summary)
+		builder.WriteString("\n")
+	}
+	if len(lines) > 0 {
+		builder.WriteString("Previous lines and their accepted translations:\n")
+		for _, line := range lines {
+			builder.WriteString(fmt.Sprintf("- %q => %q\n", line.OriginText, line.TranslatedText))
+		}
+	}
+	return fmt.Sprintf(types.BatchTranslateContextPrompt, strings.TrimSuffix(builder.String(), "\n"))
+}
+
+// updateRunningSummary 结合新翻译的原文更新内容摘要，失败时保留原摘要
+func (s Service) updateRunningSummary(ctx context.Context, summary string, lines []string) string {
+	if !config.Conf.App.RunningSummary || len(lines) == 0 {
+		return summary
+	}
+	current := summary
+	if current == "" {
+		current = "(empty)"
+	}
+	linesBytes, _ := json.Marshal(lines)
+	updated, err := s.chat(ctx, llmCallSummary, s.renderPrompt(promptKindRunningSummary, runningSummaryPromptVars{Summary: current, Lines: string(linesBytes)}))
+	if err != nil {
+		log.GetLogger().Warn("updateRunningSummary failed, keep previous summary", zap.Error(err))
+		return summary
+	}
+	updated = strings.TrimSpace(util.CleanMarkdownCodeBlock(updated))
+	if updated == "" {
+		return summary
+	}
+	return updated
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"krillin-ai/config"
+	"krillin-ai/internal/mocks"
+	"krillin-ai/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTranslateSentences_CarriesPreviousLinesAndSummary(t *testing.T) {
+	oldApp := config.Conf.App
+	oldMemory := config.Conf.TranslationMemory.Enabled
+	config.Conf.App.TranslateContextLines = 2
+	config.Conf.App.RunningSummary = true
+	config.Conf.App.TranslateMaxAttempts = 1
+	config.Conf.TranslationMemory.Enabled = false
+	defer func() {
+		config.Conf.App = oldApp
+		config.Conf.TranslationMemory.Enabled = oldMemory
+	}()
+
+	sentences := make([]string, 0, 21)
+	firstBatch := make([]string, 0, 20)
+	for i := 1; i <= 21; i++ {
+		sentences = append(sentences, fmt.Sprintf("line %d", i))
+		if i <= 20 {
+			firstBatch = append(firstBatch, fmt.Sprintf("译%d", i))
+		}
+	}
+	firstBatchJson, _ := json.Marshal(firstBatch)
+
+	mockChatCompleter := new(mocks.MockChatCompleter)
+	mockChatCompleter.On("ChatCompletion", mock.MatchedBy(func(prompt string) bool {
+		return strings.Contains(prompt, "BATCH TRANSLATION TASK") && !strings.Contains(prompt, "Context (read-only)")
+	})).Return(string(firstBatchJson), nil).Once()
+	mockChatCompleter.On("ChatCompletion", mock.MatchedBy(func(prompt string) bool {
+		return strings.Contains(prompt, "running summary") && strings.Contains(prompt, "(empty)")
+	})).Return("Someone counts lines.", nil).Once()
+	mockChatCompleter.On("ChatCompletion", mock.MatchedBy(func(prompt string) bool {
+		return strings.Contains(prompt, "Context (read-only)") &&
+			strings.Contains(prompt, "Summary of the video so far: Someone counts lines.") &&
+			strings.Contains(prompt, `"line 19" => "译19"`) &&
+			strings.Contains(prompt, `"line 20" => "译20"`) &&
+			!strings.Contains(prompt, `"line 18" =>`)
+	})).Return(`["译21"]`, nil).Once()
+	mockChatCompleter.On("ChatCompletion", mock.MatchedBy(func(prompt string) bool {
+		return strings.Contains(prompt, "running summary") && strings.Contains(prompt, "Someone counts lines.")
+	})).Return("Someone counts 21 lines.", nil).Once()
+	svc := Service{ChatCompleter: mockChatCompleter}
+
+	results := svc.translateSentences(context.Background(), &types.SubtitleTaskStepParam{}, sentences, types.LanguageNameSimplifiedChinese, 0)
+
+	require.Len(t, results, 21)
+	assert.Equal(t, "译1", results[0].TranslatedText)
+	assert.Equal(t, "译21", results[20].TranslatedText)
+	mockChatCompleter.AssertExpectations(t)
+}
+
+func TestPrecedingContextLines_SkipsRejectedLines(t *testing.T) {
+	results := []*TranslatedItem{
+		{OriginText: "a", TranslatedText: "甲"},
+		{OriginText: "b", TranslatedText: "b"},
+		{OriginText: "c", TranslatedText: "丙"},
+		nil,
+	}
+	accepted := []bool{true, false, true, false}
+
+	lines := precedingContextLines(results, accepted, 3, 2)
+
+	require.Len(t, lines, 2)
+	assert.Equal(t, "a", lines[0].OriginText)
+	assert.Equal(t, "c", lines[1].OriginText)
+}
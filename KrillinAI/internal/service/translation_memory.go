@@ -0,0 +1,225 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"krillin-ai/config"
+	"krillin-ai/internal/storage"
+	"krillin-ai/internal/types"
+	"krillin-ai/log"
+
+	"github.com/texttheater/golang-levenshtein/levenshtein"
+	"go.uber.org/zap"
+)
+
+// 翻译记忆：完全相同的句子直接复用历史译文不再调用大模型，相似句子的历史译文作为参考放进 prompt
+
+const memoryCandidateLimit = 200 // 模糊匹配时每个句子最多比较的候选记录数
+
+// normalizeMemoryKey 归一化原文作为精确匹配的键，只合并多余空白，不改变大小写和标点
+func normalizeMemoryKey(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// memoryStyleProfileId 任务选用的翻译风格，翻译记忆按风格区分
+func memoryStyleProfileId(stepParam *types.SubtitleTaskStepParam) uint64 {
+	if stepParam.StyleProfile == nil {
+		return 0
+	}
+	return stepParam.StyleProfile.Id
+}
+
+// lookupTranslationMemory 精确匹配翻译记忆，返回句子下标到译文的映射，查询失败时当作没有命中
+func lookupTranslationMemory(sentences []string, targetLang types.StandardLanguageCode, styleProfileId uint64) map[int]string {
+	hits := make(map[int]string)
+	if !config.Conf.TranslationMemory.Enabled || len(sentences) == 0 {
+		return hits
+	}
+	keys := make([]string, 0, len(sentences))
+	for _, sentence := range sentences {
+		keys = append(keys, normalizeMemoryKey(sentence))
+	}
+	entries, err := storage.FindTranslationMemories(keys, string(targetLang), styleProfileId)
+	if err != nil {
+		log.GetLogger().Warn("lookupTranslationMemory FindTranslationMemories err", zap.Error(err))
+		return hits
+	}
+	for i, key := range keys {
+		if entry, ok := entries[key]; ok && key != "" {
+			hits[i] = entry.TargetText
+		}
+	}
+	return hits
+}
+
+// findFuzzyMemories 为一批句子查找相似度不低于阈值的历史译文，按相似度从高到低最多返回 MaxFuzzyMatches 条
+func findFuzzyMemories(sentences []string, targetLang types.StandardLanguageCode, styleProfileId uint64) []types.TranslationMemory {
+	memoryConf := config.Conf.TranslationMemory
+	if !memoryConf.Enabled || memoryConf.MaxFuzzyMatches <= 0 || memoryConf.FuzzyThreshold <= 0 {
+		return nil
+	}
+
+	scores := make(map[uint64]float64)
+	candidates := make(map[uint64]types.TranslationMemory)
+	for _, sentence := range sentences {
+		key := normalizeMemoryKey(sentence)
+		length := len([]rune(key))
+		if length == 0 {
+			continue
+		}
+		// 相似度 = 1 - 编辑距离/较长串长度，达到阈值时两者长度之比不会低于阈值
+		minLength := int(math.Ceil(float64(length) * memoryConf.FuzzyThreshold))
+		maxLength := int(math.Floor(float64(length) / memoryConf.FuzzyThreshold))
+		entries, err := storage.ListTranslationMemoryCandidates(string(targetLang), styleProfileId, minLength, maxLength, memoryCandidateLimit)
+		if err != nil {
+			log.GetLogger().Warn("findFuzzyMemories ListTranslationMemoryCandidates err", zap.Error(err))
+			return nil
+		}
+		for _, entry := range entries {
+			score := memorySimilarity(key, entry.SourceKey)
+			if score < memoryConf.FuzzyThreshold || score <= scores[entry.Id] {
+				continue
+			}
+			scores[entry.Id] = score
+			candidates[entry.Id] = entry
+		}
+	}
+
+	matched := make([]types.TranslationMemory, 0, len(candidates))
+	for _, entry := range candidates {
+		matched = append(matched, entry)
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		if scores[matched[i].Id] != scores[matched[j].Id] {
+			return scores[matched[i].Id] > scores[matched[j].Id]
+		}
+		return matched[i].Id < matched[j].Id
+	})
+	if len(matched) > memoryConf.MaxFuzzyMatches {
+		matched = matched[:memoryConf.MaxFuzzyMatches]
+	}
+	return matched
+}
+
+// memorySimilarity 基于编辑距离的相似度，取值 0~1
+func memorySimilarity(a, b string) float64 {
+	runesA, runesB := []rune(strings.ToLower(a)), []rune(strings.ToLower(b))
+	maxLength := max(len(runesA), len(runesB))
+	if maxLength == 0 {
+		return 1
+	}
+	distance := levenshtein.DistanceForStrings(runesA, runesB, levenshtein.Options{
+		InsCost: 1,
+		DelCost: 1,
+		SubCost: 1,
+		Matches: levenshtein.IdenticalRunes,
+	})
+	return 1 - float64(distance)/float64(maxLength)
+}
+
+// buildMemoryPrompt 生成附加在批量翻译 prompt 中的参考译文，没有参考时返回空
+func buildMemoryPrompt(entries []types.TranslationMemory) string {
+	if len(entries) == 0 {
+		return ""
+	}
+	lines := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		lines = append(lines, fmt.Sprintf("- %q => %q", entry.SourceText, entry.TargetText))
+	}
+	return fmt.Sprintf(types.BatchTranslateMemoryPrompt, strings.Join(lines, "\n"))
+}
+
+// queueTranslationMemory 暂存成功翻译的句子，等质检之后再写入翻译记忆
+func queueTranslationMemory(stepParam *types.SubtitleTaskStepParam, targetLang types.StandardLanguageCode, items []*TranslatedItem) {
+	if !config.Conf.TranslationMemory.Enabled || stepParam.PendingMemories == nil || len(items) == 0 {
+		return
+	}
+	entries := make([]types.TranslationMemory, 0, len(items))
+	seen := make(map[string]bool)
+	for _, item := range items {
+		key := normalizeMemoryKey(item.OriginText)
+		if key == "" || strings.TrimSpace(item.TranslatedText) == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		entries = append(entries, types.TranslationMemory{
+			SourceKey:      key,
+			TargetLanguage: string(targetLang),
+			StyleProfileId: memoryStyleProfileId(stepParam),
+			SourceLanguage: string(stepParam.OriginLanguage),
+			SourceText:     item.OriginText,
+			TargetText:     item.TranslatedText,
+			SourceLength:   len([]rune(key)),
+			TaskId:         stepParam.TaskId,
+		})
+	}
+	stepParam.PendingMemories.Add(entries...)
+}
+
+// saveTranslationMemory 把暂存的译文写入翻译记忆，质检不合格的字幕（包括重译过的）所在的句子不写入，
+// report 为 nil 表示没有做质检。失败只记录日志
+func saveTranslationMemory(stepParam *types.SubtitleTaskStepParam, report *TranslationQaReport) {
+	if !config.Conf.TranslationMemory.Enabled || stepParam.PendingMemories == nil {
+		return
+	}
+	pending := stepParam.PendingMemories.Take()
+	var rejectedKeys []string
+	if report != nil {
+		for _, cue := range report.Cues {
+			if key := normalizeMemoryKey(cue.OriginText); key != "" {
+				rejectedKeys = append(rejectedKeys, key)
+			}
+		}
+	}
+	entries := make([]types.TranslationMemory, 0, len(pending))
+	for _, entry := range pending {
+		// 翻译之后长句可能被拆成多条字幕，任意一条不合格整句都不写入
+		rejected := false
+		for _, key := range rejectedKeys {
+			if containsWordRun(entry.SourceKey, key) {
+				rejected = true
+				break
+			}
+		}
+		if !rejected {
+			entries = append(entries, entry)
+		}
+	}
+	if len(entries) == 0 {
+		return
+	}
+	if err := storage.SaveTranslationMemories(entries); err != nil {
+		log.GetLogger().Warn("saveTranslationMemory SaveTranslationMemories err", zap.Any("taskId", stepParam.TaskId), zap.Error(err))
+		return
+	}
+	log.GetLogger().Info("saveTranslationMemory completed", zap.Any("taskId", stepParam.TaskId), zap.Int("entries", len(entries)), zap.Int("rejected", len(pending)-len(entries)))
+}
+
+// containsWordRun sentence 中是否有一段完整的词与 key 相同，避免 "yes" 这样的短字幕匹配到 "yesterday"。
+// 中日泰文字之间没有空格分词，相邻的这类字符视为词的边界
+func containsWordRun(sentence, key string) bool {
+	for offset := 0; offset <= len(sentence)-len(key); {
+		index := strings.Index(sentence[offset:], key)
+		if index < 0 {
+			return false
+		}
+		start, end := offset+index, offset+index+len(key)
+		before, _ := utf8.DecodeLastRuneInString(sentence[:start])
+		after, _ := utf8.DecodeRuneInString(sentence[end:])
+		if (start == 0 || isWordBoundaryRune(before)) && (end == len(sentence) || isWordBoundaryRune(after)) {
+			return true
+		}
+		_, size := utf8.DecodeRuneInString(sentence[start:])
+		offset = start + size
+	}
+	return false
+}
+
+func isWordBoundaryRune(r rune) bool {
+	return (!unicode.IsLetter(r) && !unicode.IsDigit(r)) || unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Thai)
+}
@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"krillin-ai/config"
+	"krillin-ai/internal/mocks"
+	"krillin-ai/internal/storage"
+	"krillin-ai/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// setupTestDB 把 storage.DB 换成临时的 sqlite 数据库，只迁移传入的表，测试结束后恢复
+func setupTestDB(t *testing.T, models ...any) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(models...))
+	originalDB := storage.DB
+	storage.DB = db
+	t.Cleanup(func() { storage.DB = originalDB })
+}
+
+func setupTranslationMemoryTestDB(t *testing.T) {
+	t.Helper()
+	setupTestDB(t, &types.TranslationMemory{})
+}
+
+// enableTranslationMemory 翻译记忆默认关闭，测试期间开启
+func enableTranslationMemory(t *testing.T) {
+	t.Helper()
+	oldEnabled := config.Conf.TranslationMemory.Enabled
+	config.Conf.TranslationMemory.Enabled = true
+	t.Cleanup(func() { config.Conf.TranslationMemory.Enabled = oldEnabled })
+}
+
+func TestTranslateSentences_UsesTranslationMemory(t *testing.T) {
+	setupTranslationMemoryTestDB(t)
+	enableTranslationMemory(t)
+	require.NoError(t, storage.SaveTranslationMemories([]types.TranslationMemory{
+		{SourceKey: "Thanks for watching!", TargetLanguage: "zh_cn", SourceLanguage: "en", SourceText: "Thanks for watching!", TargetText: "感谢观看！", SourceLength: 20},
+		{SourceKey: "This video is sponsored by Acme.", TargetLanguage: "zh_cn", SourceLanguage: "en", SourceText: "This video is sponsored by Acme.", TargetText: "本视频由Acme赞助。", SourceLength: 32},
+	}))
+
+	mockChatCompleter := new(mocks.MockChatCompleter)
+	mockChatCompleter.On("ChatCompletion", mock.MatchedBy(func(prompt string) bool {
+		return strings.Contains(prompt, `"This video is sponsored by Acme." => "本视频由Acme赞助。"`) &&
+			!strings.Contains(prompt, `"Thanks for watching!",`)
+	})).Return(`["本视频由Globex赞助。"]`, nil).Once()
+	svc := Service{ChatCompleter: mockChatCompleter}
+	stepParam := &types.SubtitleTaskStepParam{TaskId: "task_1", OriginLanguage: types.LanguageNameEnglish, PendingMemories: &types.PendingTranslationMemories{}}
+
+	results := svc.translateSentences(context.Background(), stepParam, []string{"Thanks for watching!", "This video is sponsored by Globex."}, types.LanguageNameSimplifiedChinese, 0)
+
+	require.Len(t, results, 2)
+	assert.Equal(t, "感谢观看！", results[0].TranslatedText)
+	assert.Equal(t, "本视频由Globex赞助。", results[1].TranslatedText)
+	mockChatCompleter.AssertExpectations(t)
+
+	// 质检之前不写入
+	saved, err := storage.FindTranslationMemories([]string{"This video is sponsored by Globex."}, "zh_cn", 0)
+	require.NoError(t, err)
+	assert.Empty(t, saved)
+
+	saveTranslationMemory(stepParam, nil)
+	saved, err = storage.FindTranslationMemories([]string{"This video is sponsored by Globex."}, "zh_cn", 0)
+	require.NoError(t, err)
+	assert.Equal(t, "task_1", saved["This video is sponsored by Globex."].TaskId)
+}
+
+func TestTranslateSentences_TranslationMemoryIsPerStyleProfile(t *testing.T) {
+	setupTranslationMemoryTestDB(t)
+	enableTranslationMemory(t)
+	require.NoError(t, storage.SaveTranslationMemories([]types.TranslationMemory{
+		{SourceKey: "Thanks for watching!", TargetLanguage: "zh_cn", SourceText: "Thanks for watching!", TargetText: "感谢观看！", SourceLength: 20},
+		{SourceKey: "See you next time.", TargetLanguage: "zh_cn", StyleProfileId: 7, SourceText: "See you next time.", TargetText: "下回见啦～", SourceLength: 18},
+	}))
+
+	// 未指定风格时保存的译文不用于选了风格的任务
+	mockChatCompleter := new(mocks.MockChatCompleter)
+	mockChatCompleter.On("ChatCompletion", mock.MatchedBy(func(prompt string) bool {
+		return strings.Contains(prompt, `["Thanks for watching!"]`)
+	})).Return(`["感谢收看，家人们！"]`, nil).Once()
+	svc := Service{ChatCompleter: mockChatCompleter}
+	stepParam := &types.SubtitleTaskStepParam{TaskId: "task_1", StyleProfile: &types.StyleProfile{Id: 7, Tone: "casual"}, PendingMemories: &types.PendingTranslationMemories{}}
+
+	results := svc.translateSentences(context.Background(), stepParam, []string{"Thanks for watching!", "See you next time."}, types.LanguageNameSimplifiedChinese, 0)
+
+	require.Len(t, results, 2)
+	assert.Equal(t, "感谢收看，家人们！", results[0].TranslatedText)
+	assert.Equal(t, "下回见啦～", results[1].TranslatedText)
+	mockChatCompleter.AssertExpectations(t)
+
+	saveTranslationMemory(stepParam, nil)
+	plain, err := storage.FindTranslationMemories([]string{"Thanks for watching!"}, "zh_cn", 0)
+	require.NoError(t, err)
+	assert.Equal(t, "感谢观看！", plain["Thanks for watching!"].TargetText)
+	styled, err := storage.FindTranslationMemories([]string{"Thanks for watching!"}, "zh_cn", 7)
+	require.NoError(t, err)
+	assert.Equal(t, "感谢收看，家人们！", styled["Thanks for watching!"].TargetText)
+}
+
+func TestSaveTranslationMemory_SkipsQaRejectedCues(t *testing.T) {
+	setupTranslationMemoryTestDB(t)
+	enableTranslationMemory(t)
+	stepParam := &types.SubtitleTaskStepParam{TaskId: "task_1", OriginLanguage: types.LanguageNameEnglish, PendingMemories: &types.PendingTranslationMemories{}}
+	queueTranslationMemory(stepParam, types.LanguageNameSimplifiedChinese, []*TranslatedItem{
+		{OriginText: "Welcome back to the channel.", TranslatedText: "欢迎回到频道。"},
+		{OriginText: "Today we are going to build a  rocket, and then we launch it.", TranslatedText: "今天我们造火箭。"},
+	})
+	// 长句拆成两条字幕后其中一条重译过
+	report := &TranslationQaReport{Cues: []TranslationQaCue{
+		{OriginText: "and then we launch it.", Status: qaStatusRepaired},
+	}}
+
+	saveTranslationMemory(stepParam, report)
+
+	saved, err := storage.FindTranslationMemories([]string{"Welcome back to the channel.", "Today we are going to build a rocket, and then we launch it."}, "zh_cn", 0)
+	require.NoError(t, err)
+	assert.Len(t, saved, 1)
+	assert.Equal(t, "欢迎回到频道。", saved["Welcome back to the channel."].TargetText)
+	assert.Empty(t, stepParam.PendingMemories.Take())
+}
+
+func TestSaveTranslationMemory_ShortRejectedCueOnlyMatchesWholeWords(t *testing.T) {
+	setupTranslationMemoryTestDB(t)
+	enableTranslationMemory(t)
+	stepParam := &types.SubtitleTaskStepParam{TaskId: "task_1", OriginLanguage: types.LanguageNameEnglish, PendingMemories: &types.PendingTranslationMemories{}}
+	queueTranslationMemory(stepParam, types.LanguageNameSimplifiedChinese, []*TranslatedItem{
+		{OriginText: "I saw it yesterday.", TranslatedText: "我昨天看到了。"},
+		{OriginText: "Take a look.", TranslatedText: "看一看。"},
+		{OriginText: "Well, yes, ok.", TranslatedText: "嗯，是的，好。"},
+	})
+	report := &TranslationQaReport{Cues: []TranslationQaCue{
+		{OriginText: "yes", Status: qaStatusRepaired},
+		{OriginText: "ok", Status: qaStatusRepaired},
+	}}
+
+	saveTranslationMemory(stepParam, report)
+
+	saved, err := storage.FindTranslationMemories([]string{"I saw it yesterday.", "Take a look.", "Well, yes, ok."}, "zh_cn", 0)
+	require.NoError(t, err)
+	assert.Len(t, saved, 2)
+	assert.Contains(t, saved, "I saw it yesterday.")
+	assert.Contains(t, saved, "Take a look.")
+}
+
+func TestTranslationMemoryTmx_RoundTrip(t *testing.T) {
+	setupTranslationMemoryTestDB(t)
+	svc := Service{}
+	tmx := `<?xml version="1.0" encoding="UTF-8"?>
+<tmx version="1.4">
+  <header creationtool="test" segtype="sentence" adminlang="en" srclang="en" datatype="plaintext"/>
+  <body>
+    <tu>
+      <tuv xml:lang="zh-CN"><seg>大家好</seg></tuv>
+      <tuv xml:lang="en"><seg>Hello  everyone</seg></tuv>
+      <tuv xml:lang="ja"><seg>皆さんこんにちは</seg></tuv>
+    </tu>
+  </body>
+</tmx>`
+
+	count, err := svc.ImportTranslationMemoryTmx([]byte(tmx))
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	hits, err := storage.FindTranslationMemories([]string{"Hello everyone"}, "zh_cn", 0)
+	require.NoError(t, err)
+	assert.Equal(t, "大家好", hits["Hello everyone"].TargetText)
+
+	data, exported, err := svc.ExportTranslationMemoryTmx("en", "ja")
+	require.NoError(t, err)
+	assert.Equal(t, 1, exported)
+	assert.Contains(t, string(data), `<tuv xml:lang="ja">`)
+	assert.Contains(t, string(data), `<seg>皆さんこんにちは</seg>`)
+}
@@ -0,0 +1,401 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"krillin-ai/config"
+	"krillin-ai/internal/types"
+	"krillin-ai/log"
+	"krillin-ai/pkg/util"
+
+	"go.uber.org/zap"
+)
+
+// 翻译质检：先用规则检查每条译文（漏译、臆造、未翻译、语言错误、长度异常），通过规则的再交给大模型打分，
+// 不合格的字幕带上问题说明重译一次，重译结果通过规则检查才采用。结果写入质检报告
+
+const (
+	qaIssueEmpty         = "empty"
+	qaIssueOmission      = "omission"
+	qaIssueHallucination = "hallucination"
+	qaIssueUntranslated  = "untranslated"
+	qaIssueWrongLanguage = "wrong_language"
+	qaIssueLengthBlowUp  = "length_blowup"
+	qaIssueJudge         = "llm_judge"
+
+	qaStatusRepaired = "repaired"
+	qaStatusFailed   = "failed"
+
+	qaMinLengthForRatio = 10 // 原文太短时长度比没有参考意义
+	qaJudgeBatchSize    = 20
+)
+
+var qaNumberRegex = regexp.MustCompile(`\d+(?:[.,]\d+)*`)
+
+// TranslationQaIssue 译文的一个问题
+type TranslationQaIssue struct {
+	Type   string `json:"type"`
+	Detail string `json:"detail"`
+}
+
+// TranslationQaCue 质检不合格的字幕条目
+type TranslationQaCue struct {
+	Index        int                  `json:"index"`
+	Timestamp    string               `json:"timestamp"`
+	OriginText   string               `json:"origin_text"`
+	Translation  string               `json:"translation"`
+	Issues       []TranslationQaIssue `json:"issues"`
+	JudgeScore   int                  `json:"judge_score,omitempty"`
+	RepairedText string               `json:"repaired_text,omitempty"`
+	Status       string               `json:"status"` // repaired 已重译 failed 重译后仍不合格，保留原译文
+}
+
+// TranslationQaReport 翻译质检报告，只列出不合格的字幕
+type TranslationQaReport struct {
+	SourceLanguage string             `json:"source_language"`
+	TargetLanguage string             `json:"target_language"`
+	TotalCues      int                `json:"total_cues"`
+	PassedCues     int                `json:"passed_cues"`
+	RepairedCues   int                `json:"repaired_cues"`
+	FailedCues     int                `json:"failed_cues"`
+	LlmJudge       bool               `json:"llm_judge"`
+	Cues           []TranslationQaCue `json:"cues"`
+}
+
+type qaJudgeItem struct {
+	Id          int    `json:"id"`
+	Source      string `json:"source"`
+	Translation string `json:"translation"`
+	MaxChars    int    `json:"max_chars,omitempty"` // 开启译文精简时的长度预算，在预算内的精简不算漏译
+}
+
+type qaJudgeResult struct {
+	Id    int    `json:"id"`
+	Score int    `json:"score"`
+	Issue string `json:"issue"`
+}
+
+// checkTranslationRules 规则检查单条译文，返回发现的问题
+func checkTranslationRules(origin, translation string, sourceLanguage, targetLanguage types.StandardLanguageCode) []TranslationQaIssue {
+	issues := make([]TranslationQaIssue, 0)
+	if strings.TrimSpace(translation) == "" {
+		if strings.TrimSpace(origin) != "" {
+			issues = append(issues, TranslationQaIssue{Type: qaIssueEmpty, Detail: "translation is empty"})
+		}
+		return issues
+	}
+
+	if sourceLanguage != targetLanguage && countLetters(origin) >= 3 &&
+		normalizeMemoryKey(strings.ToLower(origin)) == normalizeMemoryKey(strings.ToLower(translation)) {
+		issues = append(issues, TranslationQaIssue{Type: qaIssueUntranslated, Detail: "translation is identical to the source"})
+	} else if !matchesLanguageScript(translation, targetLanguage) {
+		issues = append(issues, TranslationQaIssue{Type: qaIssueWrongLanguage, Detail: fmt.Sprintf("translation is not written in %s", types.GetStandardLanguageName(targetLanguage))})
+	}
+
+	originLength := calcLength(origin)
+	if originLength >= qaMinLengthForRatio {
+		ratio := calcLength(translation) / originLength
+		if ratio < config.Conf.TranslationQa.MinLengthRatio {
+			issues = append(issues, TranslationQaIssue{Type: qaIssueOmission, Detail: fmt.Sprintf("translation is too short (length ratio %.2f)", ratio)})
+		} else if config.Conf.TranslationQa.MaxLengthRatio > 0 && ratio > config.Conf.TranslationQa.MaxLengthRatio {
+			issues = append(issues, TranslationQaIssue{Type: qaIssueLengthBlowUp, Detail: fmt.Sprintf("translation is too long (length ratio %.2f)", ratio)})
+		}
+	}
+
+	translationNumbers := strings.Join(qaNumberRegex.FindAllString(strings.ReplaceAll(translation, ",", ""), -1), " ")
+	for _, number := range qaNumberRegex.FindAllString(strings.ReplaceAll(origin, ",", ""), -1) {
+		if !strings.Contains(" "+translationNumbers+" ", " "+number+" ") {
+			issues = append(issues, TranslationQaIssue{Type: qaIssueOmission, Detail: fmt.Sprintf("number %s is missing", number)})
+		}
+	}
+
+	if hasRepetitionLoop(translation) && !hasRepetitionLoop(origin) {
+		issues = append(issues, TranslationQaIssue{Type: qaIssueHallucination, Detail: "translation repeats itself"})
+	}
+	return issues
+}
+
+func countLetters(text string) int {
+	count := 0
+	for _, r := range text {
+		if unicode.IsLetter(r) {
+			count++
+		}
+	}
+	return count
+}
+
+// languageScripts 非拉丁字母书写的语言对应的文字，未列出的语言按拉丁字母处理
+func languageScripts(language types.StandardLanguageCode) []*unicode.RangeTable {
+	switch language {
+	case types.LanguageNameSimplifiedChinese, types.LanguageNameTraditionalChinese:
+		return []*unicode.RangeTable{unicode.Han}
+	case types.LanguageNameJapanese:
+		return []*unicode.RangeTable{unicode.Han, unicode.Hiragana, unicode.Katakana}
+	case types.LanguageNameKorean:
+		return []*unicode.RangeTable{unicode.Hangul, unicode.Han}
+	case types.LanguageNameRussian, types.LanguageNameUkrainian, types.LanguageNameBulgarian, types.LanguageNameMacedonian:
+		return []*unicode.RangeTable{unicode.Cyrillic}
+	case types.LanguageNameArabic, types.LanguageNamePersian:
+		return []*unicode.RangeTable{unicode.Arabic}
+	case types.LanguageNameHebrew:
+		return []*unicode.RangeTable{unicode.Hebrew}
+	case types.LanguageNameThai:
+		return []*unicode.RangeTable{unicode.Thai}
+	case types.LanguageNameHindi:
+		return []*unicode.RangeTable{unicode.Devanagari}
+	case types.LanguageNameBengali:
+		return []*unicode.RangeTable{unicode.Bengali}
+	case types.LanguageNameGreek:
+		return []*unicode.RangeTable{unicode.Greek}
+	case types.LanguageNameAmharic:
+		return []*unicode.RangeTable{unicode.Ethiopic}
+	}
+	return nil
+}
+
+// matchesLanguageScript 判断译文是否使用目标语言的文字。非拉丁文字的语言允许夹杂英文品牌名等，
+// 只要求出现目标文字；拉丁字母的语言要求字母大部分是拉丁字母
+func matchesLanguageScript(text string, language types.StandardLanguageCode) bool {
+	scripts := languageScripts(language)
+	if len(scripts) == 0 {
+		scripts = []*unicode.RangeTable{unicode.Latin}
+	}
+	letters, matched := 0, 0
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		if unicode.IsOneOf(scripts, r) {
+			matched++
+		}
+	}
+	if letters < 3 {
+		return true
+	}
+	if scripts[0] == unicode.Latin {
+		return matched*2 >= letters
+	}
+	return matched > 0
+}
+
+// hasRepetitionLoop 检测大模型常见的重复输出，如同一个词或短语连续出现多次
+func hasRepetitionLoop(text string) bool {
+	runes := []rune(strings.Join(strings.Fields(text), " "))
+	for size := 1; size <= 8; size++ {
+		for start := 0; start+size*4 <= len(runes); start++ {
+			unit := string(runes[start : start+size])
+			if strings.TrimSpace(unit) == "" || !strings.ContainsFunc(unit, unicode.IsLetter) {
+				continue
+			}
+			repeats := 1
+			for next := start + size; next+size <= len(runes) && string(runes[next:next+size]) == unit; next += size {
+				repeats++
+			}
+			if repeats >= 4 && size*repeats >= 8 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// judgeTranslations 让大模型给译文打分，返回 下标->评分结果，某一批失败时跳过该批
+func (s Service) judgeTranslations(ctx context.Context, indexes []int, srtBlocks []*util.SrtBlock, sourceLanguage, targetLanguage types.StandardLanguageCode) map[int]qaJudgeResult {
+	results := make(map[int]qaJudgeResult)
+	for start := 0; start < len(indexes); start += qaJudgeBatchSize {
+		end := min(start+qaJudgeBatchSize, len(indexes))
+		items := make([]qaJudgeItem, 0, end-start)
+		for _, idx := range indexes[start:end] {
+			items = append(items, qaJudgeItem{
+				Id:          idx,
+				Source:      srtBlocks[idx].OriginLanguageSentence,
+				Translation: srtBlocks[idx].TargetLanguageSentence,
+				MaxChars:    qaLengthBudget(targetLanguage, srtBlocks[idx].Timestamp),
+			})
+		}
+		itemsBytes, _ := json.Marshal(items)
+		prompt := s.renderPrompt(promptKindTranslationQaJudge, translationQaJudgePromptVars{
+			SourceLanguage: types.GetStandardLanguageName(sourceLanguage),
+			TargetLanguage: types.GetStandardLanguageName(targetLanguage),
+			Input:          string(itemsBytes),
+		})
+		response, err := s.chat(ctx, llmCallTranslate, prompt)
+		if err != nil {
+			log.GetLogger().Warn("judgeTranslations ChatCompletion err, skip batch", zap.Int("batchStart", start), zap.Error(err))
+			continue
+		}
+		var judged struct {
+			Results []qaJudgeResult `json:"results"`
+		}
+		if err = json.Unmarshal([]byte(strings.TrimSpace(util.CleanMarkdownCodeBlock(response))), &judged); err != nil {
+			log.GetLogger().Warn("judgeTranslations unmarshal err, skip batch", zap.Int("batchStart", start), zap.String("response", response), zap.Error(err))
+			continue
+		}
+		for _, result := range judged.Results {
+			if result.Score > 0 {
+				results[result.Id] = result
+			}
+		}
+	}
+	return results
+}
+
+// qaGlossaryTerms 这批原文涉及的术语和风格中的不翻译内容，重译时与正常翻译一样注入并校验
+func qaGlossaryTerms(stepParam *types.SubtitleTaskStepParam, sentences []string) []types.GlossaryTerm {
+	glossaryTerms := glossaryTermsForLanguage(stepParam.GlossaryTerms, stepParam.TargetLanguage)
+	return append(matchGlossaryTerms(sentences, glossaryTerms), styleDoNotTranslateTerms(sentences, stepParam.StyleProfile, glossaryTerms)...)
+}
+
+// repairTranslations 带上质检问题、翻译风格和术语要求重译不合格的字幕，返回 下标->新译文，失败的批次不返回
+func (s Service) repairTranslations(ctx context.Context, stepParam *types.SubtitleTaskStepParam, cues []*TranslationQaCue, indexes []int) map[int]string {
+	sourceLanguage, targetLanguage := stepParam.OriginLanguage, stepParam.TargetLanguage
+	repaired := make(map[int]string)
+	translator := s.translatorFor(sourceLanguage, targetLanguage)
+	for start := 0; start < len(cues); start += qaJudgeBatchSize {
+		end := min(start+qaJudgeBatchSize, len(cues))
+		texts := make([]string, 0, end-start)
+		var problems strings.Builder
+		for _, cue := range cues[start:end] {
+			texts = append(texts, cue.OriginText)
+			details := make([]string, 0, len(cue.Issues))
+			for _, issue := range cue.Issues {
+				details = append(details, issue.Detail)
+			}
+			if budget := qaLengthBudget(targetLanguage, cue.Timestamp); budget > 0 {
+				details = append(details, fmt.Sprintf("the new translation must stay within %d characters", budget))
+			}
+			problems.WriteString(fmt.Sprintf("- %q => %q: %s\n", cue.OriginText, cue.Translation, strings.Join(details, "; ")))
+		}
+		extraPrompt := buildStylePrompt(stepParam.StyleProfile) + buildGlossaryPrompt(qaGlossaryTerms(stepParam, texts)) +
+			fmt.Sprintf(types.TranslationQaRepairPrompt, strings.TrimSuffix(problems.String(), "\n"))
+		translated, err := translator.Translate(ctx, texts, sourceLanguage, targetLanguage, extraPrompt)
+		if err != nil {
+			log.GetLogger().Warn("repairTranslations translate err, keep original translations", zap.Int("batchStart", start), zap.Error(err))
+			continue
+		}
+		for i, text := range translated {
+			repaired[indexes[start+i]] = text
+		}
+	}
+	return repaired
+}
+
+// reviewTranslations 质检并修复 stepParam 目标语言的字幕译文，直接修改 srtBlocks 中的译文
+func (s Service) reviewTranslations(ctx context.Context, stepParam *types.SubtitleTaskStepParam, srtBlocks []*util.SrtBlock) *TranslationQaReport {
+	sourceLanguage, targetLanguage := stepParam.OriginLanguage, stepParam.TargetLanguage
+	report := &TranslationQaReport{
+		SourceLanguage: string(sourceLanguage),
+		TargetLanguage: string(targetLanguage),
+		TotalCues:      len(srtBlocks),
+		LlmJudge:       config.Conf.TranslationQa.LlmJudge,
+		Cues:           make([]TranslationQaCue, 0),
+	}
+
+	failedCues := make([]*TranslationQaCue, 0)
+	failedIndexes := make([]int, 0)
+	passedIndexes := make([]int, 0)
+	cueByIndex := make(map[int]*TranslationQaCue)
+	for i, block := range srtBlocks {
+		issues := checkTranslationRules(block.OriginLanguageSentence, block.TargetLanguageSentence, sourceLanguage, targetLanguage)
+		if len(issues) == 0 {
+			passedIndexes = append(passedIndexes, i)
+			continue
+		}
+		cueByIndex[i] = &TranslationQaCue{Index: block.Index, Timestamp: block.Timestamp, OriginText: block.OriginLanguageSentence, Translation: block.TargetLanguageSentence, Issues: issues}
+	}
+	if config.Conf.TranslationQa.LlmJudge && len(passedIndexes) > 0 {
+		for idx, result := range s.judgeTranslations(ctx, passedIndexes, srtBlocks, sourceLanguage, targetLanguage) {
+			if idx < 0 || idx >= len(srtBlocks) || result.Score >= config.Conf.TranslationQa.MinJudgeScore {
+				continue
+			}
+			block := srtBlocks[idx]
+			cueByIndex[idx] = &TranslationQaCue{
+				Index:       block.Index,
+				Timestamp:   block.Timestamp,
+				OriginText:  block.OriginLanguageSentence,
+				Translation: block.TargetLanguageSentence,
+				Issues:      []TranslationQaIssue{{Type: qaIssueJudge, Detail: result.Issue}},
+				JudgeScore:  result.Score,
+			}
+		}
+	}
+	for i := range srtBlocks {
+		if cue, ok := cueByIndex[i]; ok {
+			failedCues = append(failedCues, cue)
+			failedIndexes = append(failedIndexes, i)
+		}
+	}
+
+	repaired := s.repairTranslations(ctx, stepParam, failedCues, failedIndexes)
+	for i, cue := range failedCues {
+		cue.Status = qaStatusFailed
+		text, ok := repaired[failedIndexes[i]]
+		// 重译结果同样要符合术语表，不能用违反术语的译文替换原有译文
+		if ok && len(checkTranslationRules(cue.OriginText, text, sourceLanguage, targetLanguage)) == 0 &&
+			len(findGlossaryViolations([]string{cue.OriginText}, []string{text}, qaGlossaryTerms(stepParam, []string{cue.OriginText}))) == 0 {
+			cue.Status = qaStatusRepaired
+			cue.RepairedText = text
+			srtBlocks[failedIndexes[i]].TargetLanguageSentence = text
+			report.RepairedCues++
+		} else {
+			report.FailedCues++
+		}
+		report.Cues = append(report.Cues, *cue)
+	}
+	report.PassedCues = report.TotalCues - len(failedCues)
+	return report
+}
+
+// translationQaEnabled 是否需要质检，只有原文字幕时不需要
+func translationQaEnabled(stepParam *types.SubtitleTaskStepParam) bool {
+	return config.Conf.TranslationQa.Enabled && stepParam.TargetLanguage != "" && stepParam.TargetLanguage != "none"
+}
+
+// reviewBilingualSrt 对双语字幕做翻译质检，重写修复后的双语字幕并保存质检报告，没有开启质检时返回的报告为 nil
+func (s Service) reviewBilingualSrt(ctx context.Context, stepParam *types.SubtitleTaskStepParam) (*TranslationQaReport, error) {
+	if !translationQaEnabled(stepParam) {
+		return nil, nil
+	}
+	srtBlocks, err := readBilingualSrtFile(stepParam.BilingualSrtFilePath, stepParam.SubtitleResultType)
+	if err != nil {
+		return nil, fmt.Errorf("reviewBilingualSrt %w", err)
+	}
+	report := s.reviewTranslations(ctx, stepParam, srtBlocks)
+	if report.RepairedCues > 0 {
+		if err = writeBilingualSrtFile(stepParam.BilingualSrtFilePath, srtBlocks, stepParam.SubtitleResultType); err != nil {
+			return nil, fmt.Errorf("reviewBilingualSrt %w", err)
+		}
+	}
+	if err = saveTranslationQaReport(stepParam, report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// saveTranslationQaReport 保存质检报告并加入任务的下载文件
+func saveTranslationQaReport(stepParam *types.SubtitleTaskStepParam, report *TranslationQaReport) error {
+	reportPath := filepath.Join(stepParam.TaskBasePath, "output", types.SubtitleTaskTranslationQaReportFileName)
+	if err := util.SaveToDisk(report, reportPath); err != nil {
+		return fmt.Errorf("saveTranslationQaReport save report err: %w", err)
+	}
+	reportName := fmt.Sprintf("Translation QA Report (%s)", types.GetStandardLanguageName(stepParam.TargetLanguage))
+	if stepParam.UserUILanguage == types.LanguageNameSimplifiedChinese {
+		reportName = fmt.Sprintf("翻译质检报告 (%s)", types.GetStandardLanguageName(stepParam.TargetLanguage))
+	}
+	stepParam.SubtitleInfos = append(stepParam.SubtitleInfos, types.SubtitleFileInfo{
+		Name:               reportName,
+		Path:               reportPath,
+		LanguageIdentifier: "translation_qa",
+	})
+	log.GetLogger().Info("translation qa completed", zap.Any("taskId", stepParam.TaskId), zap.String("language", report.TargetLanguage),
+		zap.Int("total", report.TotalCues), zap.Int("repaired", report.RepairedCues), zap.Int("failed", report.FailedCues))
+	return nil
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"krillin-ai/config"
+	"krillin-ai/internal/mocks"
+	"krillin-ai/internal/types"
+	"krillin-ai/pkg/util"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func issueTypes(issues []TranslationQaIssue) []string {
+	result := make([]string, 0, len(issues))
+	for _, issue := range issues {
+		result = append(result, issue.Type)
+	}
+	return result
+}
+
+func qaStepParam() *types.SubtitleTaskStepParam {
+	return &types.SubtitleTaskStepParam{OriginLanguage: types.LanguageNameEnglish, TargetLanguage: types.LanguageNameSimplifiedChinese}
+}
+
+func TestCheckTranslationRules(t *testing.T) {
+	oldQa := config.Conf.TranslationQa
+	defer func() { config.Conf.TranslationQa = oldQa }()
+	config.Conf.TranslationQa.MinLengthRatio = 0.2
+	config.Conf.TranslationQa.MaxLengthRatio = 3
+
+	en, zh, de := types.LanguageNameEnglish, types.LanguageNameSimplifiedChinese, types.LanguageNameGerman
+	assert.Empty(t, checkTranslationRules("We sold 250 units last year.", "我们去年卖出了250台。", en, zh))
+	assert.Equal(t, []string{qaIssueEmpty}, issueTypes(checkTranslationRules("Hello there", " ", en, zh)))
+	assert.Equal(t, []string{qaIssueUntranslated}, issueTypes(checkTranslationRules("Good morning", "Good morning", en, zh)))
+	assert.Equal(t, []string{qaIssueWrongLanguage}, issueTypes(checkTranslationRules("Good morning", "おはようございます", en, de)))
+	assert.Contains(t, issueTypes(checkTranslationRules("This sentence is long enough to measure its length ratio.", "好", en, zh)), qaIssueOmission)
+	assert.Contains(t, issueTypes(checkTranslationRules("It costs 42 dollars.", "它很贵。", en, zh)), qaIssueOmission)
+	assert.Contains(t, issueTypes(checkTranslationRules("Thank you very much", "谢谢谢谢谢谢谢谢谢谢谢谢谢谢谢谢", en, zh)), qaIssueHallucination)
+	// 中文译文夹杂英文名称是正常的
+	assert.Empty(t, checkTranslationRules("I use Visual Studio Code every day.", "我每天都用 Visual Studio Code。", en, zh))
+}
+
+func TestReviewTranslations_RepairsFailingCues(t *testing.T) {
+	oldQa := config.Conf.TranslationQa
+	defer func() { config.Conf.TranslationQa = oldQa }()
+	config.Conf.TranslationQa = config.TranslationQaConfig{Enabled: true, LlmJudge: true, MinJudgeScore: 3, MinLengthRatio: 0.2, MaxLengthRatio: 3}
+
+	mockChatCompleter := new(mocks.MockChatCompleter)
+	mockChatCompleter.On("ChatCompletion", mock.MatchedBy(func(prompt string) bool {
+		return strings.Contains(prompt, `"id":1`) && !strings.Contains(prompt, `"id":0`)
+	})).Return(`{"results":[{"id":1,"score":2,"issue":"meaning reversed"},{"id":2,"score":5,"issue":""}]}`, nil).Once()
+	mockChatCompleter.On("ChatCompletion", mock.MatchedBy(func(prompt string) bool {
+		return strings.Contains(prompt, "Translate a list of subtitles") && strings.Contains(prompt, "meaning reversed")
+	})).Return(`["早上好", "我喜欢猫"]`, nil).Once()
+	svc := Service{ChatCompleter: mockChatCompleter}
+
+	srtBlocks := []*util.SrtBlock{
+		{Index: 1, Timestamp: "00:00:00,000 --> 00:00:01,000", OriginLanguageSentence: "Good morning", TargetLanguageSentence: "Good morning"},
+		{Index: 2, Timestamp: "00:00:01,000 --> 00:00:02,000", OriginLanguageSentence: "I like cats", TargetLanguageSentence: "我不喜欢猫"},
+		{Index: 3, Timestamp: "00:00:02,000 --> 00:00:03,000", OriginLanguageSentence: "See you", TargetLanguageSentence: "再见"},
+	}
+	report := svc.reviewTranslations(context.Background(), qaStepParam(), srtBlocks)
+
+	assert.Equal(t, 3, report.TotalCues)
+	assert.Equal(t, 1, report.PassedCues)
+	assert.Equal(t, 2, report.RepairedCues)
+	assert.Equal(t, 0, report.FailedCues)
+	require.Len(t, report.Cues, 2)
+	assert.Equal(t, qaIssueUntranslated, report.Cues[0].Issues[0].Type)
+	assert.Equal(t, 2, report.Cues[1].JudgeScore)
+	assert.Equal(t, qaStatusRepaired, report.Cues[1].Status)
+	assert.Equal(t, "早上好", srtBlocks[0].TargetLanguageSentence)
+	assert.Equal(t, "我喜欢猫", srtBlocks[1].TargetLanguageSentence)
+	assert.Equal(t, "再见", srtBlocks[2].TargetLanguageSentence)
+	mockChatCompleter.AssertExpectations(t)
+}
+
+func TestReviewTranslations_KeepsOriginalWhenRepairStillFails(t *testing.T) {
+	oldQa := config.Conf.TranslationQa
+	defer func() { config.Conf.TranslationQa = oldQa }()
+	config.Conf.TranslationQa = config.TranslationQaConfig{Enabled: true, LlmJudge: false, MinLengthRatio: 0.2, MaxLengthRatio: 3}
+
+	mockChatCompleter := new(mocks.MockChatCompleter)
+	mockChatCompleter.On("ChatCompletion", mock.Anything).Return(`["Good morning"]`, nil).Once()
+	svc := Service{ChatCompleter: mockChatCompleter}
+
+	srtBlocks := []*util.SrtBlock{{Index: 1, OriginLanguageSentence: "Good morning", TargetLanguageSentence: "Good morning"}}
+	report := svc.reviewTranslations(context.Background(), qaStepParam(), srtBlocks)
+
+	assert.Equal(t, 1, report.FailedCues)
+	assert.Equal(t, qaStatusFailed, report.Cues[0].Status)
+	assert.Equal(t, "Good morning", srtBlocks[0].TargetLanguageSentence)
+	mockChatCompleter.AssertExpectations(t)
+}
+
+func TestReviewTranslations_TellsJudgeAboutLengthBudget(t *testing.T) {
+	oldQa, oldCondensation := config.Conf.TranslationQa, config.Conf.Condensation
+	defer func() { config.Conf.TranslationQa, config.Conf.Condensation = oldQa, oldCondensation }()
+	config.Conf.TranslationQa = config.TranslationQaConfig{Enabled: true, LlmJudge: true, MinJudgeScore: 3, MinLengthRatio: 0.2, MaxLengthRatio: 3}
+	config.Conf.Condensation = config.CondensationConfig{Enabled: true, MaxCps: 17, MaxCpsAsian: 9}
+
+	mockChatCompleter := new(mocks.MockChatCompleter)
+	mockChatCompleter.On("ChatCompletion", mock.MatchedBy(func(prompt string) bool {
+		return strings.Contains(prompt, `"max_chars":18`) && strings.Contains(prompt, "must not be treated as an omission")
+	})).Return(`{"results":[{"id":0,"score":5,"issue":""}]}`, nil).Once()
+	svc := Service{ChatCompleter: mockChatCompleter}
+
+	srtBlocks := []*util.SrtBlock{{
+		Index:                  1,
+		Timestamp:              "00:00:00,000 --> 00:00:02,000",
+		OriginLanguageSentence: "What I really want to tell you today is that this is important.",
+		TargetLanguageSentence: "今天我想说的是这很重要。",
+	}}
+	report := svc.reviewTranslations(context.Background(), qaStepParam(), srtBlocks)
+
+	assert.Equal(t, 1, report.PassedCues)
+	assert.Equal(t, "今天我想说的是这很重要。", srtBlocks[0].TargetLanguageSentence)
+	mockChatCompleter.AssertExpectations(t)
+}
+
+func TestReviewTranslations_RejectsRepairThatBreaksGlossary(t *testing.T) {
+	oldQa := config.Conf.TranslationQa
+	defer func() { config.Conf.TranslationQa = oldQa }()
+	config.Conf.TranslationQa = config.TranslationQaConfig{Enabled: true, LlmJudge: false, MinLengthRatio: 0.2, MaxLengthRatio: 3}
+
+	mockChatCompleter := new(mocks.MockChatCompleter)
+	mockChatCompleter.On("ChatCompletion", mock.MatchedBy(func(prompt string) bool {
+		return strings.Contains(prompt, `"Krillin" -> "克林"`) && strings.Contains(prompt, "Keep it formal.")
+	})).Return(`["欢迎来到小林"]`, nil).Once()
+	svc := Service{ChatCompleter: mockChatCompleter}
+	stepParam := qaStepParam()
+	stepParam.GlossaryTerms = []types.GlossaryTerm{{Source: "Krillin", Target: "克林"}}
+	stepParam.StyleProfile = &types.StyleProfile{CustomInstructions: "Keep it formal."}
+
+	srtBlocks := []*util.SrtBlock{{Index: 1, OriginLanguageSentence: "Welcome to Krillin", TargetLanguageSentence: "Welcome to Krillin"}}
+	report := svc.reviewTranslations(context.Background(), stepParam, srtBlocks)
+
+	assert.Equal(t, 1, report.FailedCues)
+	assert.Equal(t, qaStatusFailed, report.Cues[0].Status)
+	assert.Equal(t, "Welcome to Krillin", srtBlocks[0].TargetLanguageSentence)
+	mockChatCompleter.AssertExpectations(t)
+}
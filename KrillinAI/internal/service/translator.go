@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"krillin-ai/config"
+	"krillin-ai/internal/types"
+	"krillin-ai/log"
+
+	"go.uber.org/zap"
+)
+
+// 翻译后端：默认使用大模型，可以按语言对路由到通用机器翻译接口
+
+const (
+	translateProviderLlm = "llm"
+	translateProviderMt  = "mt"
+)
+
+// LlmTranslator 通过大模型批量翻译，instructions 插入到 BatchTranslatePrompt 的要求之后
+type LlmTranslator struct {
+	ChatCompleter   types.ChatCompleter
+	PromptTemplates map[string]string
+}
+
+func (t LlmTranslator) Translate(ctx context.Context, texts []string, sourceLanguage, targetLanguage types.StandardLanguageCode, instructions string) ([]string, error) {
+	inputBytes, _ := json.Marshal(texts)
+	if llmResponseFormat(t.ChatCompleter, llmCallTranslate, true) != types.ChatResponseFormatText {
+		// JSON 模式要求输出对象，把数组包在 translations 字段里
+		instructions += types.BatchTranslateJsonObjectPrompt
+	}
+	prompt := renderPrompt(t.PromptTemplates, promptKindBatchTranslate, batchTranslatePromptVars{
+		TargetLanguage: types.GetStandardLanguageName(targetLanguage),
+		Instructions:   instructions,
+		Input:          string(inputBytes),
+	})
+	responseText, err := chatWithConfig(ctx, t.ChatCompleter, llmCallTranslate, prompt, translationsJsonSchema)
+	if err != nil {
+		return nil, err
+	}
+	// 有时候LLM会返回 markdown 代码块，需要去掉 `json ... `
+	cleanText := strings.TrimSpace(responseText)
+	cleanText = strings.TrimPrefix(cleanText, "```json")
+	cleanText = strings.TrimPrefix(cleanText, "```")
+	cleanText = strings.TrimSuffix(cleanText, "```")
+	cleanText = strings.TrimSpace(cleanText)
+
+	translated, err := parseTranslationsResponse(cleanText)
+	if err != nil {
+		return nil, err
+	}
+	if len(translated) != len(texts) {
+		return nil, fmt.Errorf("batch size mismatch: expected %d, got %d", len(texts), len(translated))
+	}
+	return translated, nil
+}
+
+// translateProviderFor 按配置的语言对规则选择翻译后端，靠前的规则优先，都不匹配时使用默认后端
+func translateProviderFor(sourceLanguage, targetLanguage types.StandardLanguageCode) string {
+	for _, route := range config.Conf.Translate.Routes {
+		if routeLanguageMatches(route.SourceLanguage, sourceLanguage) && routeLanguageMatches(route.TargetLanguage, targetLanguage) {
+			return route.Provider
+		}
+	}
+	return config.Conf.Translate.Provider
+}
+
+func routeLanguageMatches(pattern string, language types.StandardLanguageCode) bool {
+	return pattern == "" || pattern == "*" || strings.EqualFold(pattern, string(language))
+}
+
+// translatorFor 返回语言对对应的翻译实现，未配置机器翻译时回退到大模型
+func (s Service) translatorFor(sourceLanguage, targetLanguage types.StandardLanguageCode) types.Translator {
+	if translateProviderFor(sourceLanguage, targetLanguage) == translateProviderMt {
+		if s.MtTranslator != nil {
+			return s.MtTranslator
+		}
+		log.GetLogger().Warn("translatorFor machine translation not configured, fallback to llm",
+			zap.String("source", string(sourceLanguage)), zap.String("target", string(targetLanguage)))
+	}
+	return LlmTranslator{ChatCompleter: s.ChatCompleter, PromptTemplates: s.PromptTemplates}
+}
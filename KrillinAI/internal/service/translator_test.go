@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"krillin-ai/config"
+	"krillin-ai/internal/mocks"
+	"krillin-ai/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type stubTranslator struct {
+	calls int
+}
+
+func (t *stubTranslator) Translate(ctx context.Context, texts []string, sourceLanguage, targetLanguage types.StandardLanguageCode, instructions string) ([]string, error) {
+	t.calls++
+	result := make([]string, 0, len(texts))
+	for _, text := range texts {
+		result = append(result, "["+string(targetLanguage)+"] "+text)
+	}
+	return result, nil
+}
+
+func TestTranslateProviderFor_FirstMatchingRouteWins(t *testing.T) {
+	oldTranslate := config.Conf.Translate
+	defer func() { config.Conf.Translate = oldTranslate }()
+	config.Conf.Translate.Provider = "llm"
+	config.Conf.Translate.Routes = []config.TranslateRoute{
+		{SourceLanguage: "ja", TargetLanguage: "de", Provider: "llm"},
+		{SourceLanguage: "*", TargetLanguage: "de", Provider: "mt"},
+		{TargetLanguage: "FR", Provider: "mt"},
+	}
+
+	assert.Equal(t, "llm", translateProviderFor(types.LanguageNameJapanese, types.LanguageNameGerman))
+	assert.Equal(t, "mt", translateProviderFor(types.LanguageNameEnglish, types.LanguageNameGerman))
+	assert.Equal(t, "mt", translateProviderFor(types.LanguageNameEnglish, types.LanguageNameFrench))
+	assert.Equal(t, "llm", translateProviderFor(types.LanguageNameEnglish, types.LanguageNameSimplifiedChinese))
+}
+
+func TestTranslateSentences_RoutesLanguagePairToMachineTranslation(t *testing.T) {
+	oldTranslate := config.Conf.Translate
+	oldMemory := config.Conf.TranslationMemory.Enabled
+	oldAttempts := config.Conf.App.TranslateMaxAttempts
+	defer func() {
+		config.Conf.Translate = oldTranslate
+		config.Conf.TranslationMemory.Enabled = oldMemory
+		config.Conf.App.TranslateMaxAttempts = oldAttempts
+	}()
+	config.Conf.Translate.Routes = []config.TranslateRoute{{SourceLanguage: "en", TargetLanguage: "de", Provider: "mt"}}
+	config.Conf.TranslationMemory.Enabled = false
+	config.Conf.App.TranslateMaxAttempts = 3
+
+	mtTranslator := &stubTranslator{}
+	mockChatCompleter := new(mocks.MockChatCompleter)
+	mockChatCompleter.On("ChatCompletion", mock.MatchedBy(func(prompt string) bool {
+		return strings.Contains(prompt, "Translate a list of subtitles into 简体中文")
+	})).Return(`["你好"]`, nil).Once()
+	svc := Service{ChatCompleter: mockChatCompleter, MtTranslator: mtTranslator}
+	// 机器翻译不会按术语表重译，不符合术语时直接采用结果而不是重试
+	stepParam := &types.SubtitleTaskStepParam{
+		OriginLanguage: types.LanguageNameEnglish,
+		GlossaryTerms:  []types.GlossaryTerm{{Source: "Hello", Target: "Servus"}},
+	}
+
+	german := svc.translateSentences(context.Background(), stepParam, []string{"Hello"}, types.LanguageNameGerman, 0)
+	chinese := svc.translateSentences(context.Background(), &types.SubtitleTaskStepParam{OriginLanguage: types.LanguageNameEnglish}, []string{"Hello"}, types.LanguageNameSimplifiedChinese, 0)
+
+	require.Len(t, german, 1)
+	assert.Equal(t, "[de] Hello", german[0].TranslatedText)
+	assert.Equal(t, 1, mtTranslator.calls)
+	assert.Equal(t, "你好", chinese[0].TranslatedText)
+	mockChatCompleter.AssertExpectations(t)
+}
@@ -31,6 +31,7 @@ func (s Service) uploadSubtitles(ctx context.Context, stepParam *types.SubtitleT
 			TaskId:      stepParam.TaskId,
 			Name:        info.Name,
 			DownloadUrl: "/api/file/" + downloadPath,
+			Language:    info.LanguageIdentifier,
 		})
 	}
 	// 更新字幕任务信息
@@ -0,0 +1,223 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"unicode/utf8"
+
+	"krillin-ai/config"
+	"krillin-ai/internal/dto"
+	"krillin-ai/internal/storage"
+	"krillin-ai/internal/types"
+	"krillin-ai/log"
+	"krillin-ai/pkg/ratelimit"
+	"krillin-ai/pkg/util"
+
+	"go.uber.org/zap"
+)
+
+// 用量统计：任务执行时把大模型、转录、语音合成客户端包一层，每次调用成功后按阶段记录用量并按价格表计算费用
+
+const usageProviderLlm = "llm"
+
+// usagePriceFor 返回提供方/模型对应的价格，靠前的配置优先
+func usagePriceFor(provider, model string) (config.UsagePrice, bool) {
+	for _, price := range config.Conf.Usage.Prices {
+		if !strings.EqualFold(price.Provider, provider) {
+			continue
+		}
+		if price.Model == "" || price.Model == "*" || strings.EqualFold(price.Model, model) {
+			return price, true
+		}
+	}
+	return config.UsagePrice{}, false
+}
+
+func usageCost(record *types.UsageRecord) float64 {
+	price, ok := usagePriceFor(record.Provider, record.Model)
+	if !ok {
+		return 0
+	}
+	return float64(record.PromptTokens)/1e6*price.InputPerMillionTokens +
+		float64(record.CompletionTokens)/1e6*price.OutputPerMillionTokens +
+		record.AudioSeconds/60*price.PerAudioMinute +
+		float64(record.Characters)/1e6*price.PerMillionCharacters
+}
+
+// recordUsage 计算费用并保存，失败只记日志
+func recordUsage(record *types.UsageRecord) {
+	record.Cost = usageCost(record)
+	if err := storage.SaveUsageRecord(record); err != nil {
+		log.GetLogger().Warn("recordUsage save err", zap.String("taskId", record.TaskId), zap.String("stage", record.Stage), zap.Error(err))
+	}
+}
+
+type usageChatCompleter struct {
+	types.UsageChatCompleter
+	taskId   string
+	provider string // 不按 llm.routes 路由的客户端（如智能切片单独配置的模型）指定提供方，为空时按用途取
+	model    string
+}
+
+func (c usageChatCompleter) Chat(ctx context.Context, req *types.ChatRequest) (string, error) {
+	content, usage, err := c.ChatWithUsage(ctx, req)
+	if err != nil {
+		return content, err
+	}
+	provider, model := llmProviderFor(req.Stage)
+	if c.provider != "" {
+		provider, model = c.provider, c.model
+	}
+	record := &types.UsageRecord{TaskId: c.taskId, Stage: req.Stage, Provider: provider, Model: model}
+	if usage != nil {
+		record.PromptTokens, record.CompletionTokens = usage.PromptTokens, usage.CompletionTokens
+	} else {
+		// 服务端没有返回用量（如未开启 stream_usage），按文本长度估算
+		record.Estimated = true
+		for _, message := range req.Messages {
+			record.PromptTokens += ratelimit.EstimateTokens(message.Content)
+		}
+		record.CompletionTokens = ratelimit.EstimateTokens(content)
+	}
+	recordUsage(record)
+	return content, nil
+}
+
+type usageTranscriber struct {
+	types.Transcriber
+	taskId string
+}
+
+func (t usageTranscriber) Transcription(ctx context.Context, audioFile, language, wordDir string) (*types.TranscriptionData, error) {
+	data, err := t.Transcriber.Transcription(ctx, audioFile, language, wordDir)
+	if err != nil {
+		return data, err
+	}
+	duration, durationErr := util.GetAudioDuration(audioFile)
+	if durationErr != nil {
+		log.GetLogger().Warn("usageTranscriber get audio duration err", zap.String("audioFile", audioFile), zap.Error(durationErr))
+	}
+	recordUsage(&types.UsageRecord{
+		TaskId:       t.taskId,
+		Stage:        types.UsageStageTranscribe,
+		Provider:     config.Conf.Transcribe.Provider,
+		Model:        transcribeModel(),
+		AudioSeconds: duration,
+	})
+	return data, nil
+}
+
+type usageTtser struct {
+	types.Ttser
+	taskId string
+}
+
+func (t usageTtser) Text2Speech(ctx context.Context, text string, voice string, outputFile string) error {
+	if err := t.Ttser.Text2Speech(ctx, text, voice, outputFile); err != nil {
+		return err
+	}
+	recordUsage(&types.UsageRecord{
+		TaskId:     t.taskId,
+		Stage:      types.UsageStageTts,
+		Provider:   config.Conf.Tts.Provider,
+		Model:      ttsModel(),
+		Characters: utf8.RuneCountInString(text),
+	})
+	return nil
+}
+
+func transcribeModel() string {
+	switch config.Conf.Transcribe.Provider {
+	case "openai":
+		return config.Conf.Transcribe.Openai.Model
+	case "fasterwhisper":
+		return config.Conf.Transcribe.Fasterwhisper.Model
+	case "whisperkit":
+		return config.Conf.Transcribe.Whisperkit.Model
+	case "whispercpp":
+		return config.Conf.Transcribe.Whispercpp.Model
+	case "whispercpp_server":
+		return config.Conf.Transcribe.WhispercppServer.Model
+	}
+	return ""
+}
+
+func ttsModel() string {
+	switch config.Conf.Tts.Provider {
+	case "openai":
+		return config.Conf.Tts.Openai.Model
+	case "minimax":
+		return config.Conf.Tts.Minimax.Model
+	}
+	return ""
+}
+
+// withUsageTracking 返回记录到指定任务的服务副本，未开启用量统计时原样返回
+func (s Service) withUsageTracking(taskId string) Service {
+	if !config.Conf.Usage.Enabled {
+		return s
+	}
+	if chatCompleter, ok := s.ChatCompleter.(types.UsageChatCompleter); ok {
+		s.ChatCompleter = usageChatCompleter{UsageChatCompleter: chatCompleter, taskId: taskId}
+	}
+	if s.Transcriber != nil {
+		s.Transcriber = usageTranscriber{Transcriber: s.Transcriber, taskId: taskId}
+	}
+	if s.TtsClient != nil {
+		s.TtsClient = usageTtser{Ttser: s.TtsClient, taskId: taskId}
+	}
+	return s
+}
+
+func newUsageSummary(total *types.UsageTotal) *dto.UsageSummary {
+	return &dto.UsageSummary{
+		Calls:            total.Calls,
+		PromptTokens:     total.PromptTokens,
+		CompletionTokens: total.CompletionTokens,
+		TotalTokens:      total.PromptTokens + total.CompletionTokens,
+		AudioSeconds:     total.AudioSeconds,
+		Characters:       total.Characters,
+		Cost:             total.Cost,
+		Currency:         config.Conf.Usage.Currency,
+		EstimatedCalls:   total.EstimatedCalls,
+	}
+}
+
+// taskUsageSummary 任务的用量合计，查询失败或没有记录时返回nil
+func taskUsageSummary(taskId string) *dto.UsageSummary {
+	total, err := storage.SumUsage(storage.UsageFilter{TaskId: taskId})
+	if err != nil {
+		log.GetLogger().Warn("taskUsageSummary SumUsage err", zap.String("taskId", taskId), zap.Error(err))
+		return nil
+	}
+	if total.Calls == 0 {
+		return nil
+	}
+	return newUsageSummary(total)
+}
+
+// GetUsage 按条件汇总用量，并按提供方、模型、阶段分组
+func (s Service) GetUsage(req dto.GetUsageReq) (*dto.GetUsageResData, error) {
+	filter := storage.UsageFilter{TaskId: req.TaskId, Stage: req.Stage, Since: req.Since, Until: req.Until}
+	total, err := storage.SumUsage(filter)
+	if err != nil {
+		return nil, err
+	}
+	groups, err := storage.GroupUsage(filter)
+	if err != nil {
+		return nil, err
+	}
+	res := &dto.GetUsageResData{
+		Total:  newUsageSummary(total),
+		Groups: make([]*dto.UsageGroup, 0, len(groups)),
+	}
+	for _, group := range groups {
+		res.Groups = append(res.Groups, &dto.UsageGroup{
+			Provider:     group.Provider,
+			Model:        group.Model,
+			Stage:        group.Stage,
+			UsageSummary: newUsageSummary(&group.UsageTotal),
+		})
+	}
+	return res, nil
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"krillin-ai/config"
+	"krillin-ai/internal/dto"
+	"krillin-ai/internal/mocks"
+	"krillin-ai/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type stubUsageChatCompleter struct {
+	stubStructuredChatCompleter
+	usage *types.TokenUsage
+}
+
+func (c *stubUsageChatCompleter) ChatWithUsage(ctx context.Context, req *types.ChatRequest) (string, *types.TokenUsage, error) {
+	content, err := c.Chat(ctx, req)
+	return content, c.usage, err
+}
+
+func setupUsageTestDB(t *testing.T) {
+	t.Helper()
+	setupTestDB(t, &types.UsageRecord{})
+}
+
+func TestWithUsageTracking_RecordsCostPerTaskAndStage(t *testing.T) {
+	setupUsageTestDB(t)
+	oldUsage, oldLlm, oldTts := config.Conf.Usage, config.Conf.Llm, config.Conf.Tts
+	defer func() { config.Conf.Usage, config.Conf.Llm, config.Conf.Tts = oldUsage, oldLlm, oldTts }()
+	config.Conf.Llm.Model = "gpt-4o-mini"
+	config.Conf.Tts.Provider = "edge-tts"
+	config.Conf.Usage = config.UsageConfig{
+		Enabled:  true,
+		Currency: "USD",
+		Prices: []config.UsagePrice{
+			{Provider: "llm", Model: "gpt-4o-mini", InputPerMillionTokens: 1, OutputPerMillionTokens: 4},
+			{Provider: "edge-tts", PerMillionCharacters: 1000},
+		},
+	}
+
+	mockTtser := new(mocks.MockTtser)
+	mockTtser.On("Text2Speech", "你好世界", "voice", "out.wav").Return(nil).Once()
+	svc := Service{
+		ChatCompleter: &stubUsageChatCompleter{stubStructuredChatCompleter: stubStructuredChatCompleter{response: "ok"}, usage: &types.TokenUsage{PromptTokens: 1000, CompletionTokens: 500}},
+		TtsClient:     mockTtser,
+	}.withUsageTracking("task_1")
+
+	_, err := svc.chat(context.Background(), llmCallTranslate, "hello")
+	require.NoError(t, err)
+	_, err = svc.chat(context.Background(), llmCallSummary, "hello")
+	require.NoError(t, err)
+	require.NoError(t, svc.TtsClient.Text2Speech(context.Background(), "你好世界", "voice", "out.wav"))
+	// 其他任务的用量不计入
+	_, err = Service{ChatCompleter: svc.ChatCompleter.(usageChatCompleter).UsageChatCompleter}.withUsageTracking("task_2").chat(context.Background(), llmCallTranslate, "hi")
+	require.NoError(t, err)
+
+	summary := taskUsageSummary("task_1")
+	require.NotNil(t, summary)
+	assert.EqualValues(t, 3, summary.Calls)
+	assert.EqualValues(t, 2000, summary.PromptTokens)
+	assert.EqualValues(t, 3000, summary.TotalTokens)
+	assert.EqualValues(t, 4, summary.Characters)
+	assert.InDelta(t, 2*(0.001+0.002)+0.004, summary.Cost, 1e-9)
+	assert.Nil(t, taskUsageSummary("task_unknown"))
+
+	usage, err := svc.GetUsage(dto.GetUsageReq{Stage: types.UsageStageTranslate})
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, usage.Total.Calls)
+	require.Len(t, usage.Groups, 1)
+	assert.Equal(t, "llm", usage.Groups[0].Provider)
+	assert.Equal(t, "gpt-4o-mini", usage.Groups[0].Model)
+	assert.EqualValues(t, 2000, usage.Groups[0].PromptTokens)
+	mockTtser.AssertExpectations(t)
+}
+
+func TestWithUsageTracking_DisabledOrPlainCompleter(t *testing.T) {
+	oldUsage := config.Conf.Usage
+	defer func() { config.Conf.Usage = oldUsage }()
+
+	mockChatCompleter := new(mocks.MockChatCompleter)
+	config.Conf.Usage.Enabled = true
+	svc := Service{ChatCompleter: mockChatCompleter}.withUsageTracking("task_1")
+	// 不能返回用量的实现不包装，避免改变调用方式
+	assert.Same(t, mockChatCompleter, svc.ChatCompleter)
+
+	config.Conf.Usage.Enabled = false
+	completer := &stubUsageChatCompleter{}
+	svc = Service{ChatCompleter: completer}.withUsageTracking("task_1")
+	assert.Same(t, completer, svc.ChatCompleter)
+	mockChatCompleter.AssertNotCalled(t, "ChatCompletion", mock.Anything)
+}
+
+func TestUsageChatCompleter_ProviderOverride(t *testing.T) {
+	setupUsageTestDB(t)
+	oldUsage := config.Conf.Usage
+	defer func() { config.Conf.Usage = oldUsage }()
+	config.Conf.Usage = config.UsageConfig{
+		Enabled: true,
+		Prices:  []config.UsagePrice{{Provider: "smart_clipper", Model: "moonshot-v1-128k", InputPerMillionTokens: 2}},
+	}
+
+	completer := usageChatCompleter{
+		UsageChatCompleter: &stubUsageChatCompleter{stubStructuredChatCompleter: stubStructuredChatCompleter{response: "[]"}, usage: &types.TokenUsage{PromptTokens: 1000}},
+		taskId:             smartClipperMasterTaskId("abc"),
+		provider:           "smart_clipper",
+		model:              "moonshot-v1-128k",
+	}
+	_, err := completer.Chat(context.Background(), newChatRequest(llmCallClipper, "hello", nil))
+	require.NoError(t, err)
+
+	res, err := Service{}.GetUsage(dto.GetUsageReq{TaskId: "master_abc"})
+	require.NoError(t, err)
+	require.Len(t, res.Groups, 1)
+	assert.Equal(t, "smart_clipper", res.Groups[0].Provider)
+	assert.Equal(t, "moonshot-v1-128k", res.Groups[0].Model)
+	assert.InDelta(t, 0.002, res.Total.Cost, 1e-9)
+}
+
+func TestUsageChatCompleter_EstimatesWhenServerOmitsUsage(t *testing.T) {
+	setupUsageTestDB(t)
+	oldUsage := config.Conf.Usage
+	defer func() { config.Conf.Usage = oldUsage }()
+	config.Conf.Usage = config.UsageConfig{Enabled: true}
+
+	completer := usageChatCompleter{
+		UsageChatCompleter: &stubUsageChatCompleter{stubStructuredChatCompleter: stubStructuredChatCompleter{response: "abcd"}},
+		taskId:             "task_1",
+	}
+	_, err := completer.Chat(context.Background(), &types.ChatRequest{
+		Stage:    types.UsageStageTranslate,
+		Messages: []types.ChatMessage{{Role: "user", Content: "12345678"}},
+	})
+	require.NoError(t, err)
+
+	summary := taskUsageSummary("task_1")
+	require.NotNil(t, summary)
+	assert.EqualValues(t, 1, summary.Calls)
+	assert.EqualValues(t, 1, summary.EstimatedCalls)
+	assert.EqualValues(t, 3, summary.PromptTokens)
+	assert.EqualValues(t, 2, summary.CompletionTokens)
+}
@@ -35,7 +35,7 @@ func InitDB() {
 	}
 
 	// Auto Migrate the schema
-	err = DB.AutoMigrate(&types.SubtitleTask{}, &types.SubtitleInfo{})
+	err = DB.AutoMigrate(&types.SubtitleTask{}, &types.SubtitleInfo{}, &types.Glossary{}, &types.GlossaryTerm{}, &types.TranslationMemory{}, &types.UsageRecord{}, &types.PromptTemplate{}, &types.PromptTemplateVersion{}, &types.StyleProfile{}, &types.ReplacementRuleSet{}, &types.ReplacementRule{}, &types.SubtitleStyle{})
 	if err != nil {
 		log.GetLogger().Fatal("failed to migrate database", zap.Error(err))
 	}
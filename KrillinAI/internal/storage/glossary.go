@@ -0,0 +1,96 @@
+package storage
+
+import (
+	"errors"
+	"krillin-ai/internal/types"
+
+	"gorm.io/gorm"
+)
+
+func CreateGlossary(glossary *types.Glossary) error {
+	if DB == nil {
+		return errors.New("database not initialized")
+	}
+	return DB.Create(glossary).Error
+}
+
+// UpdateGlossary 更新术语表基本信息，并整体替换术语列表
+func UpdateGlossary(glossary *types.Glossary) error {
+	if DB == nil {
+		return errors.New("database not initialized")
+	}
+	return DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&types.Glossary{Id: glossary.Id}).Updates(map[string]any{
+			"name":        glossary.Name,
+			"description": glossary.Description,
+		}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("glossary_id = ?", glossary.Id).Delete(&types.GlossaryTerm{}).Error; err != nil {
+			return err
+		}
+		for i := range glossary.Terms {
+			glossary.Terms[i].Id = 0
+			glossary.Terms[i].GlossaryId = glossary.Id
+		}
+		if len(glossary.Terms) == 0 {
+			return nil
+		}
+		return tx.Create(&glossary.Terms).Error
+	})
+}
+
+func GetGlossary(id uint64) (*types.Glossary, error) {
+	if DB == nil {
+		return nil, errors.New("database not initialized")
+	}
+	var glossary types.Glossary
+	if err := DB.Preload("Terms").Where("id = ?", id).First(&glossary).Error; err != nil {
+		return nil, err
+	}
+	return &glossary, nil
+}
+
+func ListGlossaries() ([]types.Glossary, error) {
+	if DB == nil {
+		return nil, errors.New("database not initialized")
+	}
+	var glossaries []types.Glossary
+	if err := DB.Preload("Terms").Order("id asc").Find(&glossaries).Error; err != nil {
+		return nil, err
+	}
+	return glossaries, nil
+}
+
+func DeleteGlossary(id uint64) error {
+	if DB == nil {
+		return errors.New("database not initialized")
+	}
+	return DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("glossary_id = ?", id).Delete(&types.GlossaryTerm{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", id).Delete(&types.Glossary{}).Error
+	})
+}
+
+// GetGlossaryTerms 合并多个术语表的术语，同一原文术语在同一目标语言下以靠前的术语表为准
+func GetGlossaryTerms(ids []uint64) ([]types.GlossaryTerm, error) {
+	terms := make([]types.GlossaryTerm, 0)
+	seen := make(map[string]bool)
+	for _, id := range ids {
+		glossary, err := GetGlossary(id)
+		if err != nil {
+			return nil, err
+		}
+		for _, term := range glossary.Terms {
+			key := term.Source + "\x00" + term.TargetLanguage
+			if term.Source == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			terms = append(terms, term)
+		}
+	}
+	return terms, nil
+}
@@ -0,0 +1,116 @@
+package storage
+
+import (
+	"errors"
+	"krillin-ai/internal/types"
+
+	"gorm.io/gorm"
+)
+
+// CreatePromptTemplate 创建模板并保存为第1个版本
+func CreatePromptTemplate(tmpl *types.PromptTemplate) error {
+	if DB == nil {
+		return errors.New("database not initialized")
+	}
+	return DB.Transaction(func(tx *gorm.DB) error {
+		tmpl.Version = 1
+		if err := tx.Create(tmpl).Error; err != nil {
+			return err
+		}
+		return tx.Create(&types.PromptTemplateVersion{TemplateId: tmpl.Id, Version: tmpl.Version, Content: tmpl.Content}).Error
+	})
+}
+
+// UpdatePromptTemplate 更新模板，内容有变化时新增一个版本，旧版本保留
+func UpdatePromptTemplate(tmpl *types.PromptTemplate) error {
+	if DB == nil {
+		return errors.New("database not initialized")
+	}
+	return DB.Transaction(func(tx *gorm.DB) error {
+		var current types.PromptTemplate
+		if err := tx.Where("id = ?", tmpl.Id).First(&current).Error; err != nil {
+			return err
+		}
+		tmpl.Version = current.Version
+		if tmpl.Content != current.Content {
+			tmpl.Version++
+			if err := tx.Create(&types.PromptTemplateVersion{TemplateId: tmpl.Id, Version: tmpl.Version, Content: tmpl.Content}).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(&types.PromptTemplate{Id: tmpl.Id}).Updates(map[string]any{
+			"name":        tmpl.Name,
+			"kind":        tmpl.Kind,
+			"description": tmpl.Description,
+			"content":     tmpl.Content,
+			"version":     tmpl.Version,
+		}).Error
+	})
+}
+
+func GetPromptTemplate(id uint64) (*types.PromptTemplate, error) {
+	if DB == nil {
+		return nil, errors.New("database not initialized")
+	}
+	var tmpl types.PromptTemplate
+	if err := DB.Where("id = ?", id).First(&tmpl).Error; err != nil {
+		return nil, err
+	}
+	return &tmpl, nil
+}
+
+func GetPromptTemplateByName(name string) (*types.PromptTemplate, error) {
+	if DB == nil {
+		return nil, errors.New("database not initialized")
+	}
+	var tmpl types.PromptTemplate
+	if err := DB.Where("name = ?", name).First(&tmpl).Error; err != nil {
+		return nil, err
+	}
+	return &tmpl, nil
+}
+
+func ListPromptTemplates() ([]types.PromptTemplate, error) {
+	if DB == nil {
+		return nil, errors.New("database not initialized")
+	}
+	var templates []types.PromptTemplate
+	if err := DB.Order("id asc").Find(&templates).Error; err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
+
+func GetPromptTemplateVersion(templateId uint64, version int) (*types.PromptTemplateVersion, error) {
+	if DB == nil {
+		return nil, errors.New("database not initialized")
+	}
+	var v types.PromptTemplateVersion
+	if err := DB.Where("template_id = ? AND version = ?", templateId, version).First(&v).Error; err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+func ListPromptTemplateVersions(templateId uint64) ([]types.PromptTemplateVersion, error) {
+	if DB == nil {
+		return nil, errors.New("database not initialized")
+	}
+	var versions []types.PromptTemplateVersion
+	if err := DB.Where("template_id = ?", templateId).Order("version desc").Find(&versions).Error; err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+func DeletePromptTemplate(id uint64) error {
+	if DB == nil {
+		return errors.New("database not initialized")
+	}
+	return DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("template_id = ?", id).Delete(&types.PromptTemplateVersion{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", id).Delete(&types.PromptTemplate{}).Error
+	})
+}
@@ -0,0 +1,92 @@
+package storage
+
+import (
+	"errors"
+	"krillin-ai/internal/types"
+
+	"gorm.io/gorm"
+)
+
+func CreateReplacementRuleSet(ruleSet *types.ReplacementRuleSet) error {
+	if DB == nil {
+		return errors.New("database not initialized")
+	}
+	return DB.Create(ruleSet).Error
+}
+
+// UpdateReplacementRuleSet 更新规则集基本信息，并整体替换规则列表
+func UpdateReplacementRuleSet(ruleSet *types.ReplacementRuleSet) error {
+	if DB == nil {
+		return errors.New("database not initialized")
+	}
+	return DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&types.ReplacementRuleSet{Id: ruleSet.Id}).Updates(map[string]any{
+			"name":        ruleSet.Name,
+			"description": ruleSet.Description,
+		}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("rule_set_id = ?", ruleSet.Id).Delete(&types.ReplacementRule{}).Error; err != nil {
+			return err
+		}
+		for i := range ruleSet.Rules {
+			ruleSet.Rules[i].Id = 0
+			ruleSet.Rules[i].RuleSetId = ruleSet.Id
+		}
+		if len(ruleSet.Rules) == 0 {
+			return nil
+		}
+		return tx.Create(&ruleSet.Rules).Error
+	})
+}
+
+func GetReplacementRuleSet(id uint64) (*types.ReplacementRuleSet, error) {
+	if DB == nil {
+		return nil, errors.New("database not initialized")
+	}
+	var ruleSet types.ReplacementRuleSet
+	if err := DB.Preload("Rules", orderReplacementRules).Where("id = ?", id).First(&ruleSet).Error; err != nil {
+		return nil, err
+	}
+	return &ruleSet, nil
+}
+
+func ListReplacementRuleSets() ([]types.ReplacementRuleSet, error) {
+	if DB == nil {
+		return nil, errors.New("database not initialized")
+	}
+	var ruleSets []types.ReplacementRuleSet
+	if err := DB.Preload("Rules", orderReplacementRules).Order("id asc").Find(&ruleSets).Error; err != nil {
+		return nil, err
+	}
+	return ruleSets, nil
+}
+
+func DeleteReplacementRuleSet(id uint64) error {
+	if DB == nil {
+		return errors.New("database not initialized")
+	}
+	return DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("rule_set_id = ?", id).Delete(&types.ReplacementRule{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", id).Delete(&types.ReplacementRuleSet{}).Error
+	})
+}
+
+// GetReplacementRules 按规则集的先后顺序合并规则，规则集内按 position 排序
+func GetReplacementRules(ids []uint64) ([]types.ReplacementRule, error) {
+	rules := make([]types.ReplacementRule, 0)
+	for _, id := range ids {
+		ruleSet, err := GetReplacementRuleSet(id)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, ruleSet.Rules...)
+	}
+	return rules, nil
+}
+
+func orderReplacementRules(db *gorm.DB) *gorm.DB {
+	return db.Order("position asc, id asc")
+}
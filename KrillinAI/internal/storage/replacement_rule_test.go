@@ -0,0 +1,56 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+
+	"krillin-ai/internal/types"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestGetReplacementRulesOrdersBySetThenPosition(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err = db.AutoMigrate(&types.ReplacementRuleSet{}, &types.ReplacementRule{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	originalDB := DB
+	DB = db
+	t.Cleanup(func() { DB = originalDB })
+
+	// 规则按 position 倒序插入，确保排序不依赖插入顺序
+	first := &types.ReplacementRuleSet{Name: "first", Rules: []types.ReplacementRule{
+		{Position: 1, Pattern: "first-1"},
+		{Position: 0, Pattern: "first-0"},
+	}}
+	second := &types.ReplacementRuleSet{Name: "second", Rules: []types.ReplacementRule{
+		{Position: 2, Pattern: "second-2"},
+		{Position: 0, Pattern: "second-0"},
+		{Position: 1, Pattern: "second-1"},
+	}}
+	for _, ruleSet := range []*types.ReplacementRuleSet{first, second} {
+		if err = CreateReplacementRuleSet(ruleSet); err != nil {
+			t.Fatalf("CreateReplacementRuleSet(%s) returned error: %v", ruleSet.Name, err)
+		}
+	}
+
+	rules, err := GetReplacementRules([]uint64{second.Id, first.Id})
+	if err != nil {
+		t.Fatalf("GetReplacementRules() returned error: %v", err)
+	}
+
+	want := []string{"second-0", "second-1", "second-2", "first-0", "first-1"}
+	if len(rules) != len(want) {
+		t.Fatalf("GetReplacementRules() returned %d rules, want %d", len(rules), len(want))
+	}
+	for i, rule := range rules {
+		if rule.Pattern != want[i] {
+			t.Fatalf("GetReplacementRules()[%d].Pattern = %q, want %q", i, rule.Pattern, want[i])
+		}
+	}
+}
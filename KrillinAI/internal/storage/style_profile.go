@@ -0,0 +1,50 @@
+package storage
+
+import (
+	"errors"
+	"krillin-ai/internal/types"
+)
+
+func CreateStyleProfile(profile *types.StyleProfile) error {
+	if DB == nil {
+		return errors.New("database not initialized")
+	}
+	return DB.Create(profile).Error
+}
+
+// UpdateStyleProfile 整体更新风格配置，空值也会写入
+func UpdateStyleProfile(profile *types.StyleProfile) error {
+	if DB == nil {
+		return errors.New("database not initialized")
+	}
+	return DB.Model(&types.StyleProfile{Id: profile.Id}).Select("*").Omit("id", "create_time").Updates(profile).Error
+}
+
+func GetStyleProfile(id uint64) (*types.StyleProfile, error) {
+	if DB == nil {
+		return nil, errors.New("database not initialized")
+	}
+	var profile types.StyleProfile
+	if err := DB.Where("id = ?", id).First(&profile).Error; err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+func ListStyleProfiles() ([]types.StyleProfile, error) {
+	if DB == nil {
+		return nil, errors.New("database not initialized")
+	}
+	var profiles []types.StyleProfile
+	if err := DB.Order("id asc").Find(&profiles).Error; err != nil {
+		return nil, err
+	}
+	return profiles, nil
+}
+
+func DeleteStyleProfile(id uint64) error {
+	if DB == nil {
+		return errors.New("database not initialized")
+	}
+	return DB.Where("id = ?", id).Delete(&types.StyleProfile{}).Error
+}
@@ -0,0 +1,50 @@
+package storage
+
+import (
+	"errors"
+	"krillin-ai/internal/types"
+)
+
+func CreateSubtitleStyle(style *types.SubtitleStyle) error {
+	if DB == nil {
+		return errors.New("database not initialized")
+	}
+	return DB.Create(style).Error
+}
+
+// UpdateSubtitleStyle 整体更新字幕样式预设，空值也会写入
+func UpdateSubtitleStyle(style *types.SubtitleStyle) error {
+	if DB == nil {
+		return errors.New("database not initialized")
+	}
+	return DB.Model(&types.SubtitleStyle{Id: style.Id}).Select("*").Omit("id", "create_time").Updates(style).Error
+}
+
+func GetSubtitleStyle(id uint64) (*types.SubtitleStyle, error) {
+	if DB == nil {
+		return nil, errors.New("database not initialized")
+	}
+	var style types.SubtitleStyle
+	if err := DB.Where("id = ?", id).First(&style).Error; err != nil {
+		return nil, err
+	}
+	return &style, nil
+}
+
+func ListSubtitleStyles() ([]types.SubtitleStyle, error) {
+	if DB == nil {
+		return nil, errors.New("database not initialized")
+	}
+	var styles []types.SubtitleStyle
+	if err := DB.Order("id asc").Find(&styles).Error; err != nil {
+		return nil, err
+	}
+	return styles, nil
+}
+
+func DeleteSubtitleStyle(id uint64) error {
+	if DB == nil {
+		return errors.New("database not initialized")
+	}
+	return DB.Where("id = ?", id).Delete(&types.SubtitleStyle{}).Error
+}
@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"errors"
+	"krillin-ai/internal/types"
+
+	"gorm.io/gorm/clause"
+)
+
+// FindTranslationMemories 按归一化原文精确查找同一目标语言和翻译风格的记录，返回 SourceKey 到记录的映射
+func FindTranslationMemories(sourceKeys []string, targetLanguage string, styleProfileId uint64) (map[string]types.TranslationMemory, error) {
+	if DB == nil {
+		return nil, errors.New("database not initialized")
+	}
+	var entries []types.TranslationMemory
+	if err := DB.Where("target_language = ? AND style_profile_id = ? AND source_key IN ?", targetLanguage, styleProfileId, sourceKeys).Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	result := make(map[string]types.TranslationMemory, len(entries))
+	for _, entry := range entries {
+		result[entry.SourceKey] = entry
+	}
+	return result, nil
+}
+
+// ListTranslationMemoryCandidates 取同一目标语言和翻译风格下原文长度在 [minLength, maxLength] 之间的记录，作为模糊匹配的候选
+func ListTranslationMemoryCandidates(targetLanguage string, styleProfileId uint64, minLength, maxLength, limit int) ([]types.TranslationMemory, error) {
+	if DB == nil {
+		return nil, errors.New("database not initialized")
+	}
+	var entries []types.TranslationMemory
+	if err := DB.Where("target_language = ? AND style_profile_id = ? AND source_length BETWEEN ? AND ?", targetLanguage, styleProfileId, minLength, maxLength).
+		Order("update_time desc").Limit(limit).Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// SaveTranslationMemories 写入翻译记忆，原文、目标语言和翻译风格相同的记录覆盖为最新译文
+func SaveTranslationMemories(entries []types.TranslationMemory) error {
+	if DB == nil {
+		return errors.New("database not initialized")
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+	return DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "source_key"}, {Name: "target_language"}, {Name: "style_profile_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"source_language", "source_text", "target_text", "source_length", "task_id", "update_time"}),
+	}).CreateInBatches(entries, 100).Error
+}
+
+// ListTranslationMemories 导出用，语言为空时不过滤
+func ListTranslationMemories(sourceLanguage, targetLanguage string) ([]types.TranslationMemory, error) {
+	if DB == nil {
+		return nil, errors.New("database not initialized")
+	}
+	query := DB.Order("id asc")
+	if sourceLanguage != "" {
+		query = query.Where("source_language = ?", sourceLanguage)
+	}
+	if targetLanguage != "" {
+		query = query.Where("target_language = ?", targetLanguage)
+	}
+	var entries []types.TranslationMemory
+	if err := query.Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
@@ -0,0 +1,69 @@
+package storage
+
+import (
+	"errors"
+	"krillin-ai/internal/types"
+
+	"gorm.io/gorm"
+)
+
+// UsageFilter 用量查询条件，零值表示不过滤
+type UsageFilter struct {
+	TaskId string
+	Stage  string
+	Since  int64 // 起始时间（含），unix 秒
+	Until  int64 // 截止时间（不含），unix 秒
+}
+
+const usageSumColumns = "COUNT(*) AS calls, COALESCE(SUM(prompt_tokens), 0) AS prompt_tokens, COALESCE(SUM(completion_tokens), 0) AS completion_tokens, " +
+	"COALESCE(SUM(audio_seconds), 0) AS audio_seconds, COALESCE(SUM(characters), 0) AS characters, COALESCE(SUM(cost), 0) AS cost, " +
+	"COALESCE(SUM(CASE WHEN estimated THEN 1 ELSE 0 END), 0) AS estimated_calls"
+
+func SaveUsageRecord(record *types.UsageRecord) error {
+	if DB == nil {
+		return errors.New("database not initialized")
+	}
+	return DB.Create(record).Error
+}
+
+func usageQuery(filter UsageFilter) *gorm.DB {
+	query := DB.Model(&types.UsageRecord{})
+	if filter.TaskId != "" {
+		query = query.Where("task_id = ?", filter.TaskId)
+	}
+	if filter.Stage != "" {
+		query = query.Where("stage = ?", filter.Stage)
+	}
+	if filter.Since > 0 {
+		query = query.Where("create_time >= ?", filter.Since)
+	}
+	if filter.Until > 0 {
+		query = query.Where("create_time < ?", filter.Until)
+	}
+	return query
+}
+
+// SumUsage 汇总符合条件的用量
+func SumUsage(filter UsageFilter) (*types.UsageTotal, error) {
+	if DB == nil {
+		return nil, errors.New("database not initialized")
+	}
+	var total types.UsageTotal
+	if err := usageQuery(filter).Select(usageSumColumns).Scan(&total).Error; err != nil {
+		return nil, err
+	}
+	return &total, nil
+}
+
+// GroupUsage 按提供方、模型、阶段分组汇总用量
+func GroupUsage(filter UsageFilter) ([]types.UsageGroup, error) {
+	if DB == nil {
+		return nil, errors.New("database not initialized")
+	}
+	var groups []types.UsageGroup
+	if err := usageQuery(filter).Select("provider, model, stage, " + usageSumColumns).
+		Group("provider, model, stage").Order("cost desc, provider, model, stage").Scan(&groups).Error; err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
@@ -226,7 +226,7 @@ func (r *Runner) processSubtitleTask(payload SubtitleTaskPayload) error {
 		Url:                    payload.URL,
 		AudioUrl:               payload.AudioURL,
 		OriginLanguage:         payload.OriginLanguage,
-		TargetLang:             payload.TargetLanguage,
+		TargetLang:             dto.NewLanguageList(payload.TargetLanguage),
 		TtsVoiceCode:           payload.TtsVoiceCode,
 		Bilingual:              uint8(payload.Bilingual),
 		EmbedSubtitleVideoType: payload.EmbedType,
@@ -251,7 +251,7 @@ func (r *Runner) processSmartClipperTask(payload SmartClipperTaskPayload) error
 		return errors.New("service not initialized")
 	}
 
-	_, err := r.service.AnalyzeVideo(dto.SmartClipperAnalyzeReq{Url: payload.URL})
+	_, err := r.service.AnalyzeVideo(r.ctx, dto.SmartClipperAnalyzeReq{Url: payload.URL})
 	return err
 }
 
@@ -0,0 +1,29 @@
+package types
+
+// Glossary 术语表，可在创建任务时通过 glossary_ids 关联，翻译时强制统一术语译法
+type Glossary struct {
+	Id          uint64         `json:"id" gorm:"column:id;primaryKey"`                       // 自增id
+	Name        string         `json:"name" gorm:"column:name;uniqueIndex"`                  // 术语表名称
+	Description string         `json:"description" gorm:"column:description"`                // 描述
+	Terms       []GlossaryTerm `json:"terms" gorm:"foreignKey:GlossaryId;references:Id"`     // 术语列表
+	CreateTime  int64          `json:"create_time" gorm:"column:create_time;autoCreateTime"` // 创建时间
+	UpdateTime  int64          `json:"update_time" gorm:"column:update_time;autoUpdateTime"` // 更新时间
+}
+
+type GlossaryTerm struct {
+	Id             uint64 `json:"id" gorm:"column:id;primaryKey"`                  // 自增id
+	GlossaryId     uint64 `json:"glossary_id" gorm:"column:glossary_id;index"`     // 所属术语表
+	Source         string `json:"source" gorm:"column:source"`                     // 原文术语
+	Target         string `json:"target" gorm:"column:target"`                     // 目标译法，不翻译时可为空
+	CaseSensitive  bool   `json:"case_sensitive" gorm:"column:case_sensitive"`     // 原文匹配是否区分大小写
+	DoNotTranslate bool   `json:"do_not_translate" gorm:"column:do_not_translate"` // 保留原文不翻译，如品牌名
+	TargetLanguage string `json:"target_language" gorm:"column:target_language"`   // 译法对应的目标语言，为空时适用于所有目标语言
+}
+
+// ExpectedTranslation 译文中必须出现的内容
+func (t GlossaryTerm) ExpectedTranslation() string {
+	if t.DoNotTranslate || t.Target == "" {
+		return t.Source
+	}
+	return t.Target
+}
@@ -1,13 +1,72 @@
 package types
 
+import (
+	"context"
+	"encoding/json"
+)
+
+// 调用外部服务的接口都接收 context，取消 context 会中断请求；超时按提供方在 timeout 配置中设置
+
 type ChatCompleter interface {
-	ChatCompletion(query string) (string, error)
+	ChatCompletion(ctx context.Context, query string) (string, error)
 }
 
 type Transcriber interface {
-	Transcription(audioFile, language, wordDir string) (*TranscriptionData, error)
+	Transcription(ctx context.Context, audioFile, language, wordDir string) (*TranscriptionData, error)
 }
 
 type Ttser interface {
-	Text2Speech(text string, voice string, outputFile string) error
+	Text2Speech(ctx context.Context, text string, voice string, outputFile string) error
+}
+
+// Translator 批量翻译，返回的译文与输入一一对应。instructions 是附加的翻译要求（术语表、参考译文、上下文等），
+// 不支持提示词的机器翻译实现会忽略
+type Translator interface {
+	Translate(ctx context.Context, texts []string, sourceLanguage, targetLanguage StandardLanguageCode, instructions string) ([]string, error)
+}
+
+const (
+	ChatRoleSystem    = "system"
+	ChatRoleUser      = "user"
+	ChatRoleAssistant = "assistant"
+
+	ChatResponseFormatText       = "text"
+	ChatResponseFormatJsonObject = "json_object"
+	ChatResponseFormatJsonSchema = "json_schema"
+)
+
+type ChatMessage struct {
+	Role    string
+	Content string
+}
+
+// ChatJsonSchema response_format 为 json_schema 时约束输出的结构
+type ChatJsonSchema struct {
+	Name   string
+	Schema json.RawMessage
+	Strict bool
+}
+
+// ChatRequest 完整的大模型请求参数，零值表示使用服务端默认值
+type ChatRequest struct {
+	Messages        []ChatMessage
+	Temperature     float32
+	MaxTokens       int
+	ResponseFormat  string          // 空、text、json_object、json_schema
+	JsonSchema      *ChatJsonSchema // ResponseFormat 为 json_schema 时必填
+	Seed            *int
+	ReasoningEffort string // low、medium、high，仅推理模型支持
+	Stage           string // 用量统计的阶段，见 UsageStage 常量
+}
+
+// StructuredChatCompleter 支持完整请求参数的 ChatCompleter，未实现时调用方回退到 ChatCompletion
+type StructuredChatCompleter interface {
+	ChatCompleter
+	Chat(ctx context.Context, req *ChatRequest) (string, error)
+}
+
+// UsageChatCompleter 能返回 token 用量的 ChatCompleter
+type UsageChatCompleter interface {
+	StructuredChatCompleter
+	ChatWithUsage(ctx context.Context, req *ChatRequest) (string, *TokenUsage, error)
 }
@@ -0,0 +1,22 @@
+package types
+
+// PromptTemplate 用户自定义的提示词模板，按名称唯一，内容为 text/template 格式，变量由模板类型决定
+type PromptTemplate struct {
+	Id          uint64 `json:"id" gorm:"column:id;primaryKey"`                       // 自增id
+	Name        string `json:"name" gorm:"column:name;uniqueIndex"`                  // 模板名称，任务中按名称选择
+	Kind        string `json:"kind" gorm:"column:kind;index"`                        // 模板类型，如 batch_translate
+	Description string `json:"description" gorm:"column:description"`                // 描述
+	Content     string `json:"content" gorm:"column:content"`                        // 当前版本的模板内容
+	Version     int    `json:"version" gorm:"column:version"`                        // 当前版本号，从1开始
+	CreateTime  int64  `json:"create_time" gorm:"column:create_time;autoCreateTime"` // 创建时间
+	UpdateTime  int64  `json:"update_time" gorm:"column:update_time;autoUpdateTime"` // 更新时间
+}
+
+// PromptTemplateVersion 模板的历史版本，每次修改内容都新增一个版本，任务可以通过 name@version 固定使用某个版本
+type PromptTemplateVersion struct {
+	Id         uint64 `json:"id" gorm:"column:id;primaryKey"`                                         // 自增id
+	TemplateId uint64 `json:"template_id" gorm:"column:template_id;uniqueIndex:idx_template_version"` // 所属模板
+	Version    int    `json:"version" gorm:"column:version;uniqueIndex:idx_template_version"`         // 版本号
+	Content    string `json:"content" gorm:"column:content"`                                          // 该版本的模板内容
+	CreateTime int64  `json:"create_time" gorm:"column:create_time;autoCreateTime"`                   // 创建时间
+}
@@ -0,0 +1,29 @@
+package types
+
+// ReplacementRuleSet 文字替换规则集，可在创建任务时通过 replacement_rule_set_ids 关联，按顺序在流水线的不同环节替换文字
+type ReplacementRuleSet struct {
+	Id          uint64            `json:"id" gorm:"column:id;primaryKey"`                       // 自增id
+	Name        string            `json:"name" gorm:"column:name;uniqueIndex"`                  // 规则集名称
+	Description string            `json:"description" gorm:"column:description"`                // 描述
+	Rules       []ReplacementRule `json:"rules" gorm:"foreignKey:RuleSetId;references:Id"`      // 规则列表，按 position 顺序执行
+	CreateTime  int64             `json:"create_time" gorm:"column:create_time;autoCreateTime"` // 创建时间
+	UpdateTime  int64             `json:"update_time" gorm:"column:update_time;autoUpdateTime"` // 更新时间
+}
+
+type ReplacementRule struct {
+	Id            uint64   `json:"id" gorm:"column:id;primaryKey"`                    // 自增id
+	RuleSetId     uint64   `json:"rule_set_id" gorm:"column:rule_set_id;index"`       // 所属规则集
+	Position      int      `json:"position" gorm:"column:position"`                   // 规则集内的执行顺序，从0开始
+	Scope         string   `json:"scope" gorm:"column:scope"`                         // 生效环节 source/target/tts
+	Pattern       string   `json:"pattern" gorm:"column:pattern"`                     // 要替换的内容
+	Replacement   string   `json:"replacement" gorm:"column:replacement"`             // 替换后的内容，正则规则可用 $1 引用分组
+	IsRegex       bool     `json:"is_regex" gorm:"column:is_regex"`                   // pattern 是否为正则表达式
+	CaseSensitive bool     `json:"case_sensitive" gorm:"column:case_sensitive"`       // 是否区分大小写
+	Languages     []string `json:"languages" gorm:"column:languages;serializer:json"` // 生效的语言，为空表示所有语言；source 规则匹配源语言，其他匹配目标语言
+}
+
+const (
+	ReplacementScopeSource = "source" // 修正识别错误，翻译输入和字幕原文使用替换结果，时间戳仍按识别原文对齐
+	ReplacementScopeTarget = "target" // 翻译之后替换译文
+	ReplacementScopeTts    = "tts"    // 只替换送去配音的文字，字幕不变，如把缩写展开成读音
+)
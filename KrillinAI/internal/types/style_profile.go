@@ -0,0 +1,22 @@
+package types
+
+// StyleProfile 翻译风格配置，可在创建任务时通过 style_profile_id 选择，翻译时注入 prompt
+type StyleProfile struct {
+	Id                     uint64   `json:"id" gorm:"column:id;primaryKey"`                                                    // 自增id
+	Name                   string   `json:"name" gorm:"column:name;uniqueIndex"`                                               // 风格名称
+	Description            string   `json:"description" gorm:"column:description"`                                             // 描述
+	Tone                   string   `json:"tone" gorm:"column:tone"`                                                           // 语气，如 formal corporate、casual gaming slang
+	Formality              string   `json:"formality" gorm:"column:formality"`                                                 // 正式程度 formal/neutral/informal，为空时不限制
+	Audience               string   `json:"audience" gorm:"column:audience"`                                                   // 目标受众，如 children、software engineers
+	Honorifics             string   `json:"honorifics" gorm:"column:honorifics"`                                               // 敬语要求，如 use です/ます form、称呼观众用“您”
+	DoNotTranslatePatterns []string `json:"do_not_translate_patterns" gorm:"column:do_not_translate_patterns;serializer:json"` // 正则表达式，原文中匹配到的内容保留不译
+	CustomInstructions     string   `json:"custom_instructions" gorm:"column:custom_instructions"`                             // 其他自定义要求
+	CreateTime             int64    `json:"create_time" gorm:"column:create_time;autoCreateTime"`                              // 创建时间
+	UpdateTime             int64    `json:"update_time" gorm:"column:update_time;autoUpdateTime"`                              // 更新时间
+}
+
+const (
+	StyleFormalityFormal   = "formal"
+	StyleFormalityNeutral  = "neutral"
+	StyleFormalityInformal = "informal"
+)
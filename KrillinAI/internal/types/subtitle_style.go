@@ -0,0 +1,38 @@
+package types
+
+// SubtitleStyle 字幕嵌入视频时使用的样式预设，可在创建任务时通过 subtitle_style_id 选择。
+// 字号、描边、阴影和边距都按视频尺寸的百分比配置，生成 ASS 时按视频分辨率写入 PlayResX/PlayResY 并换算成像素
+type SubtitleStyle struct {
+	Id               uint64            `json:"id" gorm:"column:id;primaryKey"`                       // 自增id
+	Name             string            `json:"name" gorm:"column:name;uniqueIndex"`                  // 预设名称
+	Description      string            `json:"description" gorm:"column:description"`                // 描述
+	Position         string            `json:"position" gorm:"column:position"`                      // 字幕位置 bottom/middle/top，为空时为 bottom
+	MarginHorizontal float64           `json:"margin_horizontal" gorm:"column:margin_horizontal"`    // 左右边距，占视频宽度的百分比
+	MarginVertical   float64           `json:"margin_vertical" gorm:"column:margin_vertical"`        // 上下边距，占视频高度的百分比，为0时使用默认值
+	Major            SubtitleTextStyle `json:"major" gorm:"column:major;serializer:json"`            // 主字幕（译文或单语字幕）样式
+	Minor            SubtitleTextStyle `json:"minor" gorm:"column:minor;serializer:json"`            // 双语字幕中原文的样式
+	CreateTime       int64             `json:"create_time" gorm:"column:create_time;autoCreateTime"` // 创建时间
+	UpdateTime       int64             `json:"update_time" gorm:"column:update_time;autoUpdateTime"` // 更新时间
+}
+
+// SubtitleTextStyle 一种字幕文字的样式，颜色为 #RRGGBB，为空或为0的字段使用默认值
+type SubtitleTextStyle struct {
+	FontName     string  `json:"font_name"`     // 字体名称
+	FontSize     float64 `json:"font_size"`     // 字号，占视频高度的百分比
+	Bold         bool    `json:"bold"`          // 粗体
+	Italic       bool    `json:"italic"`        // 斜体
+	PrimaryColor string  `json:"primary_color"` // 文字颜色
+	OutlineColor string  `json:"outline_color"` // 描边颜色
+	Outline      float64 `json:"outline"`       // 描边宽度，占视频高度的百分比
+	ShadowColor  string  `json:"shadow_color"`  // 阴影颜色
+	Shadow       float64 `json:"shadow"`        // 阴影距离，占视频高度的百分比
+	Box          bool    `json:"box"`           // 使用背景框代替描边
+	BoxColor     string  `json:"box_color"`     // 背景框颜色
+	BoxOpacity   float64 `json:"box_opacity"`   // 背景框不透明度 0~1，为0时为0.5
+}
+
+const (
+	SubtitlePositionBottom = "bottom"
+	SubtitlePositionMiddle = "middle"
+	SubtitlePositionTop    = "top"
+)
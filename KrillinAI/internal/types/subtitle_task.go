@@ -181,6 +181,18 @@ Requirements:
 
 `
 
+var RestorePunctuationPrompt = `Please restore punctuation for the following %s speech transcription, which was produced by a speech recognition model and lacks punctuation.
+
+Original text: %s
+
+Requirements:
+1. Only insert punctuation marks (periods, commas, question marks, etc.) and adjust spaces around them
+2. Do NOT change, add, remove or reorder any words, and keep the original spelling
+3. Use the punctuation conventions of the text's language (e.g. "，。？" for Chinese and Japanese)
+4. Return only the punctuated text, no other descriptions or explanations
+
+`
+
 // var SplitTextWithContextPrompt = `你是一个专业翻译专家，擅长结合上下文进行准确翻译。请根据以下提供的上下文句子和目标句子，将目标句子翻译成%s，并确保翻译结果与上下文保持连贯一致：
 
 // 上下文句子：
@@ -242,6 +254,8 @@ Use "Previous Sentences" ONLY to understand the context of referents (e.g. prono
 
 **Your output must be literal, minimal, and on a single line. Provide only the translation result:**`
 
+// BatchTranslatePrompt 批量翻译的 prompt，第二个占位符是附加要求，由下面的片段按需拼接，不需要时为空：
+// 上下文、风格、术语表、翻译记忆，术语未遵守或质检不合格重译时再加上对应的说明，使用 JSON 模式时最后加上输出格式
 var BatchTranslatePrompt = `You are a professional subtitle translation expert.
 
 [BATCH TRANSLATION TASK]
@@ -258,12 +272,104 @@ Example: ["Hello world", "This is a test"]
 3. Maintain the original order.
 4. Do not include any explanation, markdown, or extra keys. Just the array.
 5. Example output: ["你好世界", "这是一个测试"]
-
+%s
 **Input Data**:
 %s
 
 **Your Output:**`
 
+// BatchTranslateJsonObjectPrompt 使用 JSON 模式时把输出改成对象，JSON 模式只允许输出对象
+var BatchTranslateJsonObjectPrompt = `
+**Output Format Override**:
+Return a JSON object instead of a bare array, with the translated array under the key "translations".
+Example output: {"translations": ["你好世界", "这是一个测试"]}
+`
+
+// BatchTranslateGlossaryPrompt 术语表，只列出本批次中出现的术语
+var BatchTranslateGlossaryPrompt = `
+**Glossary**:
+The following terms MUST be translated exactly as specified whenever they appear. "(keep as is)" means the term must stay untranslated in its original form.
+%s
+`
+
+// BatchTranslateContextPrompt 前文及其译文（以及可选的内容摘要），只作为上下文不翻译
+var BatchTranslateContextPrompt = `
+**Context (read-only)**:
+The following is provided ONLY to keep pronouns, names and terminology consistent with what came before.
+Do NOT translate it and do NOT include it in your output; your output must contain exactly one translation for each line of the Input Data.
+%s
+`
+
+var RunningSummaryPrompt = `You are maintaining a running summary of a video while its subtitles are being translated.
+
+Current summary:
+%s
+
+New subtitle lines:
+%s
+
+Update the summary to include the new lines. Keep the names of people, products and other key terms, and keep it under 100 words.
+Return only the updated summary, no other descriptions or explanations.`
+
+// BatchTranslateMemoryPrompt 翻译记忆中相似句子的历史译文
+var BatchTranslateMemoryPrompt = `
+**Translation Memory References**:
+Previously approved translations of similar sentences. Reuse their wording and terminology where they fit, but always translate the input lines faithfully.
+%s
+`
+
+// BatchTranslateStylePrompt 任务选用的翻译风格
+var BatchTranslateStylePrompt = `
+**Style**:
+Write every translation in the following style. The style must never change the meaning of a line.
+%s
+`
+
+var BatchTranslateGlossaryRetryPrompt = `
+
+**Attention**: Your previous output did not follow the glossary:
+%s
+Translate again and strictly follow the glossary.`
+
+// TranslationQaJudgePrompt 翻译质检时让大模型逐条给译文打分
+var TranslationQaJudgePrompt = `You are a strict subtitle translation reviewer.
+Review each subtitle translation from %s into %s. Check for omissions, mistranslations, content that is not present in the source (hallucinations), untranslated text and output in the wrong language.
+
+**Input**:
+A JSON array of objects with "id", "source" and "translation".
+Some items also have "max_chars": that translation was deliberately shortened to fit on screen within that many characters. A concise translation that keeps the essential meaning within the limit is acceptable and must not be treated as an omission.
+%s
+
+**Output Requirements**:
+1. Return strictly a JSON object {"results": [...]} whose array has one object per input item: {"id": <id>, "score": <integer 1-5>, "issue": "<short description, empty if none>"}.
+2. 5 means accurate and natural, 3 means understandable with minor problems, 1 means wrong, missing or unrelated.
+3. Do not include any explanation or markdown. Just the JSON object.
+
+**Your Output:**`
+
+// CondenseTranslationPrompt 译文超出阅读速度时让大模型在字数限制内精简
+var CondenseTranslationPrompt = `You are a professional subtitle editor.
+The following %s subtitle lines are too long to be read comfortably in the time they stay on screen.
+Rewrite each line more concisely so that it has at most "max_chars" characters, keeping the original meaning, names, numbers and tone. Remove filler words and redundancy first, and never add new information.
+
+**Input**:
+A JSON array of objects with "id", "text" and "max_chars".
+%s
+
+**Output Requirements**:
+1. Return strictly a JSON object {"results": [...]} whose array has one object per input item: {"id": <id>, "text": "<condensed line>"}.
+2. Keep the same language as the input.
+3. Do not include any explanation or markdown. Just the JSON object.
+
+**Your Output:**`
+
+// TranslationQaRepairPrompt 质检不合格的译文及问题，重译时使用
+var TranslationQaRepairPrompt = `
+**Attention**: Previous translations of these subtitles were rejected by quality review:
+%s
+Translate again and fix the problems above.
+`
+
 type SmallAudio struct {
 	AudioFile         string
 	TranscriptionData *TranscriptionData
@@ -333,10 +439,18 @@ const (
 	SubtitleTaskAudioTranscriptionDataPersistenceFileNamePattern = "audio_transcription_data_%d.json"
 	SubtitleTaskTranslationRawDataPersistenceFileNamePattern     = "audio_translation_raw_data_%d.json"
 	SubtitleTaskTranslationDataPersistenceFileNamePattern        = "translation_data_%d.json"
+	SubtitleTaskCondensedTranslationPersistenceFileNamePattern   = "condensed_translation_data_%d.json" // 拆句后带精简译文的翻译数据，完整译文仍保存在 translation_data
 	SubtitleTaskTransferredVerticalVideoFileName                 = "transferred_vertical_video.mp4"
 	SubtitleTaskHorizontalEmbedVideoFileName                     = "horizontal_embed.mp4"
 	SubtitleTaskVerticalEmbedVideoFileName                       = "vertical_embed.mp4"
 	SubtitleTaskVideoWithTtsFileName                             = "video_with_tts.mp4"
+	SubtitleTaskUserTranscriptFileName                           = "user_transcript.txt"
+	SubtitleTaskForcedAlignmentReportFileName                    = "forced_alignment_report.json"
+	SubtitleTaskConfidenceReviewReportFileName                   = "confidence_review.json"
+	SubtitleTaskConfidenceReviewSrtFileName                      = "confidence_review.srt"
+	SubtitleTaskConfidenceReviewAssFileName                      = "confidence_review.ass"
+	SubtitleTaskTranslationQaReportFileName                      = "translation_qa_report.json"
+	SubtitleTaskExportSubtitleFileNamePattern                    = "%s_subtitle.%s" // 按请求导出的其他格式字幕，轨道_subtitle.扩展名
 )
 
 const (
@@ -359,7 +473,7 @@ type SubtitleTaskStepParam struct {
 	TaskPtr                     *SubtitleTask // 和storage里面对应
 	TaskBasePath                string
 	Link                        string
-	AudioDownloadUrl            string        // New: Optional separate audio file URL to download
+	AudioDownloadUrl            string // New: Optional separate audio file URL to download
 	AudioFilePath               string
 	SubtitleResultType          SubtitleResultType
 	EnableModalFilter           bool
@@ -367,6 +481,7 @@ type SubtitleTaskStepParam struct {
 	TtsVoiceCode                string // 人声语音编码
 	VoiceCloneAudioUrl          string // 音色克隆的源音频oss地址
 	ReplaceWordsMap             map[string]string
+	ReplacementRules            []ReplacementRule    // 任务关联的替换规则，按执行顺序排列
 	OriginLanguage              StandardLanguageCode // 视频源语言
 	TargetLanguage              StandardLanguageCode // 用户希望的目标翻译语言
 	UserUILanguage              StandardLanguageCode // 用户的使用语言
@@ -379,8 +494,20 @@ type SubtitleTaskStepParam struct {
 	EmbedSubtitleVideoType      string // 合成字幕嵌入的视频类型 none不嵌入 horizontal横屏 vertical竖屏
 	VerticalVideoMajorTitle     string // 合成竖屏视频的主标题
 	VerticalVideoMinorTitle     string
-	MaxWordOneLine              int    // 字幕一行最多显示多少个字
-	VideoWithTtsFilePath        string // 替换源视频的音频为tts结果后的视频路径
+	MaxWordOneLine              int                         // 字幕一行最多显示多少个字
+	VideoWithTtsFilePath        string                      // 替换源视频的音频为tts结果后的视频路径
+	UserTranscriptFilePath      string                      // 用户提供的文稿，非空时跳过识别文本，仅做强制对齐
+	TranscribedWords            []Word                      // 整段音频的识别词（已加上分段偏移），用于置信度复核
+	GlossaryTerms               []GlossaryTerm              // 任务关联的术语表中的术语，翻译时强制使用
+	StyleProfile                *StyleProfile               // 任务选用的翻译风格，nil表示不指定
+	ExtraTargetLanguages        []StandardLanguageCode      // 主目标语言之外的其他目标语言
+	TtsVoiceCodes               map[string]string           // 按目标语言指定的配音音色
+	SubtitleFormats             map[string][]string         // 按字幕轨道（origin/target/bilingual）额外导出的字幕格式
+	KaraokeMode                 string                      // 原文卡拉OK字幕的模式 k/kf，为空不启用
+	KaraokeColor                string                      // 卡拉OK高亮颜色，ASS 格式
+	SubtitleStyle               *SubtitleStyle              // 字幕嵌入视频时使用的样式预设，nil表示使用默认样式
+	PendingMemories             *PendingTranslationMemories // 等待质检后写入的翻译记忆，nil表示不写回
+	LanguageStepParams          []*SubtitleTaskStepParam    // 其他目标语言各自的任务参数，文件输出在任务目录下的子目录
 }
 
 type SrtSentence struct {
@@ -401,6 +528,7 @@ type SubtitleInfo struct {
 	Uid         uint32 `json:"uid" gorm:"column:uid"`                                // 用户id
 	Name        string `json:"name" gorm:"column:name"`                              // 字幕名称
 	DownloadUrl string `json:"download_url" gorm:"column:download_url"`              // 字幕地址
+	Language    string `json:"language" gorm:"column:language"`                      // 文件对应的语言标识
 	CreateTime  int64  `json:"create_time" gorm:"column:create_time;autoCreateTime"` // 创建时间
 }
 
@@ -430,10 +558,11 @@ type SubtitleTask struct {
 }
 
 type Word struct {
-	Num   int
-	Text  string
-	Start float64
-	End   float64
+	Num        int
+	Text       string
+	Start      float64
+	End        float64
+	Confidence float64 // 识别置信度 0~1，为0表示转录服务未提供
 }
 
 type TranscriptionData struct {
@@ -0,0 +1,40 @@
+package types
+
+import "sync"
+
+// TranslationMemory 翻译记忆，按（归一化原文，目标语言，翻译风格）唯一，跨任务复用
+type TranslationMemory struct {
+	Id             uint64 `json:"id" gorm:"column:id;primaryKey"`                                                   // 自增id
+	SourceKey      string `json:"-" gorm:"column:source_key;uniqueIndex:idx_tm_source_target"`                      // 归一化后的原文，用于精确匹配
+	TargetLanguage string `json:"target_language" gorm:"column:target_language;uniqueIndex:idx_tm_source_target"`   // 目标语言
+	StyleProfileId uint64 `json:"style_profile_id" gorm:"column:style_profile_id;uniqueIndex:idx_tm_source_target"` // 产生译文时选用的翻译风格，0表示未指定，不同风格的译文互不复用
+	SourceLanguage string `json:"source_language" gorm:"column:source_language"`                                    // 原文语言
+	SourceText     string `json:"source_text" gorm:"column:source_text"`                                            // 原文
+	TargetText     string `json:"target_text" gorm:"column:target_text"`                                            // 译文
+	SourceLength   int    `json:"source_length" gorm:"column:source_length;index"`                                  // 原文字符数，模糊匹配时用于预筛选
+	TaskId         string `json:"task_id" gorm:"column:task_id"`                                                    // 产生该译文的任务，导入的记录为空
+	CreateTime     int64  `json:"create_time" gorm:"column:create_time;autoCreateTime"`                             // 创建时间
+	UpdateTime     int64  `json:"update_time" gorm:"column:update_time;autoUpdateTime"`                             // 更新时间
+}
+
+// PendingTranslationMemories 翻译过程中产生的翻译记忆，质检之后才写入，可以并发添加
+type PendingTranslationMemories struct {
+	mutex   sync.Mutex
+	entries []TranslationMemory
+}
+
+// Add 暂存翻译记忆
+func (p *PendingTranslationMemories) Add(entries ...TranslationMemory) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.entries = append(p.entries, entries...)
+}
+
+// Take 取出并清空暂存的翻译记忆
+func (p *PendingTranslationMemories) Take() []TranslationMemory {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	entries := p.entries
+	p.entries = nil
+	return entries
+}
@@ -0,0 +1,52 @@
+package types
+
+// 用量统计的阶段
+const (
+	UsageStageTranslate  = "translate"
+	UsageStageSplit      = "split"
+	UsageStageSummary    = "summary"
+	UsageStageClipper    = "clipper"
+	UsageStageTranscribe = "transcribe"
+	UsageStageTts        = "tts"
+)
+
+// TokenUsage 一次大模型调用的 token 用量
+type TokenUsage struct {
+	PromptTokens     int
+	CompletionTokens int
+}
+
+// UsageRecord 一次模型调用的用量及按价格表计算的费用
+type UsageRecord struct {
+	Id               uint64  `json:"id" gorm:"column:id;primaryKey"`                             // 自增id
+	TaskId           string  `json:"task_id" gorm:"column:task_id;index"`                        // 所属任务，不属于任务的调用为空
+	Stage            string  `json:"stage" gorm:"column:stage"`                                  // 阶段，见 UsageStage 常量
+	Provider         string  `json:"provider" gorm:"column:provider"`                            // 服务提供方，大模型固定为 llm
+	Model            string  `json:"model" gorm:"column:model"`                                  // 模型名
+	PromptTokens     int     `json:"prompt_tokens" gorm:"column:prompt_tokens"`                  // 输入 token
+	CompletionTokens int     `json:"completion_tokens" gorm:"column:completion_tokens"`          // 输出 token
+	AudioSeconds     float64 `json:"audio_seconds" gorm:"column:audio_seconds"`                  // 转录的音频时长
+	Characters       int     `json:"characters" gorm:"column:characters"`                        // 语音合成的字符数
+	Cost             float64 `json:"cost" gorm:"column:cost"`                                    // 费用，未配置价格时为0
+	Estimated        bool    `json:"estimated" gorm:"column:estimated"`                          // 服务端没有返回用量，token 按文本长度估算
+	CreateTime       int64   `json:"create_time" gorm:"column:create_time;autoCreateTime;index"` // 创建时间
+}
+
+// UsageTotal 用量汇总
+type UsageTotal struct {
+	Calls            int64
+	PromptTokens     int64
+	CompletionTokens int64
+	AudioSeconds     float64
+	Characters       int64
+	Cost             float64
+	EstimatedCalls   int64 // token 为估算值的调用次数
+}
+
+// UsageGroup 按提供方、模型、阶段分组的用量
+type UsageGroup struct {
+	Provider string
+	Model    string
+	Stage    string
+	UsageTotal
+}
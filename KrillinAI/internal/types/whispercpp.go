@@ -55,3 +55,27 @@ type WhispercppOutput struct {
 		} `json:"tokens"`
 	} `json:"transcription"`
 }
+
+// WhispercppServerOutput whisper.cpp server /inference 接口 response_format=verbose_json 的返回，
+// 兼容 faster-whisper 类服务返回的顶层 words
+type WhispercppServerOutput struct {
+	Task     string  `json:"task"`
+	Language string  `json:"language"`
+	Duration float64 `json:"duration"`
+	Text     string  `json:"text"`
+	Segments []struct {
+		Id    int                    `json:"id"`
+		Text  string                 `json:"text"`
+		Start float64                `json:"start"`
+		End   float64                `json:"end"`
+		Words []WhispercppServerWord `json:"words"`
+	} `json:"segments"`
+	Words []WhispercppServerWord `json:"words"`
+}
+
+type WhispercppServerWord struct {
+	Word        string  `json:"word"`
+	Start       float64 `json:"start"`
+	End         float64 `json:"end"`
+	Probability float64 `json:"probability"`
+}
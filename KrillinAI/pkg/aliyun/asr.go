@@ -91,7 +91,7 @@ type TaskResponse struct {
 	maxPollTime  time.Duration
 }
 
-func (c *AsrClient) Transcription(audioFile, language, workDir string) (*types.TranscriptionData, error) {
+func (c *AsrClient) Transcription(ctx context.Context, audioFile, language, workDir string) (*types.TranscriptionData, error) {
 	const (
 		postRequestAction = "SubmitTask"
 		getRequestAction  = "GetTaskResult"
@@ -111,7 +111,7 @@ func (c *AsrClient) Transcription(audioFile, language, workDir string) (*types.T
 
 	// 上传音频文件
 	fileKey := util.GenerateRandStringWithUpperLowerNum(5) + filepath.Ext(audioFile)
-	err = c.ossClient.UploadFile(context.Background(), fileKey, processedAudioFile, c.ossClient.Bucket)
+	err = c.ossClient.UploadFile(ctx, fileKey, processedAudioFile, c.ossClient.Bucket)
 	if err != nil {
 		log.GetLogger().Error("StartVideoSubtitleTask UploadFile err", zap.Any("audio file", audioFile), zap.Error(err))
 		return nil, errors.New("上传声音克隆源失败")
@@ -197,7 +197,11 @@ func (c *AsrClient) Transcription(audioFile, language, workDir string) (*types.T
 
 		switch getResult.StatusText {
 		case statusRunning, statusQueueing:
-			time.Sleep(c.pollInterval)
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(c.pollInterval):
+			}
 			continue
 		case statusSuccess:
 			if getResult.Result == nil || len(getResult.Result.Sentences) == 0 {
@@ -217,10 +221,11 @@ func (c *AsrClient) Transcription(audioFile, language, workDir string) (*types.T
 			if c.enableWords && getResult.Result.Words != nil {
 				for i, v := range getResult.Result.Words {
 					words = append(words, types.Word{
-						Num:   i,
-						Text:  strings.TrimSpace(v.Word), // 阿里云这边的word后面会有空格
-						Start: v.BeginTime / 1000,
-						End:   v.EndTime / 1000,
+						Num:        i,
+						Text:       strings.TrimSpace(v.Word), // 阿里云这边的word后面会有空格
+						Start:      v.BeginTime / 1000,
+						End:        v.EndTime / 1000,
+						Confidence: v.Confidence,
 					})
 				}
 			}
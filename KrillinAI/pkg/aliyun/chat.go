@@ -5,6 +5,7 @@ import (
 	goopenai "github.com/sashabaranov/go-openai"
 	"go.uber.org/zap"
 	"krillin-ai/log"
+	"krillin-ai/pkg/timeout"
 )
 
 type ChatClient struct {
@@ -14,12 +15,13 @@ type ChatClient struct {
 func NewChatClient(apiKey string) *ChatClient {
 	cfg := goopenai.DefaultConfig(apiKey)
 	cfg.BaseURL = "https://dashscope.aliyuncs.com/compatible-mode/v1" // 使用阿里云的openai兼容模式调用
+	cfg.HTTPClient = timeout.NewClient("llm", nil)
 	return &ChatClient{
 		Client: goopenai.NewClientWithConfig(cfg),
 	}
 }
 
-func (c ChatClient) ChatCompletion(query string) (string, error) {
+func (c ChatClient) ChatCompletion(ctx context.Context, query string) (string, error) {
 	req := goopenai.ChatCompletionRequest{
 		Model: "qwen-plus",
 		Messages: []goopenai.ChatCompletionMessage{
@@ -34,7 +36,7 @@ func (c ChatClient) ChatCompletion(query string) (string, error) {
 		},
 	}
 
-	resp, err := c.CreateChatCompletion(context.Background(), req)
+	resp, err := c.CreateChatCompletion(ctx, req)
 	if err != nil {
 		log.GetLogger().Error("aliyun openai create chat completion failed", zap.Error(err))
 		return "", err
@@ -1,6 +1,7 @@
 package aliyun
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/gorilla/websocket"
@@ -55,7 +56,7 @@ func NewTtsClient(accessKeyId, accessKeySecret, appkey string) *TtsClient {
 	}
 }
 
-func (c *TtsClient) Text2Speech(text, voice, outputFile string) error {
+func (c *TtsClient) Text2Speech(ctx context.Context, text, voice, outputFile string) error {
 	file, err := os.OpenFile(outputFile, os.O_CREATE|os.O_WRONLY, 0666)
 	if err != nil {
 		return fmt.Errorf("failed to create file: %w", err)
@@ -70,12 +71,15 @@ func (c *TtsClient) Text2Speech(text, voice, outputFile string) error {
 		dialer.Proxy = http.ProxyURL(config.Conf.App.ParsedProxy)
 	}
 	dialer.HandshakeTimeout = 10 * time.Second
-	conn, _, err = dialer.Dial(fullURL, nil)
+	conn, _, err = dialer.DialContext(ctx, fullURL, nil)
 	if err != nil {
 		return err
 	}
 	_ = conn.SetReadDeadline(time.Now().Add(time.Second * 60))
 	defer c.Close(conn)
+	// 任务取消时关闭连接，中断等待中的读写
+	stop := context.AfterFunc(ctx, func() { _ = conn.Close() })
+	defer stop()
 
 	onTextMessage := func(message string) {
 		log.GetLogger().Info("Received text message", zap.String("Message", message))
@@ -2,15 +2,16 @@ package doubao
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
 	"krillin-ai/log"
+	"krillin-ai/pkg/timeout"
 	"net/http"
 	"os"
 	"path/filepath"
-	"time"
 
 	"github.com/google/uuid"
 	"go.uber.org/zap"
@@ -66,7 +67,10 @@ type DoubaoTTSResponse struct {
 	Data    string `json:"data"` // Base64 encoded audio
 }
 
-func (c *DoubaoClient) Text2Speech(text, voice, outputFile string) error {
+// ttsHttpClient 按 tts 提供方的超时设置请求，所有客户端共享连接池
+var ttsHttpClient = timeout.NewClient("tts", nil)
+
+func (c *DoubaoClient) Text2Speech(ctx context.Context, text, voice, outputFile string) error {
 	// Ensure output directory exists
 	outputDir := filepath.Dir(outputFile)
 	if err := os.MkdirAll(outputDir, 0755); err != nil {
@@ -97,7 +101,7 @@ func (c *DoubaoClient) Text2Speech(text, voice, outputFile string) error {
 		return fmt.Errorf("marshal request failed: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", c.BaseURL, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", c.BaseURL, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return fmt.Errorf("create request failed: %w", err)
 	}
@@ -111,8 +115,7 @@ func (c *DoubaoClient) Text2Speech(text, voice, outputFile string) error {
 	req.Header.Set("X-Api-Resource-Id", c.ResourceId)
 	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{Timeout: 60 * time.Second}
-	resp, err := client.Do(req)
+	resp, err := ttsHttpClient.Do(req)
 	if err != nil {
 		return fmt.Errorf("http request failed: %w", err)
 	}
@@ -1,11 +1,13 @@
 package fasterwhisper
 
 import (
+	"context"
 	"encoding/json"
 	"krillin-ai/config"
 	"krillin-ai/internal/storage"
 	"krillin-ai/internal/types"
 	"krillin-ai/log"
+	"krillin-ai/pkg/timeout"
 	"krillin-ai/pkg/util"
 	"os"
 	"os/exec"
@@ -14,7 +16,9 @@ import (
 	"go.uber.org/zap"
 )
 
-func (c *FastwhisperProcessor) Transcription(audioFile, language, workDir string) (*types.TranscriptionData, error) {
+func (c *FastwhisperProcessor) Transcription(ctx context.Context, audioFile, language, workDir string) (*types.TranscriptionData, error) {
+	ctx, cancel := timeout.WithOverall(ctx, "transcribe")
+	defer cancel()
 	cmdArgs := []string{
 		"--model_dir", "./models/",
 		"--model", c.Model,
@@ -35,7 +39,7 @@ func (c *FastwhisperProcessor) Transcription(audioFile, language, workDir string
 		log.GetLogger().Info("FastwhisperProcessor启用GPU加速", zap.String("model", c.Model))
 	}
 
-	cmd := exec.Command(storage.FasterwhisperPath, cmdArgs...)
+	cmd := exec.CommandContext(ctx, storage.FasterwhisperPath, cmdArgs...)
 	
 	// Set HF_ENDPOINT for faster-whisper subprocess to use mirror
 	// os.Setenv doesn't propagate to child processes, so we must set cmd.Env explicitly
@@ -75,25 +79,28 @@ func (c *FastwhisperProcessor) Transcription(audioFile, language, workDir string
 				seperatedWords := strings.Split(word.Word, "—")
 				transcriptionData.Words = append(transcriptionData.Words, []types.Word{
 					{
-						Num:   num,
-						Text:  util.CleanPunction(strings.TrimSpace(seperatedWords[0])),
-						Start: word.Start,
-						End:   mid,
+						Num:        num,
+						Text:       util.CleanPunction(strings.TrimSpace(seperatedWords[0])),
+						Start:      word.Start,
+						End:        mid,
+						Confidence: word.Probability,
 					},
 					{
-						Num:   num + 1,
-						Text:  util.CleanPunction(strings.TrimSpace(seperatedWords[1])),
-						Start: mid,
-						End:   word.End,
+						Num:        num + 1,
+						Text:       util.CleanPunction(strings.TrimSpace(seperatedWords[1])),
+						Start:      mid,
+						End:        word.End,
+						Confidence: word.Probability,
 					},
 				}...)
 				num += 2
 			} else {
 				transcriptionData.Words = append(transcriptionData.Words, types.Word{
-					Num:   num,
-					Text:  util.CleanPunction(strings.TrimSpace(word.Word)),
-					Start: word.Start,
-					End:   word.End,
+					Num:        num,
+					Text:       util.CleanPunction(strings.TrimSpace(word.Word)),
+					Start:      word.Start,
+					End:        word.End,
+					Confidence: word.Probability,
 				})
 				num++
 			}
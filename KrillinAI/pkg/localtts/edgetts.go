@@ -6,6 +6,7 @@ import (
 	"io/ioutil"
 	"krillin-ai/internal/storage"
 	"krillin-ai/log"
+	"krillin-ai/pkg/timeout"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -22,7 +23,7 @@ func NewEdgeTtsClient() *EdgeTtsClient {
 	return &EdgeTtsClient{}
 }
 
-func (c *EdgeTtsClient) Text2Speech(text, voice, outputFile string) error {
+func (c *EdgeTtsClient) Text2Speech(ctx context.Context, text, voice, outputFile string) error {
 	// 清理语音名称中的额外空格
 	voice = strings.TrimSpace(voice)
 
@@ -72,7 +73,7 @@ func (c *EdgeTtsClient) Text2Speech(text, voice, outputFile string) error {
 			zap.Int("maxRetries", maxRetries),
 			zap.String("text_length", fmt.Sprintf("%d", len(text))))
 
-		err := c.attemptTTS(tempFileName, voice, absOutputFile, attempt)
+		err := c.attemptTTS(ctx, tempFileName, voice, absOutputFile, attempt)
 		if err == nil {
 			// 成功生成
 			log.GetLogger().Info("edge-tts转录完成", zap.String("output file", absOutputFile))
@@ -87,18 +88,22 @@ func (c *EdgeTtsClient) Text2Speech(text, voice, outputFile string) error {
 			zap.Int("attempt", attempt),
 			zap.Error(err))
 
-		// 如果不是最后一次尝试，等待一段时间再重试
+		// 如果不是最后一次尝试，等待一段时间再重试，任务取消时不再重试
 		if attempt < maxRetries {
 			waitTime := time.Duration(attempt) * 2 * time.Second
 			log.GetLogger().Info("等待重试", zap.Duration("waitTime", waitTime))
-			time.Sleep(waitTime)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(waitTime):
+			}
 		}
 	}
 
 	return fmt.Errorf("edge-tts转录失败，已重试%d次", maxRetries)
 }
 
-func (c *EdgeTtsClient) attemptTTS(tempFileName, voice, absOutputFile string, attempt int) error {
+func (c *EdgeTtsClient) attemptTTS(ctx context.Context, tempFileName, voice, absOutputFile string, attempt int) error {
 	// 使用新的edge-tts命令参数（文件输入方式）
 	cmdArgs := []string{
 		"--text-file", tempFileName,
@@ -108,8 +113,12 @@ func (c *EdgeTtsClient) attemptTTS(tempFileName, voice, absOutputFile string, at
 		"--sample_rate", "44100",
 	}
 
-	// 创建带超时的上下文
-	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second) // 60秒超时
+	// 创建带超时的上下文，未配置 tts 的总超时时每次尝试最多60秒
+	attemptTimeout := timeout.For("tts").Overall
+	if attemptTimeout <= 0 {
+		attemptTimeout = 60 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, attemptTimeout)
 	defer cancel()
 
 	cmd := exec.CommandContext(ctx, storage.EdgeTtsPath, cmdArgs...)
@@ -2,15 +2,16 @@ package minimax
 
 import (
 	"bytes"
+	"context"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"krillin-ai/log"
+	"krillin-ai/pkg/timeout"
 	"net/http"
 	"os"
 	"path/filepath"
-	"time"
 
 	"go.uber.org/zap"
 )
@@ -67,7 +68,10 @@ type BaseResp struct {
 	StatusMsg  string `json:"status_msg"`
 }
 
-func (c *MiniMaxClient) Text2Speech(text, voice, outputFile string) error {
+// ttsHttpClient 按 tts 提供方的超时设置请求，所有客户端共享连接池
+var ttsHttpClient = timeout.NewClient("tts", nil)
+
+func (c *MiniMaxClient) Text2Speech(ctx context.Context, text, voice, outputFile string) error {
 	// 确保输出目录存在
 	outputDir := filepath.Dir(outputFile)
 	if err := os.MkdirAll(outputDir, 0755); err != nil {
@@ -101,7 +105,7 @@ func (c *MiniMaxClient) Text2Speech(text, voice, outputFile string) error {
 		return fmt.Errorf("marshal request failed: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return fmt.Errorf("create request failed: %w", err)
 	}
@@ -109,8 +113,7 @@ func (c *MiniMaxClient) Text2Speech(text, voice, outputFile string) error {
 	req.Header.Set("Authorization", "Bearer "+c.ApiKey)
 	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{Timeout: 60 * time.Second}
-	resp, err := client.Do(req)
+	resp, err := ttsHttpClient.Do(req)
 	if err != nil {
 		return fmt.Errorf("http request failed: %w", err)
 	}
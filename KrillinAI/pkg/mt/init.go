@@ -0,0 +1,38 @@
+package mt
+
+import (
+	"krillin-ai/config"
+	"krillin-ai/pkg/timeout"
+	"net/http"
+	"strings"
+)
+
+const (
+	ApiLibreTranslate = "libretranslate"
+	ApiDeepl          = "deepl"
+)
+
+// Client 通用机器翻译 HTTP 客户端，支持 DeepL 和 LibreTranslate 风格的接口，
+// 自建或兼容这两种协议的服务都可以直接使用
+type Client struct {
+	Api        string
+	BaseUrl    string
+	ApiKey     string
+	httpClient *http.Client
+}
+
+func NewClient(api, baseUrl, apiKey, proxyAddr string) *Client {
+	if api == "" {
+		api = ApiLibreTranslate
+	}
+	transport := &http.Transport{}
+	if proxyAddr != "" {
+		transport.Proxy = http.ProxyURL(config.Conf.App.ParsedProxy)
+	}
+	return &Client{
+		Api:        api,
+		BaseUrl:    strings.TrimSuffix(baseUrl, "/"),
+		ApiKey:     apiKey,
+		httpClient: timeout.NewClient("mt", transport),
+	}
+}
@@ -0,0 +1,175 @@
+package mt
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"krillin-ai/internal/types"
+	"krillin-ai/log"
+	"net/http"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+type deeplRequest struct {
+	Text       []string `json:"text"`
+	SourceLang string   `json:"source_lang,omitempty"`
+	TargetLang string   `json:"target_lang"`
+}
+
+type deeplResponse struct {
+	Translations []struct {
+		Text string `json:"text"`
+	} `json:"translations"`
+}
+
+type libreTranslateRequest struct {
+	Q      []string `json:"q"`
+	Source string   `json:"source"`
+	Target string   `json:"target"`
+	Format string   `json:"format"`
+	ApiKey string   `json:"api_key,omitempty"`
+}
+
+type libreTranslateResponse struct {
+	TranslatedText []string `json:"translatedText"`
+	Error          string   `json:"error"`
+}
+
+// Translate 实现 types.Translator，机器翻译不支持附加要求，instructions 会被忽略
+func (c *Client) Translate(ctx context.Context, texts []string, sourceLanguage, targetLanguage types.StandardLanguageCode, instructions string) ([]string, error) {
+	if len(texts) == 0 {
+		return []string{}, nil
+	}
+	var (
+		result []string
+		err    error
+	)
+	switch c.Api {
+	case ApiDeepl:
+		result, err = c.translateByDeepl(ctx, texts, sourceLanguage, targetLanguage)
+	case ApiLibreTranslate:
+		result, err = c.translateByLibreTranslate(ctx, texts, sourceLanguage, targetLanguage)
+	default:
+		return nil, fmt.Errorf("mt unsupported api: %s", c.Api)
+	}
+	if err != nil {
+		log.GetLogger().Error("mt translate failed", zap.String("api", c.Api), zap.String("target", string(targetLanguage)), zap.Error(err))
+		return nil, err
+	}
+	if len(result) != len(texts) {
+		return nil, fmt.Errorf("mt translate size mismatch: expected %d, got %d", len(texts), len(result))
+	}
+	return result, nil
+}
+
+func (c *Client) translateByDeepl(ctx context.Context, texts []string, sourceLanguage, targetLanguage types.StandardLanguageCode) ([]string, error) {
+	reqBody := deeplRequest{
+		Text:       texts,
+		SourceLang: deeplSourceLanguage(sourceLanguage),
+		TargetLang: deeplTargetLanguage(targetLanguage),
+	}
+	headers := map[string]string{}
+	if c.ApiKey != "" {
+		headers["Authorization"] = "DeepL-Auth-Key " + c.ApiKey
+	}
+	var resp deeplResponse
+	if err := c.postJson(ctx, "/v2/translate", reqBody, headers, &resp); err != nil {
+		return nil, err
+	}
+	result := make([]string, 0, len(resp.Translations))
+	for _, translation := range resp.Translations {
+		result = append(result, translation.Text)
+	}
+	return result, nil
+}
+
+func (c *Client) translateByLibreTranslate(ctx context.Context, texts []string, sourceLanguage, targetLanguage types.StandardLanguageCode) ([]string, error) {
+	reqBody := libreTranslateRequest{
+		Q:      texts,
+		Source: libreTranslateLanguage(sourceLanguage),
+		Target: libreTranslateLanguage(targetLanguage),
+		Format: "text",
+		ApiKey: c.ApiKey,
+	}
+	if reqBody.Source == "" {
+		reqBody.Source = "auto"
+	}
+	var resp libreTranslateResponse
+	if err := c.postJson(ctx, "/translate", reqBody, nil, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Error != "" {
+		return nil, fmt.Errorf("libretranslate error: %s", resp.Error)
+	}
+	return resp.TranslatedText, nil
+}
+
+func (c *Client) postJson(ctx context.Context, path string, body any, headers map[string]string, result any) error {
+	bodyBytes, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("mt marshal request err: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.BaseUrl+path, bytes.NewReader(bodyBytes))
+	if err != nil {
+		return fmt.Errorf("mt create request err: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("mt request err: %w", err)
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("mt read response err: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("mt server returned status %d: %s", resp.StatusCode, string(respBody))
+	}
+	if err = json.Unmarshal(respBody, result); err != nil {
+		return fmt.Errorf("mt unmarshal response err: %w", err)
+	}
+	return nil
+}
+
+// baseLanguage 去掉地区部分，如 zh_cn -> zh
+func baseLanguage(language types.StandardLanguageCode) string {
+	code, _, _ := strings.Cut(string(language), "_")
+	return code
+}
+
+// deeplSourceLanguage DeepL 的源语言不区分地区，留空时自动识别
+func deeplSourceLanguage(language types.StandardLanguageCode) string {
+	return strings.ToUpper(baseLanguage(language))
+}
+
+// deeplTargetLanguage DeepL 的部分目标语言必须指定变体
+func deeplTargetLanguage(language types.StandardLanguageCode) string {
+	switch language {
+	case types.LanguageNameSimplifiedChinese:
+		return "ZH-HANS"
+	case types.LanguageNameTraditionalChinese:
+		return "ZH-HANT"
+	case types.LanguageNameEnglish:
+		return "EN-US"
+	case types.LanguageNamePortuguese:
+		return "PT-PT"
+	}
+	return strings.ToUpper(baseLanguage(language))
+}
+
+// libreTranslateLanguage LibreTranslate 使用 ISO 639-1 代码，繁体中文为 zt
+func libreTranslateLanguage(language types.StandardLanguageCode) string {
+	if language == types.LanguageNameTraditionalChinese {
+		return "zt"
+	}
+	return baseLanguage(language)
+}
@@ -0,0 +1,80 @@
+package mt
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"krillin-ai/internal/types"
+	"krillin-ai/log"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	log.InitLogger()
+}
+
+func TestTranslate_LibreTranslate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/translate", r.URL.Path)
+		var req libreTranslateRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, []string{"Hello", "Goodbye"}, req.Q)
+		assert.Equal(t, "en", req.Source)
+		assert.Equal(t, "zt", req.Target)
+		assert.Equal(t, "secret", req.ApiKey)
+		_ = json.NewEncoder(w).Encode(map[string]any{"translatedText": []string{"你好", "再見"}})
+	}))
+	defer server.Close()
+
+	client := NewClient(ApiLibreTranslate, server.URL+"/", "secret", "")
+	result, err := client.Translate(context.Background(), []string{"Hello", "Goodbye"}, types.LanguageNameEnglish, types.LanguageNameTraditionalChinese, "ignored")
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"你好", "再見"}, result)
+}
+
+func TestTranslate_Deepl(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v2/translate", r.URL.Path)
+		assert.Equal(t, "DeepL-Auth-Key secret", r.Header.Get("Authorization"))
+		var req deeplRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "EN", req.SourceLang)
+		assert.Equal(t, "ZH-HANS", req.TargetLang)
+		_ = json.NewEncoder(w).Encode(map[string]any{"translations": []map[string]string{{"text": "你好"}}})
+	}))
+	defer server.Close()
+
+	client := NewClient(ApiDeepl, server.URL, "secret", "")
+	result, err := client.Translate(context.Background(), []string{"Hello"}, types.LanguageNameEnglish, types.LanguageNameSimplifiedChinese, "")
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"你好"}, result)
+}
+
+func TestTranslate_ErrorStatusAndSizeMismatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req libreTranslateRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		if req.ApiKey == "bad" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"error":"invalid api key"}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"translatedText": []string{"only one"}})
+	}))
+	defer server.Close()
+
+	client := NewClient(ApiLibreTranslate, server.URL, "", "")
+	_, err := client.Translate(context.Background(), []string{"a", "b"}, types.LanguageNameEnglish, types.LanguageNameGerman, "")
+	assert.ErrorContains(t, err, "size mismatch")
+
+	client.ApiKey = "bad"
+	_, err = client.Translate(context.Background(), []string{"a"}, types.LanguageNameEnglish, types.LanguageNameGerman, "")
+	assert.ErrorContains(t, err, "status 403")
+}
@@ -1,13 +1,24 @@
 package openai
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"github.com/sashabaranov/go-openai"
+	"io"
 	"krillin-ai/config"
+	"krillin-ai/pkg/ratelimit"
+	"krillin-ai/pkg/timeout"
 	"net/http"
+	"time"
 )
 
 type Client struct {
-	client *openai.Client
+	client        *openai.Client
+	limiter       *ratelimit.Limiter // 为nil时不限流
+	model         string             // 为空时使用 llm.model
+	timeout       *timeout.Transport
+	ttsHttpClient *http.Client
 }
 
 func NewClient(baseUrl, apiKey, proxyAddr string) *Client {
@@ -16,17 +27,102 @@ func NewClient(baseUrl, apiKey, proxyAddr string) *Client {
 		cfg.BaseURL = baseUrl
 	}
 
-	// 总是配置自定义 HTTP Client 以设置超时
 	transport := &http.Transport{}
 	if proxyAddr != "" {
 		transport.Proxy = http.ProxyURL(config.Conf.App.ParsedProxy)
 	}
-
+	// 不设置 http.Client 的总超时，Thinking 模型可能需要很长时间；超时按 timeout 配置，流式响应长时间没有数据时中断
+	timeoutTransport := timeout.NewTransport("llm", transport)
 	cfg.HTTPClient = &http.Client{
-		Transport: transport,
-		// 不设置超时，允许长时间运行的翻译请求（Thinking 模型可能需要 30+ 分钟）
+		Transport: &extraBodyTransport{base: &retryAfterTransport{base: timeoutTransport}},
 	}
 
 	client := openai.NewClientWithConfig(cfg)
-	return &Client{client: client}
+	return &Client{client: client, timeout: timeoutTransport, ttsHttpClient: &http.Client{Transport: timeoutTransport}}
+}
+
+// WithModel 指定该客户端使用的模型
+func (c *Client) WithModel(model string) *Client {
+	c.model = model
+	return c
+}
+
+// WithTimeout 使用提供方的超时设置，默认为 llm
+func (c *Client) WithTimeout(provider string) *Client {
+	c.timeout.Provider = provider
+	return c
+}
+
+// WithRateLimit 使用提供方的进程级限流器，同一提供方的所有客户端共享额度
+func (c *Client) WithRateLimit(provider string) *Client {
+	c.limiter = ratelimit.For(provider)
+	return c
+}
+
+type extraBodyKey struct{}
+
+func withExtraBody(ctx context.Context, fields map[string]any) context.Context {
+	return context.WithValue(ctx, extraBodyKey{}, fields)
+}
+
+// extraBodyTransport 把 context 中的额外字段合并进 JSON 请求体，用于 SDK 还不支持的参数
+type extraBodyTransport struct {
+	base http.RoundTripper
+}
+
+func (t *extraBodyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	fields, ok := req.Context().Value(extraBodyKey{}).(map[string]any)
+	if !ok || len(fields) == 0 || req.Body == nil {
+		return t.base.RoundTrip(req)
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var payload map[string]any
+	if err = decoder.Decode(&payload); err == nil {
+		for key, value := range fields {
+			payload[key] = value
+		}
+		if merged, marshalErr := json.Marshal(payload); marshalErr == nil {
+			body = merged
+		}
+	}
+
+	newReq := req.Clone(req.Context())
+	newReq.Body = io.NopCloser(bytes.NewReader(body))
+	newReq.ContentLength = int64(len(body))
+	newReq.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	return t.base.RoundTrip(newReq)
+}
+
+type retryAfterKey struct{}
+
+type retryAfterHolder struct {
+	value time.Duration
+}
+
+func withRetryAfter(ctx context.Context, holder *retryAfterHolder) context.Context {
+	return context.WithValue(ctx, retryAfterKey{}, holder)
+}
+
+// retryAfterTransport SDK 返回的错误不带响应头，在这里记下限流响应的 Retry-After
+type retryAfterTransport struct {
+	base http.RoundTripper
+}
+
+func (t *retryAfterTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || !ratelimit.RetryableStatus(resp.StatusCode) {
+		return resp, err
+	}
+	if holder, ok := req.Context().Value(retryAfterKey{}).(*retryAfterHolder); ok {
+		holder.value = ratelimit.ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+	}
+	return resp, nil
 }
@@ -3,47 +3,145 @@ package openai
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	openai "github.com/sashabaranov/go-openai"
 	"go.uber.org/zap"
 	"io"
 	"krillin-ai/config"
+	"krillin-ai/internal/types"
 	"krillin-ai/log"
+	"krillin-ai/pkg/ratelimit"
 	"net/http"
 	"os"
 	"strings"
+	"time"
 )
 
-func (c *Client) ChatCompletion(query string) (string, error) {
-	var responseFormat *openai.ChatCompletionResponseFormat
+func (c *Client) ChatCompletion(ctx context.Context, query string) (string, error) {
+	return c.Chat(ctx, &types.ChatRequest{
+		Messages: []types.ChatMessage{
+			{Role: types.ChatRoleSystem, Content: "You are an assistant that helps with subtitle translation."},
+			{Role: types.ChatRoleUser, Content: query},
+		},
+		Temperature: 0.9,
+		MaxTokens:   8192,
+	})
+}
+
+// Chat 按完整参数流式调用大模型，返回拼接后的回复
+func (c *Client) Chat(ctx context.Context, chatReq *types.ChatRequest) (string, error) {
+	content, _, err := c.ChatWithUsage(ctx, chatReq)
+	return content, err
+}
 
+// ChatWithUsage 同 Chat，并返回服务端统计的 token 用量，服务端不返回用量时为nil
+func (c *Client) ChatWithUsage(ctx context.Context, chatReq *types.ChatRequest) (string, *types.TokenUsage, error) {
+	model := c.model
+	if model == "" {
+		model = config.Conf.Llm.Model
+	}
 	req := openai.ChatCompletionRequest{
-		Model: config.Conf.Llm.Model,
-		Messages: []openai.ChatCompletionMessage{
-			{
-				Role:    openai.ChatMessageRoleSystem,
-				Content: "You are an assistant that helps with subtitle translation.",
-			},
-			{
-				Role:    openai.ChatMessageRoleUser,
-				Content: query,
+		Model:       model,
+		Messages:    make([]openai.ChatCompletionMessage, 0, len(chatReq.Messages)),
+		Temperature: chatReq.Temperature,
+		Stream:      true,
+		MaxTokens:   chatReq.MaxTokens,
+		Seed:        chatReq.Seed,
+	}
+	for _, message := range chatReq.Messages {
+		req.Messages = append(req.Messages, openai.ChatCompletionMessage{Role: message.Role, Content: message.Content})
+	}
+	switch chatReq.ResponseFormat {
+	case types.ChatResponseFormatJsonObject:
+		req.ResponseFormat = &openai.ChatCompletionResponseFormat{Type: openai.ChatCompletionResponseFormatTypeJSONObject}
+	case types.ChatResponseFormatJsonSchema:
+		if chatReq.JsonSchema == nil {
+			return "", nil, fmt.Errorf("openai chat json_schema response format requires a schema")
+		}
+		req.ResponseFormat = &openai.ChatCompletionResponseFormat{
+			Type: openai.ChatCompletionResponseFormatTypeJSONSchema,
+			JSONSchema: &openai.ChatCompletionResponseFormatJSONSchema{
+				Name:   chatReq.JsonSchema.Name,
+				Schema: chatReq.JsonSchema.Schema,
+				Strict: chatReq.JsonSchema.Strict,
 			},
-		},
-		Temperature:    0.9,
-		Stream:         true,
-		MaxTokens:      8192,
-		ResponseFormat: responseFormat,
+		}
+	}
+
+	if config.Conf.Usage.StreamUsage {
+		req.StreamOptions = &openai.StreamOptions{IncludeUsage: true}
+	}
+
+	if chatReq.ReasoningEffort != "" {
+		// 当前 SDK 版本的请求结构没有 reasoning_effort，由 transport 合并进请求体
+		ctx = withExtraBody(ctx, map[string]any{"reasoning_effort": chatReq.ReasoningEffort})
 	}
 
-	log.GetLogger().Info("Calling OpenAI Stream", zap.String("model", req.Model))
-	stream, err := c.client.CreateChatCompletionStream(context.Background(), req)
+	promptTokens := 0
+	for _, message := range chatReq.Messages {
+		promptTokens += ratelimit.EstimateTokens(message.Content)
+	}
+	estimatedTokens := ratelimit.EstimateRequestTokens(promptTokens, chatReq.MaxTokens)
+	for attempt := 0; ; attempt++ {
+		reservation, err := c.limiter.Wait(ctx, estimatedTokens)
+		if err != nil {
+			return "", nil, err
+		}
+		retryAfter := &retryAfterHolder{}
+		content, usage, err := c.chatStream(withRetryAfter(ctx, retryAfter), req, chatReq.ResponseFormat)
+		if usage != nil {
+			reservation.Settle(usage.PromptTokens + usage.CompletionTokens)
+		}
+		if err == nil {
+			return content, usage, nil
+		}
+		statusCode := errorStatusCode(err)
+		if !ratelimit.RetryableStatus(statusCode) || attempt >= config.Conf.RateLimit.MaxRetries {
+			return "", nil, err
+		}
+		wait := retryAfter.value
+		if wait > 0 {
+			// 服务端明确要求等待时，同一提供方的其他请求也一起暂停
+			c.limiter.Block(wait)
+		} else {
+			wait = ratelimit.Backoff(attempt)
+		}
+		log.GetLogger().Warn("openai chat retry", zap.Int("statusCode", statusCode), zap.Int("attempt", attempt+1), zap.Duration("wait", wait), zap.Error(err))
+		select {
+		case <-ctx.Done():
+			return "", nil, ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// errorStatusCode 取出接口错误的 HTTP 状态码，非接口错误返回0
+func errorStatusCode(err error) int {
+	var apiErr *openai.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.HTTPStatusCode
+	}
+	var reqErr *openai.RequestError
+	if errors.As(err, &reqErr) {
+		return reqErr.HTTPStatusCode
+	}
+	return 0
+}
+
+func (c *Client) chatStream(ctx context.Context, req openai.ChatCompletionRequest, responseFormat string) (string, *types.TokenUsage, error) {
+	log.GetLogger().Info("Calling OpenAI Stream", zap.String("model", req.Model), zap.String("responseFormat", responseFormat))
+	stream, err := c.client.CreateChatCompletionStream(ctx, req)
 	if err != nil {
 		log.GetLogger().Error("openai create chat completion stream failed", zap.Error(err))
-		return "", err
+		return "", nil, err
 	}
 	defer stream.Close()
 
-	var resContent string
+	var (
+		resContent string
+		usage      *types.TokenUsage
+	)
 	for {
 		response, err := stream.Recv()
 		if err == io.EOF {
@@ -51,20 +149,26 @@ func (c *Client) ChatCompletion(query string) (string, error) {
 		}
 		if err != nil {
 			log.GetLogger().Error("openai stream receive failed", zap.Error(err))
-			return "", err
+			return "", nil, err
+		}
+		// 开启 include_usage 后，用量在最后一个 choices 为空的分片中返回
+		if response.Usage != nil {
+			usage = &types.TokenUsage{PromptTokens: response.Usage.PromptTokens, CompletionTokens: response.Usage.CompletionTokens}
 		}
 		if len(response.Choices) == 0 {
-			log.GetLogger().Info("openai stream receive no choices", zap.Any("response", response))
+			if response.Usage == nil {
+				log.GetLogger().Info("openai stream receive no choices", zap.Any("response", response))
+			}
 			continue
 		}
 
 		resContent += response.Choices[0].Delta.Content
 	}
 
-	return resContent, nil
+	return resContent, usage, nil
 }
 
-func (c *Client) Text2Speech(text, voice string, outputFile string) error {
+func (c *Client) Text2Speech(ctx context.Context, text, voice string, outputFile string) error {
 	baseUrl := config.Conf.Tts.Openai.BaseUrl
 	if baseUrl == "" {
 		baseUrl = "https://api.openai.com/v1"
@@ -78,7 +182,7 @@ func (c *Client) Text2Speech(text, voice string, outputFile string) error {
 		"voice":"%s",
 		"response_format": "wav"
 	}`, text, voice)
-	req, err := http.NewRequest("POST", url, strings.NewReader(reqBody))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, strings.NewReader(reqBody))
 	if err != nil {
 		return err
 	}
@@ -87,8 +191,7 @@ func (c *Client) Text2Speech(text, voice string, outputFile string) error {
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", config.Conf.Tts.Openai.ApiKey))
 
 	// 发送HTTP请求
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := c.ttsHttpClient.Do(req)
 	if err != nil {
 		return err
 	}
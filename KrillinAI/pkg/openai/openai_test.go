@@ -0,0 +1,185 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"krillin-ai/config"
+	"krillin-ai/internal/types"
+	"krillin-ai/log"
+	"krillin-ai/pkg/timeout"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	log.InitLogger()
+}
+
+func TestChat_SendsRequestParameters(t *testing.T) {
+	var body map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, part := range []string{`{\"translations\":`, `[\"你好\"]}`} {
+			fmt.Fprintf(w, "data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\"%s\"}}]}\n\n", part)
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	seed := 42
+	client := NewClient(server.URL, "key", "")
+	result, err := client.Chat(context.Background(), &types.ChatRequest{
+		Messages:        []types.ChatMessage{{Role: types.ChatRoleUser, Content: "hi"}},
+		Temperature:     0.2,
+		MaxTokens:       100,
+		ResponseFormat:  types.ChatResponseFormatJsonSchema,
+		JsonSchema:      &types.ChatJsonSchema{Name: "out", Schema: json.RawMessage(`{"type":"object"}`), Strict: true},
+		Seed:            &seed,
+		ReasoningEffort: "high",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, `{"translations":["你好"]}`, result)
+	assert.Equal(t, "high", body["reasoning_effort"])
+	assert.EqualValues(t, 42, body["seed"])
+	assert.EqualValues(t, 100, body["max_tokens"])
+	assert.InDelta(t, 0.2, body["temperature"], 0.0001)
+	responseFormat := body["response_format"].(map[string]any)
+	assert.Equal(t, "json_schema", responseFormat["type"])
+	assert.Equal(t, "out", responseFormat["json_schema"].(map[string]any)["name"])
+}
+
+func TestChatCompletion_KeepsDefaultParameters(t *testing.T) {
+	var body map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\"ok\"}}]}\n\ndata: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	result, err := NewClient(server.URL, "key", "").ChatCompletion(context.Background(), "hi")
+
+	require.NoError(t, err)
+	assert.Equal(t, "ok", result)
+	assert.NotContains(t, body, "response_format")
+	assert.NotContains(t, body, "reasoning_effort")
+	assert.EqualValues(t, 8192, body["max_tokens"])
+	assert.Len(t, body["messages"], 2)
+}
+
+func TestChat_UsesClientModel(t *testing.T) {
+	oldModel := config.Conf.Llm.Model
+	defer func() { config.Conf.Llm.Model = oldModel }()
+	config.Conf.Llm.Model = "default-model"
+	var models []any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		models = append(models, body["model"])
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\"ok\"}}]}\n\ndata: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	_, err := NewClient(server.URL, "key", "").ChatCompletion(context.Background(), "hi")
+	require.NoError(t, err)
+	_, err = NewClient(server.URL, "key", "").WithModel("small-model").ChatCompletion(context.Background(), "hi")
+	require.NoError(t, err)
+
+	assert.Equal(t, []any{"default-model", "small-model"}, models)
+}
+
+func TestChatWithUsage_ReadsUsageChunk(t *testing.T) {
+	oldStreamUsage := config.Conf.Usage.StreamUsage
+	defer func() { config.Conf.Usage.StreamUsage = oldStreamUsage }()
+	config.Conf.Usage.StreamUsage = true
+	var body map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\"ok\"}}]}\n\n")
+		fmt.Fprint(w, "data: {\"choices\":[],\"usage\":{\"prompt_tokens\":12,\"completion_tokens\":3,\"total_tokens\":15}}\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	result, usage, err := NewClient(server.URL, "key", "").ChatWithUsage(context.Background(), &types.ChatRequest{
+		Messages: []types.ChatMessage{{Role: types.ChatRoleUser, Content: "hi"}},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "ok", result)
+	require.NotNil(t, usage)
+	assert.Equal(t, types.TokenUsage{PromptTokens: 12, CompletionTokens: 3}, *usage)
+	assert.Equal(t, map[string]any{"include_usage": true}, body["stream_options"])
+}
+
+func TestChat_RetriesRateLimitedRequestWithRetryAfter(t *testing.T) {
+	oldRateLimit := config.Conf.RateLimit
+	defer func() { config.Conf.RateLimit = oldRateLimit }()
+	config.Conf.RateLimit.MaxRetries = 2
+
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.Header().Set("Retry-After", "0.05")
+			w.WriteHeader(http.StatusTooManyRequests)
+			fmt.Fprint(w, `{"error":{"message":"rate limited","type":"rate_limit_error"}}`)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\"ok\"}}]}\n\ndata: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "key", "").WithRateLimit("test_retry")
+	start := time.Now()
+	result, err := client.ChatCompletion(context.Background(), "hi")
+
+	require.NoError(t, err)
+	assert.Equal(t, "ok", result)
+	assert.Equal(t, 2, calls)
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+}
+
+func TestChat_DoesNotRetryClientErrors(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"error":{"message":"bad request","type":"invalid_request_error"}}`)
+	}))
+	defer server.Close()
+
+	_, err := NewClient(server.URL, "key", "").ChatCompletion(context.Background(), "hi")
+
+	assert.Error(t, err)
+	assert.Equal(t, 1, calls)
+}
+
+func TestChatCompletion_AbortsStalledStream(t *testing.T) {
+	oldTimeout := config.Conf.Timeout
+	defer func() { config.Conf.Timeout = oldTimeout }()
+	config.Conf.Timeout = config.TimeoutConfig{Providers: []config.TimeoutProvider{{Name: "stalled", IdleSeconds: 1}}}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\"o\"}}]}\n\n")
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	_, err := NewClient(server.URL, "key", "").WithTimeout("stalled").ChatCompletion(context.Background(), "hi")
+
+	assert.ErrorIs(t, err, timeout.ErrIdleTimeout)
+}
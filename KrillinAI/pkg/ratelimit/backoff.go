@@ -0,0 +1,68 @@
+package ratelimit
+
+import (
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"krillin-ai/config"
+)
+
+// Backoff 第 attempt 次重试（从0开始）前的等待时间：指数增长并加随机抖动，避免多个请求同时重试
+func Backoff(attempt int) time.Duration {
+	base := time.Duration(config.Conf.RateLimit.BaseBackoffMs) * time.Millisecond
+	maxBackoff := time.Duration(config.Conf.RateLimit.MaxBackoffSeconds) * time.Second
+	if base <= 0 {
+		return 0
+	}
+	d := base << min(attempt, 16)
+	if maxBackoff > 0 && d > maxBackoff {
+		d = maxBackoff
+	}
+	// 取 [d/2, d] 之间的随机值
+	return d/2 + rand.N(d/2+1)
+}
+
+// ParseRetryAfter 解析 Retry-After 头，支持秒数和 HTTP 日期两种格式，无法解析时返回0
+func ParseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		if seconds <= 0 {
+			return 0
+		}
+		return time.Duration(seconds * float64(time.Second))
+	}
+	if at, err := http.ParseTime(value); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return 0
+}
+
+// RetryableStatus 限流和服务端临时错误可以重试
+func RetryableStatus(statusCode int) bool {
+	switch statusCode {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// EstimateTokens 按约4字节一个 token 粗略估算，用于请求前预占额度，拿到实际用量后再修正
+func EstimateTokens(text string) int {
+	return len(text)/4 + 1
+}
+
+// EstimateRequestTokens 请求前预占的额度：prompt 的估算加上一部分补全余量（prompt 的一半，不超过 max_tokens），
+// 不直接按 max_tokens 预占，否则 tpm 很快被占满
+func EstimateRequestTokens(promptTokens, maxTokens int) int {
+	completion := promptTokens / 2
+	if maxTokens > 0 {
+		completion = min(completion, maxTokens)
+	}
+	return promptTokens + completion
+}
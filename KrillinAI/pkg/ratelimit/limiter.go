@@ -0,0 +1,163 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"krillin-ai/config"
+)
+
+// 进程级的限流器，同一提供方的所有调用（多个任务、翻译/拆句/摘要）共享每分钟请求数和 token 数的额度
+
+const window = time.Minute
+
+type event struct {
+	at     time.Time
+	tokens int
+}
+
+// Limiter 按最近一分钟的滑动窗口限制请求数和 token 数，rpm/tpm 为0表示不限制
+type Limiter struct {
+	mu           sync.Mutex
+	rpm          int
+	tpm          int
+	events       []*event
+	blockedUntil time.Time
+
+	now   func() time.Time
+	sleep func(context.Context, time.Duration) error
+}
+
+// Reservation 一次请求占用的额度，拿到实际 token 用量后通过 Settle 修正
+type Reservation struct {
+	limiter *Limiter
+	event   *event
+}
+
+func NewLimiter(rpm, tpm int) *Limiter {
+	return &Limiter{rpm: rpm, tpm: tpm, now: time.Now, sleep: sleepContext}
+}
+
+// sleepContext 等待 d，ctx 取消时提前返回
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+var (
+	registryMu sync.Mutex
+	registry   = make(map[string]*Limiter)
+)
+
+// For 返回提供方对应的进程级限流器，每次获取时按当前配置更新额度
+func For(provider string) *Limiter {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	limiter, ok := registry[provider]
+	if !ok {
+		limiter = NewLimiter(0, 0)
+		registry[provider] = limiter
+	}
+	rpm, tpm := 0, 0
+	for _, p := range config.Conf.RateLimit.Providers {
+		if p.Name == provider {
+			rpm, tpm = p.Rpm, p.Tpm
+			break
+		}
+	}
+	limiter.SetLimits(rpm, tpm)
+	return limiter
+}
+
+func (l *Limiter) SetLimits(rpm, tpm int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.rpm, l.tpm = rpm, tpm
+}
+
+// Wait 阻塞到额度允许发出一个预计消耗 tokens 的请求。单个请求超过 tpm 时，等窗口清空后放行；
+// ctx 取消时不占用额度，返回 ctx 的错误
+func (l *Limiter) Wait(ctx context.Context, tokens int) (*Reservation, error) {
+	if l == nil {
+		return nil, nil
+	}
+	for {
+		l.mu.Lock()
+		now := l.now()
+		l.prune(now)
+		wait := l.waitDuration(now, tokens)
+		if wait <= 0 {
+			e := &event{at: now, tokens: tokens}
+			l.events = append(l.events, e)
+			l.mu.Unlock()
+			return &Reservation{limiter: l, event: e}, nil
+		}
+		l.mu.Unlock()
+		if err := l.sleep(ctx, wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Block 收到 Retry-After 时暂停该提供方的所有请求
+func (l *Limiter) Block(d time.Duration) {
+	if l == nil || d <= 0 {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if until := l.now().Add(d); until.After(l.blockedUntil) {
+		l.blockedUntil = until
+	}
+}
+
+// Settle 用实际 token 用量替换预估值
+func (r *Reservation) Settle(tokens int) {
+	if r == nil || tokens <= 0 {
+		return
+	}
+	r.limiter.mu.Lock()
+	defer r.limiter.mu.Unlock()
+	r.event.tokens = tokens
+}
+
+func (l *Limiter) prune(now time.Time) {
+	i := 0
+	for i < len(l.events) && now.Sub(l.events[i].at) >= window {
+		i++
+	}
+	l.events = l.events[i:]
+}
+
+func (l *Limiter) waitDuration(now time.Time, tokens int) time.Duration {
+	if now.Before(l.blockedUntil) {
+		return l.blockedUntil.Sub(now)
+	}
+	if l.rpm > 0 && len(l.events) >= l.rpm {
+		return l.events[len(l.events)-l.rpm].at.Add(window).Sub(now)
+	}
+	if l.tpm > 0 && len(l.events) > 0 {
+		used := 0
+		for _, e := range l.events {
+			used += e.tokens
+		}
+		// 依次等最早的请求移出窗口，直到剩余额度够用
+		for _, e := range l.events {
+			if used+tokens <= l.tpm {
+				break
+			}
+			used -= e.tokens
+			if used+tokens <= l.tpm || used == 0 {
+				return e.at.Add(window).Sub(now)
+			}
+		}
+	}
+	return 0
+}
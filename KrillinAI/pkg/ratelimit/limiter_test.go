@@ -0,0 +1,130 @@
+package ratelimit
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"krillin-ai/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newFakeLimiter 使用假时钟，sleep 直接推进时间并记录等待
+func newFakeLimiter(rpm, tpm int) (*Limiter, *[]time.Duration) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	waits := make([]time.Duration, 0)
+	limiter := NewLimiter(rpm, tpm)
+	limiter.now = func() time.Time { return now }
+	limiter.sleep = func(ctx context.Context, d time.Duration) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		waits = append(waits, d)
+		now = now.Add(d)
+		return nil
+	}
+	return limiter, &waits
+}
+
+func TestLimiter_RequestsPerMinute(t *testing.T) {
+	limiter, waits := newFakeLimiter(2, 0)
+	ctx := context.Background()
+	limiter.Wait(ctx, 10)
+	limiter.Wait(ctx, 10)
+	assert.Empty(t, *waits)
+
+	limiter.Wait(ctx, 10)
+	assert.Equal(t, []time.Duration{time.Minute}, *waits)
+}
+
+func TestLimiter_TokensPerMinuteUsesSettledUsage(t *testing.T) {
+	limiter, waits := newFakeLimiter(0, 1000)
+	ctx := context.Background()
+	reservation, err := limiter.Wait(ctx, 900)
+	assert.NoError(t, err)
+	// 实际用量比预估少，剩余额度足够下一个请求
+	reservation.Settle(300)
+	limiter.Wait(ctx, 600)
+	assert.Empty(t, *waits)
+
+	limiter.Wait(ctx, 500)
+	assert.Equal(t, []time.Duration{time.Minute}, *waits)
+
+	// 单个请求超过 tpm 时等窗口清空后放行
+	limiter.Wait(ctx, 5000)
+	assert.Len(t, *waits, 2)
+}
+
+func TestLimiter_BlockDelaysAllRequests(t *testing.T) {
+	limiter, waits := newFakeLimiter(0, 0)
+	limiter.Block(5 * time.Second)
+	limiter.Block(time.Second) // 较短的暂停不会覆盖较长的
+	limiter.Wait(context.Background(), 1)
+	assert.Equal(t, []time.Duration{5 * time.Second}, *waits)
+
+	var nilLimiter *Limiter
+	reservation, err := nilLimiter.Wait(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Nil(t, reservation)
+	reservation.Settle(10)
+}
+
+func TestLimiter_WaitReturnsWhenContextCancelled(t *testing.T) {
+	limiter, waits := newFakeLimiter(1, 0)
+	limiter.Wait(context.Background(), 10)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	reservation, err := limiter.Wait(ctx, 10)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, reservation)
+	assert.Empty(t, *waits)
+	// 取消的请求不占用额度
+	assert.Len(t, limiter.events, 1)
+
+	// 真实的等待同样响应取消
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, sleepContext(ctx, time.Hour), context.DeadlineExceeded)
+}
+
+func TestFor_SharesLimiterAndFollowsConfig(t *testing.T) {
+	oldRateLimit := config.Conf.RateLimit
+	defer func() { config.Conf.RateLimit = oldRateLimit }()
+	config.Conf.RateLimit.Providers = []config.RateLimitProvider{{Name: "test_provider", Rpm: 5, Tpm: 100}}
+
+	limiter := For("test_provider")
+	assert.Same(t, limiter, For("test_provider"))
+	assert.Equal(t, 5, limiter.rpm)
+	assert.Equal(t, 100, limiter.tpm)
+
+	config.Conf.RateLimit.Providers = nil
+	For("test_provider")
+	assert.Equal(t, 0, limiter.rpm)
+}
+
+func TestEstimateRequestTokens(t *testing.T) {
+	assert.Equal(t, 1500, EstimateRequestTokens(1000, 8192))
+	assert.Equal(t, 1100, EstimateRequestTokens(1000, 100))
+	assert.Equal(t, 1500, EstimateRequestTokens(1000, 0))
+}
+
+func TestParseRetryAfterAndBackoff(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, 3*time.Second, ParseRetryAfter("3", now))
+	assert.Equal(t, 1500*time.Millisecond, ParseRetryAfter("1.5", now))
+	assert.Equal(t, 30*time.Second, ParseRetryAfter(now.Add(30*time.Second).Format(http.TimeFormat), now))
+	assert.Equal(t, time.Duration(0), ParseRetryAfter("soon", now))
+
+	oldRateLimit := config.Conf.RateLimit
+	defer func() { config.Conf.RateLimit = oldRateLimit }()
+	config.Conf.RateLimit.BaseBackoffMs = 100
+	config.Conf.RateLimit.MaxBackoffSeconds = 1
+	for attempt, upper := range []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second, time.Second} {
+		d := Backoff(attempt)
+		assert.GreaterOrEqual(t, d, upper/2)
+		assert.LessOrEqual(t, d, upper)
+	}
+}
@@ -0,0 +1,138 @@
+package subtitle
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"krillin-ai/internal/types"
+)
+
+// 字幕样式预设生成 ASS 头：PlayResX/PlayResY 设为视频分辨率，按百分比配置的字号、描边、阴影和边距换算为像素，
+// 不同分辨率的视频中字幕占画面的比例一致
+
+const (
+	defaultPlayResX = 1920
+	defaultPlayResY = 1080
+	defaultFontName = "WenQuanYi Micro Hei"
+)
+
+// 与原有横屏样式的比例一致：字号 18/7、描边 2.5、阴影 1.5、下边距 20，原样式没有 PlayResY，按默认的 288 换算
+var (
+	defaultMajorTextStyle = types.SubtitleTextStyle{FontSize: 6.25, PrimaryColor: "#FFBF00", OutlineColor: "#000000", Outline: 0.87, ShadowColor: "#000000", Shadow: 0.52}
+	defaultMinorTextStyle = types.SubtitleTextStyle{FontSize: 2.43, PrimaryColor: "#FFBF00", OutlineColor: "#000000", Outline: 0.87, ShadowColor: "#000000", Shadow: 0.52}
+)
+
+const defaultMarginVertical = 6.94
+
+// StyledAssHeader 按样式预设和视频分辨率生成 ASS 头，分辨率未知时按 1920x1080
+func StyledAssHeader(style *types.SubtitleStyle, width, height int) string {
+	if width <= 0 || height <= 0 {
+		width, height = defaultPlayResX, defaultPlayResY
+	}
+	alignment := 2
+	switch style.Position {
+	case types.SubtitlePositionMiddle:
+		alignment = 5
+	case types.SubtitlePositionTop:
+		alignment = 8
+	}
+	marginVertical := style.MarginVertical
+	if marginVertical == 0 {
+		marginVertical = defaultMarginVertical
+	}
+	marginH := percentPixels(style.MarginHorizontal, width)
+	marginV := percentPixels(marginVertical, height)
+
+	var builder strings.Builder
+	builder.WriteString("[Script Info]\nTitle: KrillinAI\nScriptType: v4.00+\nWrapStyle: 0\nScaledBorderAndShadow: yes\n")
+	fmt.Fprintf(&builder, "PlayResX: %d\nPlayResY: %d\n\n", width, height)
+	builder.WriteString("[V4+ Styles]\nFormat: Name, Fontname, Fontsize, PrimaryColour, SecondaryColour, OutlineColour, BackColour, Bold, Italic, Underline, StrikeOut, ScaleX, ScaleY, Spacing, Angle, BorderStyle, Outline, Shadow, Alignment, MarginL, MarginR, MarginV, Encoding\n")
+	builder.WriteString(assStyleLine("Major", withTextStyleDefaults(style.Major, defaultMajorTextStyle), height, alignment, marginH, marginV))
+	builder.WriteString(assStyleLine("Minor", withTextStyleDefaults(style.Minor, defaultMinorTextStyle), height, alignment, marginH, marginV))
+	builder.WriteString("\n[Events]\nFormat: Layer, Start, End, Style, Name, MarginL, MarginR, MarginV, Effect, Text\n")
+	return builder.String()
+}
+
+// PrimaryAssColour 样式预设中主字幕或原文字幕的文字颜色，ASS 格式
+func PrimaryAssColour(style *types.SubtitleStyle, minor bool) string {
+	textStyle := withTextStyleDefaults(style.Major, defaultMajorTextStyle)
+	if minor {
+		textStyle = withTextStyleDefaults(style.Minor, defaultMinorTextStyle)
+	}
+	colour, _ := AssColour(textStyle.PrimaryColor)
+	return colour
+}
+
+// withTextStyleDefaults 为空或为0的字段使用默认值，粗体、斜体、背景框以预设为准
+func withTextStyleDefaults(style, defaults types.SubtitleTextStyle) types.SubtitleTextStyle {
+	if style.FontName == "" {
+		style.FontName = defaultFontName
+	}
+	if style.FontSize == 0 {
+		style.FontSize = defaults.FontSize
+	}
+	if style.PrimaryColor == "" {
+		style.PrimaryColor = defaults.PrimaryColor
+	}
+	if style.OutlineColor == "" {
+		style.OutlineColor = defaults.OutlineColor
+	}
+	if style.Outline == 0 {
+		style.Outline = defaults.Outline
+	}
+	if style.ShadowColor == "" {
+		style.ShadowColor = defaults.ShadowColor
+	}
+	if style.Shadow == 0 {
+		style.Shadow = defaults.Shadow
+	}
+	if style.BoxColor == "" {
+		style.BoxColor = "#000000"
+	}
+	if style.BoxOpacity == 0 {
+		style.BoxOpacity = 0.5
+	}
+	return style
+}
+
+// assStyleLine 背景框使用 BorderStyle 3，此时框的颜色取 OutlineColour，描边宽度作为框的内边距
+func assStyleLine(name string, style types.SubtitleTextStyle, height, alignment, marginH, marginV int) string {
+	borderStyle := 1
+	outlineColour := assStyleColour(style.OutlineColor, 1)
+	if style.Box {
+		borderStyle = 3
+		outlineColour = assStyleColour(style.BoxColor, style.BoxOpacity)
+	}
+	return fmt.Sprintf("Style: %s,%s,%d,%s,&H000000FF,%s,%s,%d,%d,0,0,100,100,0,0,%d,%s,%s,%d,%d,%d,%d,1\n",
+		name, style.FontName, max(percentPixels(style.FontSize, height), 1),
+		assStyleColour(style.PrimaryColor, 1), outlineColour, assStyleColour(style.ShadowColor, 0.5),
+		assBool(style.Bold), assBool(style.Italic), borderStyle,
+		formatPixels(style.Outline, height), formatPixels(style.Shadow, height),
+		alignment, marginH, marginH, marginV)
+}
+
+// assStyleColour 样式行中的颜色为 &HAABBGGRR，AA 为透明度（00 不透明）
+func assStyleColour(hex string, opacity float64) string {
+	colour, err := AssColour(hex)
+	if err != nil {
+		colour = "&H000000&"
+	}
+	alpha := int(math.Round((1 - min(max(opacity, 0), 1)) * 255))
+	return fmt.Sprintf("&H%02X%s", alpha, colour[2:len(colour)-1])
+}
+
+func assBool(value bool) int {
+	if value {
+		return -1
+	}
+	return 0
+}
+
+func percentPixels(percent float64, size int) int {
+	return int(math.Round(percent * float64(size) / 100))
+}
+
+func formatPixels(percent float64, size int) string {
+	return strings.TrimSuffix(strings.TrimRight(fmt.Sprintf("%.1f", percent*float64(size)/100), "0"), ".")
+}
@@ -0,0 +1,106 @@
+package subtitle
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// 卡拉OK字幕：按识别词的时间给 ASS 文本加 \k/\kf 标签，播放到某个词时该词切换为高亮颜色，
+// 在字幕文本中找不到的识别词的时长并入下一个词，没有匹配到任何词时按整句显示
+
+const (
+	KaraokeModeSwitch = "k"  // 到词的时间时整个词切换为高亮颜色
+	KaraokeModeFill   = "kf" // 在词的时长内从左到右渐变填充
+
+	DefaultKaraokeColour = "#FFFF00"
+	karaokeBaseColour    = "&HFFFFFF&"
+)
+
+// Karaoke ASS 逐词高亮的设置
+type Karaoke struct {
+	Mode       string // k 或 kf
+	Colour     string // 高亮颜色，ASS 格式 &HBBGGRR&
+	BaseColour string // 未唱到的词的颜色，为空时为白色
+	SubText    bool   // 对副文本逐词高亮，双语轨道中原文是副文本
+}
+
+// ParseKaraokeMode 校验卡拉OK模式，为空表示不启用
+func ParseKaraokeMode(mode string) (string, error) {
+	switch mode = strings.ToLower(strings.TrimSpace(mode)); mode {
+	case "", KaraokeModeSwitch, KaraokeModeFill:
+		return mode, nil
+	}
+	return "", fmt.Errorf("unsupported karaoke mode: %s", mode)
+}
+
+// AssColour 把 #RRGGBB 转为 ASS 的 &HBBGGRR& 颜色
+func AssColour(hex string) (string, error) {
+	hex = strings.TrimPrefix(strings.TrimSpace(hex), "#")
+	if len(hex) != 6 {
+		return "", fmt.Errorf("invalid colour: %s", hex)
+	}
+	if _, err := strconv.ParseUint(hex, 16, 32); err != nil {
+		return "", fmt.Errorf("invalid colour: %s", hex)
+	}
+	hex = strings.ToUpper(hex)
+	return fmt.Sprintf("&H%s%s%s&", hex[4:6], hex[2:4], hex[0:2]), nil
+}
+
+// render 生成带卡拉OK标签的 ASS 文本，每个词的时长从上一个词结束（第一个词从字幕开始）算到该词结束，单位为百分之一秒
+func (k *Karaoke) render(text string, cue Cue) string {
+	text = strings.TrimSpace(text)
+	if text == "" || len(cue.Words) == 0 {
+		return assText(text)
+	}
+	tag := k.Mode
+	if tag != KaraokeModeFill {
+		tag = KaraokeModeSwitch
+	}
+	searchText := text
+	fold := len(strings.ToLower(text)) == len(text)
+	if fold {
+		searchText = strings.ToLower(text)
+	}
+
+	var builder strings.Builder
+	cursor := 0
+	previous := centiseconds(cue.Start.Seconds())
+	cueEnd := centiseconds(cue.End.Seconds())
+	for _, word := range cue.Words {
+		target := strings.TrimSpace(word.Text)
+		if fold {
+			target = strings.ToLower(target)
+		}
+		if target == "" {
+			continue
+		}
+		pos := strings.Index(searchText[cursor:], target)
+		if pos < 0 {
+			continue
+		}
+		end := cursor + pos + len(target)
+		wordEnd := min(centiseconds(word.End), cueEnd)
+		fmt.Fprintf(&builder, "{\\%s%d}%s", tag, max(wordEnd-previous, 0), assEscape(text[cursor:end]))
+		previous = max(previous, wordEnd)
+		cursor = end
+	}
+	if cursor == 0 {
+		return assText(text)
+	}
+	builder.WriteString(assEscape(text[cursor:]))
+
+	colour, baseColour := k.Colour, k.BaseColour
+	if colour == "" {
+		colour, _ = AssColour(DefaultKaraokeColour)
+	}
+	if baseColour == "" {
+		baseColour = karaokeBaseColour
+	}
+	return fmt.Sprintf("{\\1c%s\\2c%s}%s", colour, baseColour, builder.String())
+}
+
+func centiseconds(seconds float64) int {
+	return int(math.Round(seconds * 100))
+}
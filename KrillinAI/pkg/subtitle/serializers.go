@@ -0,0 +1,149 @@
+package subtitle
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+
+	"krillin-ai/internal/types"
+)
+
+func writeSrt(builder *strings.Builder, track *Track) {
+	for i, cue := range track.Cues {
+		fmt.Fprintf(builder, "%d\n%s --> %s\n%s\n\n", i+1, SrtTimestamp(cue.Start), SrtTimestamp(cue.End), strings.Join(track.Lines(cue), "\n"))
+	}
+}
+
+// writeVtt WebVTT，文本中的 & < > 需要转义
+func writeVtt(builder *strings.Builder, track *Track) {
+	builder.WriteString("WEBVTT\n\n")
+	escaper := strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+	for i, cue := range track.Cues {
+		h, m, s, ms := splitDuration(cue.Start)
+		eh, em, es, ems := splitDuration(cue.End)
+		fmt.Fprintf(builder, "%d\n%02d:%02d:%02d.%03d --> %02d:%02d:%02d.%03d\n%s\n\n", i+1, h, m, s, ms, eh, em, es, ems,
+			escaper.Replace(strings.Join(track.Lines(cue), "\n")))
+	}
+}
+
+// writeAss 主文本使用 Major 样式，双语字幕的副文本使用 Minor 样式。没有样式预设时与原有字幕一致固定在底部居中
+func writeAss(builder *strings.Builder, track *Track) {
+	header, alignment := track.AssHeader, "{\\an2}"
+	if track.AssStyle != nil {
+		header, alignment = StyledAssHeader(track.AssStyle, track.PlayResX, track.PlayResY), ""
+	} else if header == "" {
+		header = types.AssHeaderHorizontal
+	}
+	builder.WriteString(header)
+	for _, cue := range track.Cues {
+		text, subText := assText(cue.Text), assText(cue.SubText)
+		if track.Karaoke != nil {
+			if track.Karaoke.SubText {
+				subText = track.Karaoke.render(cue.SubText, cue)
+			} else {
+				text = track.Karaoke.render(cue.Text, cue)
+			}
+		}
+		if subText != "" && text != "" {
+			if track.SubTextOnTop {
+				text = fmt.Sprintf("{\\rMinor}%s\\N{\\rMajor}%s", subText, text)
+			} else {
+				text = fmt.Sprintf("{\\rMajor}%s\\N{\\rMinor}%s", text, subText)
+			}
+		} else if text == "" {
+			text = subText
+		}
+		if text == "" {
+			continue
+		}
+		fmt.Fprintf(builder, "Dialogue: 0,%s,%s,Major,,0,0,0,,%s%s\n", assTimestamp(cue.Start), assTimestamp(cue.End), alignment, text)
+	}
+}
+
+// assText ASS 的换行为 \N，花括号会被当作样式标签
+func assText(text string) string {
+	return assEscape(strings.TrimSpace(text))
+}
+
+func assEscape(text string) string {
+	return strings.NewReplacer("{", "(", "}", ")", "\n", "\\N").Replace(text)
+}
+
+func assTimestamp(d time.Duration) string {
+	h, m, s, ms := splitDuration(d)
+	return fmt.Sprintf("%d:%02d:%02d.%02d", h, m, s, ms/10)
+}
+
+// writeTtml TTML（兼容 DFXP），多行文本用 <br/> 分隔
+func writeTtml(builder *strings.Builder, track *Track) {
+	builder.WriteString(xml.Header)
+	language := track.Language
+	if language == "" {
+		language = "und"
+	}
+	builder.WriteString(`<tt xmlns="http://www.w3.org/ns/ttml" xml:lang="`)
+	_ = xml.EscapeText(builder, []byte(language))
+	builder.WriteString("\">\n  <body>\n    <div>\n")
+	for i, cue := range track.Cues {
+		lines := track.Lines(cue)
+		for j, line := range lines {
+			var escaped strings.Builder
+			_ = xml.EscapeText(&escaped, []byte(line))
+			lines[j] = escaped.String()
+		}
+		fmt.Fprintf(builder, "      <p xml:id=\"c%d\" begin=\"%s\" end=\"%s\">%s</p>\n", i+1, ttmlTimestamp(cue.Start), ttmlTimestamp(cue.End),
+			strings.Join(lines, "<br/>"))
+	}
+	builder.WriteString("    </div>\n  </body>\n</tt>\n")
+}
+
+func ttmlTimestamp(d time.Duration) string {
+	h, m, s, ms := splitDuration(d)
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", h, m, s, ms)
+}
+
+// writeSbv YouTube SBV，时间为 H:MM:SS.mmm，以逗号分隔
+func writeSbv(builder *strings.Builder, track *Track) {
+	for _, cue := range track.Cues {
+		h, m, s, ms := splitDuration(cue.Start)
+		eh, em, es, ems := splitDuration(cue.End)
+		fmt.Fprintf(builder, "%d:%02d:%02d.%03d,%d:%02d:%02d.%03d\n%s\n\n", h, m, s, ms, eh, em, es, ems,
+			strings.Join(track.Lines(cue), "\n"))
+	}
+}
+
+type jsonCue struct {
+	Index   int     `json:"index"`
+	Start   float64 `json:"start"`
+	End     float64 `json:"end"`
+	Text    string  `json:"text"`
+	SubText string  `json:"sub_text,omitempty"`
+	Words   []Word  `json:"words"`
+}
+
+type jsonTrack struct {
+	Language string    `json:"language"`
+	Cues     []jsonCue `json:"cues"`
+}
+
+// marshalJson 时间轴 JSON，时间为秒，words 为落在该字幕内的识别词
+func marshalJson(track *Track) ([]byte, error) {
+	result := jsonTrack{Language: track.Language, Cues: make([]jsonCue, 0, len(track.Cues))}
+	for i, cue := range track.Cues {
+		words := cue.Words
+		if words == nil {
+			words = make([]Word, 0)
+		}
+		result.Cues = append(result.Cues, jsonCue{
+			Index:   i + 1,
+			Start:   cue.Start.Seconds(),
+			End:     cue.End.Seconds(),
+			Text:    cue.Text,
+			SubText: cue.SubText,
+			Words:   words,
+		})
+	}
+	return json.MarshalIndent(result, "", "  ")
+}
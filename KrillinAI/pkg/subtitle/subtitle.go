@@ -0,0 +1,143 @@
+package subtitle
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"krillin-ai/internal/types"
+)
+
+// 字幕模型：一条轨道由按时间排序的字幕条目组成，双语轨道的每条字幕带主文本和副文本，
+// 各导出格式都从同一个模型序列化，保证时间轴和文本一致
+
+// Format 导出格式
+type Format string
+
+const (
+	FormatSrt  Format = "srt"
+	FormatVtt  Format = "vtt"
+	FormatAss  Format = "ass"
+	FormatTtml Format = "ttml" // TTML/DFXP
+	FormatSbv  Format = "sbv"
+	FormatJson Format = "json" // 带词级时间的时间轴
+)
+
+var formats = []Format{FormatSrt, FormatVtt, FormatAss, FormatTtml, FormatSbv, FormatJson}
+
+// Extension 导出文件的扩展名
+func (f Format) Extension() string {
+	return string(f)
+}
+
+// ParseFormat 解析格式名称，忽略大小写，dfxp 视为 ttml
+func ParseFormat(name string) (Format, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "dfxp" {
+		return FormatTtml, nil
+	}
+	for _, format := range formats {
+		if string(format) == name {
+			return format, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported subtitle format: %s", name)
+}
+
+// Word 识别词，时间为相对整段音频的秒数
+type Word struct {
+	Text       string  `json:"text"`
+	Start      float64 `json:"start"`
+	End        float64 `json:"end"`
+	Confidence float64 `json:"confidence,omitempty"`
+}
+
+// Cue 一条字幕，Text 为主文本，双语字幕的另一种语言放在 SubText
+type Cue struct {
+	Start   time.Duration
+	End     time.Duration
+	Text    string
+	SubText string
+	Words   []Word
+}
+
+// Track 一条字幕轨道
+type Track struct {
+	Language     string               // 语言代码，写入 TTML 的 xml:lang 和 JSON
+	SubTextOnTop bool                 // 双语字幕中副文本是否在主文本上方
+	AssHeader    string               // ASS 的 [Script Info]、[V4+ Styles] 和 [Events] 头，为空时使用默认横屏样式
+	AssStyle     *types.SubtitleStyle // 不为nil时按样式预设生成 ASS 头，位置由样式决定
+	PlayResX     int                  // 生成 ASS 头时的视频分辨率，为0时按 1920x1080
+	PlayResY     int
+	Karaoke      *Karaoke // 不为nil时 ASS 按识别词逐词高亮
+	Cues         []Cue
+}
+
+// Lines 按显示顺序返回字幕的文本行，空行忽略
+func (t *Track) Lines(cue Cue) []string {
+	parts := []string{cue.Text, cue.SubText}
+	if t.SubTextOnTop {
+		parts = []string{cue.SubText, cue.Text}
+	}
+	lines := make([]string, 0, 2)
+	for _, part := range parts {
+		for _, line := range strings.Split(part, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				lines = append(lines, line)
+			}
+		}
+	}
+	return lines
+}
+
+// AttachWords 按词的中点落在字幕时间范围内的规则把识别词分配给字幕，字幕和词都需按时间排序
+func AttachWords(cues []Cue, words []Word) {
+	wordIdx := 0
+	for i := range cues {
+		start, end := cues[i].Start.Seconds(), cues[i].End.Seconds()
+		for wordIdx < len(words) && (words[wordIdx].Start+words[wordIdx].End)/2 < start {
+			wordIdx++
+		}
+		cues[i].Words = nil
+		for j := wordIdx; j < len(words) && (words[j].Start+words[j].End)/2 <= end; j++ {
+			cues[i].Words = append(cues[i].Words, words[j])
+		}
+	}
+}
+
+// Marshal 把轨道序列化为指定格式
+func Marshal(track *Track, format Format) ([]byte, error) {
+	var builder strings.Builder
+	switch format {
+	case FormatSrt:
+		writeSrt(&builder, track)
+	case FormatVtt:
+		writeVtt(&builder, track)
+	case FormatAss:
+		writeAss(&builder, track)
+	case FormatTtml:
+		writeTtml(&builder, track)
+	case FormatSbv:
+		writeSbv(&builder, track)
+	case FormatJson:
+		return marshalJson(track)
+	default:
+		return nil, fmt.Errorf("unsupported subtitle format: %s", format)
+	}
+	return []byte(builder.String()), nil
+}
+
+// SrtTimestamp SRT 时间格式 HH:MM:SS,mmm
+func SrtTimestamp(d time.Duration) string {
+	h, m, sec, ms := splitDuration(d)
+	return fmt.Sprintf("%02d:%02d:%02d,%03d", h, m, sec, ms)
+}
+
+// splitDuration 拆出时、分、秒、毫秒，负数按0处理
+func splitDuration(d time.Duration) (hours, minutes, seconds, milliseconds int) {
+	if d < 0 {
+		d = 0
+	}
+	ms := int(d.Milliseconds())
+	return ms / 3600000, ms / 60000 % 60, ms / 1000 % 60, ms % 1000
+}
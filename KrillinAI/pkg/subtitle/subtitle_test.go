@@ -0,0 +1,156 @@
+package subtitle
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"krillin-ai/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testTrack() *Track {
+	return &Track{
+		Language: "zh_cn",
+		Cues: []Cue{
+			{Start: 1500 * time.Millisecond, End: 3 * time.Second, Text: "你好 <世界>", SubText: "Hello & world"},
+			{Start: time.Hour + 2*time.Minute + 3*time.Second + 45*time.Millisecond, End: time.Hour + 2*time.Minute + 5*time.Second, Text: "再见"},
+		},
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	format, err := ParseFormat(" VTT ")
+	require.NoError(t, err)
+	assert.Equal(t, FormatVtt, format)
+	format, err = ParseFormat("dfxp")
+	require.NoError(t, err)
+	assert.Equal(t, FormatTtml, format)
+	_, err = ParseFormat("docx")
+	assert.Error(t, err)
+}
+
+func TestMarshal_TextFormats(t *testing.T) {
+	track := testTrack()
+
+	data, err := Marshal(track, FormatSrt)
+	require.NoError(t, err)
+	assert.Equal(t, "1\n00:00:01,500 --> 00:00:03,000\n你好 <世界>\nHello & world\n\n2\n01:02:03,045 --> 01:02:05,000\n再见\n\n", string(data))
+
+	data, err = Marshal(track, FormatVtt)
+	require.NoError(t, err)
+	assert.Equal(t, "WEBVTT\n\n1\n00:00:01.500 --> 00:00:03.000\n你好 &lt;世界&gt;\nHello &amp; world\n\n2\n01:02:03.045 --> 01:02:05.000\n再见\n\n", string(data))
+
+	data, err = Marshal(track, FormatSbv)
+	require.NoError(t, err)
+	assert.Equal(t, "0:00:01.500,0:00:03.000\n你好 <世界>\nHello & world\n\n1:02:03.045,1:02:05.000\n再见\n\n", string(data))
+
+	track.SubTextOnTop = true
+	data, err = Marshal(track, FormatSrt)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "Hello & world\n你好 <世界>\n")
+}
+
+func TestMarshal_Ass(t *testing.T) {
+	data, err := Marshal(testTrack(), FormatAss)
+	require.NoError(t, err)
+
+	content := string(data)
+	assert.True(t, strings.HasPrefix(content, "[Script Info]"))
+	assert.Contains(t, content, "Dialogue: 0,0:00:01.50,0:00:03.00,Major,,0,0,0,,{\\an2}{\\rMajor}你好 <世界>\\N{\\rMinor}Hello & world\n")
+	assert.Contains(t, content, "Dialogue: 0,1:02:03.04,1:02:05.00,Major,,0,0,0,,{\\an2}再见\n")
+}
+
+func TestMarshal_Ttml(t *testing.T) {
+	data, err := Marshal(testTrack(), FormatTtml)
+	require.NoError(t, err)
+
+	content := string(data)
+	assert.Contains(t, content, `<tt xmlns="http://www.w3.org/ns/ttml" xml:lang="zh_cn">`)
+	assert.Contains(t, content, `<p xml:id="c1" begin="00:00:01.500" end="00:00:03.000">你好 &lt;世界&gt;<br/>Hello &amp; world</p>`)
+}
+
+func TestMarshal_JsonWithWords(t *testing.T) {
+	track := testTrack()
+	AttachWords(track.Cues, []Word{
+		{Text: "intro", Start: 0.2, End: 0.8},
+		{Text: "Hello", Start: 1.5, End: 2.0, Confidence: 0.9},
+		{Text: "world", Start: 2.1, End: 2.9},
+		{Text: "bye", Start: 3723.1, End: 3723.6},
+	})
+
+	data, err := Marshal(track, FormatJson)
+	require.NoError(t, err)
+
+	var result struct {
+		Language string `json:"language"`
+		Cues     []struct {
+			Index   int     `json:"index"`
+			Start   float64 `json:"start"`
+			SubText string  `json:"sub_text"`
+			Words   []Word  `json:"words"`
+		} `json:"cues"`
+	}
+	require.NoError(t, json.Unmarshal(data, &result))
+	require.Len(t, result.Cues, 2)
+	assert.Equal(t, "zh_cn", result.Language)
+	assert.InDelta(t, 1.5, result.Cues[0].Start, 0.0001)
+	assert.Equal(t, "Hello & world", result.Cues[0].SubText)
+	assert.Equal(t, []Word{{Text: "Hello", Start: 1.5, End: 2.0, Confidence: 0.9}, {Text: "world", Start: 2.1, End: 2.9}}, result.Cues[0].Words)
+	assert.Equal(t, 2, result.Cues[1].Index)
+	assert.Equal(t, []Word{{Text: "bye", Start: 3723.1, End: 3723.6}}, result.Cues[1].Words)
+}
+
+func TestAssColour(t *testing.T) {
+	colour, err := AssColour("#FF8000")
+	require.NoError(t, err)
+	assert.Equal(t, "&H0080FF&", colour)
+	_, err = AssColour("orange")
+	assert.Error(t, err)
+}
+
+func TestMarshal_AssKaraoke(t *testing.T) {
+	track := &Track{
+		SubTextOnTop: true,
+		Karaoke:      &Karaoke{Mode: KaraokeModeFill, Colour: "&H0000FF&", SubText: true},
+		Cues: []Cue{
+			{Start: time.Second, End: 3 * time.Second, Text: "你好，世界", SubText: "Hello, big world!", Words: []Word{
+				{Text: "Hello,", Start: 1.1, End: 1.5},
+				{Text: "huge", Start: 1.6, End: 1.9},
+				{Text: "big", Start: 2.0, End: 2.2},
+				{Text: "world", Start: 2.3, End: 2.8},
+			}},
+			{Start: 4 * time.Second, End: 5 * time.Second, Text: "没有词", SubText: "No words"},
+		},
+	}
+
+	data, err := Marshal(track, FormatAss)
+	require.NoError(t, err)
+
+	content := string(data)
+	assert.Contains(t, content, "{\\an2}{\\rMinor}{\\1c&H0000FF&\\2c&HFFFFFF&}{\\kf50}Hello,{\\kf70} big{\\kf60} world!\\N{\\rMajor}你好，世界\n")
+	assert.Contains(t, content, "{\\an2}{\\rMinor}No words\\N{\\rMajor}没有词\n")
+}
+
+func TestStyledAssHeader(t *testing.T) {
+	style := &types.SubtitleStyle{
+		Position: types.SubtitlePositionTop,
+		Major:    types.SubtitleTextStyle{FontSize: 5, Bold: true},
+		Minor:    types.SubtitleTextStyle{FontName: "Noto Sans", Box: true, BoxColor: "#202020", BoxOpacity: 1},
+	}
+
+	header := StyledAssHeader(style, 1280, 720)
+	assert.Contains(t, header, "PlayResX: 1280\nPlayResY: 720\n")
+	assert.Contains(t, header, "Style: Major,WenQuanYi Micro Hei,36,&H0000BFFF,&H000000FF,&H00000000,&H80000000,-1,0,0,0,100,100,0,0,1,6.3,3.7,8,0,0,50,1\n")
+	assert.Contains(t, header, "Style: Minor,Noto Sans,17,&H0000BFFF,&H000000FF,&H00202020,&H80000000,0,0,0,0,100,100,0,0,3,6.3,3.7,8,0,0,50,1\n")
+	assert.Contains(t, StyledAssHeader(style, 0, 0), "PlayResX: 1920\nPlayResY: 1080\n")
+
+	track := testTrack()
+	track.AssStyle = style
+	data, err := Marshal(track, FormatAss)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "Dialogue: 0,1:02:03.04,1:02:05.00,Major,,0,0,0,,再见\n")
+}
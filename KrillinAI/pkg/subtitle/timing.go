@@ -0,0 +1,242 @@
+package subtitle
+
+import (
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// 字幕时间轴规整：按顺序合并过短的字幕、消除重叠并保证最小间隔、按最短时长和阅读速度（CPS）延长显示时间、
+// 截断过长的字幕，填补和衔接相邻字幕之间的小间隔避免字幕闪烁，最后把靠近镜头切换的起止时间对齐到镜头切换
+
+// TimingOptions 时间轴规整参数，为0的项不处理
+type TimingOptions struct {
+	MinDuration   time.Duration // 最短显示时长
+	MaxDuration   time.Duration // 最长显示时长
+	MinGap        time.Duration // 相邻字幕的最小间隔
+	MergeUnder    time.Duration // 短于该时长的字幕与相邻字幕合并
+	MergeMaxGap   time.Duration // 合并时与相邻字幕的最大间隔
+	FillGap       time.Duration // 小于该值的间隔由上一条字幕延长填补
+	ChainGap      time.Duration // 小于该值的间隔由前后两条字幕在中点衔接
+	MaxCps        float64       // 主文本每秒字符数上限
+	MaxSubTextCps float64       // 副文本每秒字符数上限
+
+	ShotChanges   []time.Duration // 镜头切换时间点，升序，为空时不对齐
+	SnapTolerance time.Duration   // 起止时间与镜头切换相差不超过该值时对齐
+	CutGap        time.Duration   // 结束时间对齐到镜头切换之前的间隔，即切换前的帧数乘以帧时长
+}
+
+// NormalizeTiming 规整字幕时间轴，返回新的字幕列表，合并后的字幕数量可能减少
+func NormalizeTiming(cues []Cue, opts TimingOptions) []Cue {
+	result := make([]Cue, 0, len(cues))
+	for _, cue := range cues {
+		if cue.End < cue.Start {
+			cue.End = cue.Start
+		}
+		result = append(result, cue)
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Start < result[j].Start })
+
+	result = mergeShortCues(result, opts)
+	separateCues(result, opts.MinGap)
+	extendCues(result, opts)
+	if opts.MaxDuration > 0 {
+		for i := range result {
+			result[i].End = min(result[i].End, result[i].Start+opts.MaxDuration)
+		}
+	}
+	linkCues(result, opts)
+	if len(opts.ShotChanges) > 0 && opts.SnapTolerance > 0 {
+		snapToShotChanges(result, opts)
+		// 对齐后可能与相邻字幕重叠
+		separateCues(result, opts.MinGap)
+	}
+	for i := range result {
+		result[i].Start = result[i].Start.Round(time.Millisecond)
+		result[i].End = result[i].End.Round(time.Millisecond)
+	}
+	return result
+}
+
+// mergeShortCues 过短的字幕并入间隔较小的相邻字幕，合并后不能超过最长显示时长
+func mergeShortCues(cues []Cue, opts TimingOptions) []Cue {
+	if opts.MergeUnder <= 0 {
+		return cues
+	}
+	for i := 0; i < len(cues); {
+		if cues[i].End-cues[i].Start >= opts.MergeUnder {
+			i++
+			continue
+		}
+		prevOk := i > 0 && canMergeCues(cues[i-1], cues[i], opts)
+		nextOk := i+1 < len(cues) && canMergeCues(cues[i], cues[i+1], opts)
+		if prevOk && nextOk && cues[i+1].Start-cues[i].End < cues[i].Start-cues[i-1].End {
+			prevOk = false
+		}
+		switch {
+		case prevOk:
+			cues[i-1] = mergeCues(cues[i-1], cues[i])
+			cues = append(cues[:i], cues[i+1:]...)
+			i--
+		case nextOk:
+			cues[i] = mergeCues(cues[i], cues[i+1])
+			cues = append(cues[:i+1], cues[i+2:]...)
+		default:
+			i++
+		}
+	}
+	return cues
+}
+
+func canMergeCues(first, second Cue, opts TimingOptions) bool {
+	if second.Start-first.End > opts.MergeMaxGap {
+		return false
+	}
+	return opts.MaxDuration <= 0 || max(first.End, second.End)-first.Start <= opts.MaxDuration
+}
+
+func mergeCues(first, second Cue) Cue {
+	return Cue{
+		Start:   first.Start,
+		End:     max(first.End, second.End),
+		Text:    joinCueText(first.Text, second.Text),
+		SubText: joinCueText(first.SubText, second.SubText),
+		Words:   append(append([]Word{}, first.Words...), second.Words...),
+	}
+}
+
+// joinCueText 合并两段文本，中日泰文字之间不加空格
+func joinCueText(first, second string) string {
+	first, second = strings.TrimSpace(first), strings.TrimSpace(second)
+	if first == "" || second == "" {
+		return first + second
+	}
+	last, _ := utf8.DecodeLastRuneInString(first)
+	next, _ := utf8.DecodeRuneInString(second)
+	if isCompactScript(last) || isCompactScript(next) {
+		return first + second
+	}
+	return first + " " + second
+}
+
+func isCompactScript(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Thai) || (unicode.Is(unicode.P, r) && r > unicode.MaxLatin1)
+}
+
+// separateCues 消除重叠并保证最小间隔，优先提前上一条的结束时间，上一条没有余地时整体后移下一条
+func separateCues(cues []Cue, minGap time.Duration) {
+	for i := 0; i+1 < len(cues); i++ {
+		limit := cues[i+1].Start - minGap
+		if cues[i].End <= limit {
+			continue
+		}
+		if limit > cues[i].Start {
+			cues[i].End = limit
+			continue
+		}
+		shift := cues[i].End + minGap - cues[i+1].Start
+		cues[i+1].Start += shift
+		cues[i+1].End += shift
+	}
+}
+
+// extendCues 显示时间短于最短时长或阅读速度要求的字幕，先延后结束时间，空间不够时再提前开始时间
+func extendCues(cues []Cue, opts TimingOptions) {
+	for i := range cues {
+		required := max(opts.MinDuration, readingDuration(cues[i].Text, opts.MaxCps), readingDuration(cues[i].SubText, opts.MaxSubTextCps))
+		if opts.MaxDuration > 0 {
+			required = min(required, opts.MaxDuration)
+		}
+		if cues[i].End-cues[i].Start >= required {
+			continue
+		}
+		end := cues[i].Start + required
+		if i+1 < len(cues) {
+			end = min(end, cues[i+1].Start-opts.MinGap)
+		}
+		cues[i].End = max(cues[i].End, end)
+		if cues[i].End-cues[i].Start >= required {
+			continue
+		}
+		start := cues[i].End - required
+		if i > 0 {
+			start = max(start, cues[i-1].End+opts.MinGap)
+		}
+		cues[i].Start = max(min(cues[i].Start, start), 0)
+	}
+}
+
+// readingDuration 按每秒字符数上限计算读完文本需要的时长
+func readingDuration(text string, maxCps float64) time.Duration {
+	if maxCps <= 0 {
+		return 0
+	}
+	chars := utf8.RuneCountInString(strings.ReplaceAll(strings.TrimSpace(text), "\n", ""))
+	return time.Duration(float64(chars) / maxCps * float64(time.Second))
+}
+
+// linkCues 小于 FillGap 的间隔由上一条延长到最小间隔，小于 ChainGap 的间隔由前后两条在中点衔接，均不超过最长显示时长
+func linkCues(cues []Cue, opts TimingOptions) {
+	for i := 0; i+1 < len(cues); i++ {
+		gap := cues[i+1].Start - cues[i].End
+		if gap <= opts.MinGap {
+			continue
+		}
+		switch {
+		case gap < opts.FillGap:
+			if end := cues[i+1].Start - opts.MinGap; withinMaxDuration(cues[i].Start, end, opts) {
+				cues[i].End = end
+			}
+		case gap < opts.ChainGap:
+			middle := cues[i].End + (gap-opts.MinGap)/2
+			if withinMaxDuration(cues[i].Start, middle, opts) {
+				cues[i].End = middle
+			}
+			if start := middle + opts.MinGap; withinMaxDuration(start, cues[i+1].End, opts) {
+				cues[i+1].Start = start
+			}
+		}
+	}
+}
+
+func withinMaxDuration(start, end time.Duration, opts TimingOptions) bool {
+	return opts.MaxDuration <= 0 || end-start <= opts.MaxDuration
+}
+
+// snapToShotChanges 开始时间对齐到镜头切换，结束时间对齐到镜头切换之前 CutGap，避免字幕跨越镜头切换或在切换后残留几帧
+func snapToShotChanges(cues []Cue, opts TimingOptions) {
+	for i := range cues {
+		if cut, ok := nearestShotChange(opts.ShotChanges, cues[i].Start, opts.SnapTolerance); ok && cut < cues[i].End {
+			cues[i].Start = cut
+		}
+		if cut, ok := nearestShotChange(opts.ShotChanges, cues[i].End, opts.SnapTolerance); ok {
+			if end := cut - opts.CutGap; end > cues[i].Start && (end <= cues[i].End || withinMaxDuration(cues[i].Start, end, opts)) {
+				cues[i].End = end
+			}
+		}
+	}
+}
+
+// nearestShotChange 二分查找离 at 最近且相差不超过 tolerance 的镜头切换
+func nearestShotChange(shotChanges []time.Duration, at, tolerance time.Duration) (time.Duration, bool) {
+	index := sort.Search(len(shotChanges), func(i int) bool { return shotChanges[i] >= at })
+	best, found := time.Duration(0), false
+	for _, i := range []int{index - 1, index} {
+		if i < 0 || i >= len(shotChanges) {
+			continue
+		}
+		if distance := absDuration(shotChanges[i] - at); distance <= tolerance && (!found || distance < absDuration(best-at)) {
+			best, found = shotChanges[i], true
+		}
+	}
+	return best, found
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
@@ -0,0 +1,85 @@
+package subtitle
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func ms(value int) time.Duration {
+	return time.Duration(value) * time.Millisecond
+}
+
+func TestNormalizeTiming(t *testing.T) {
+	opts := TimingOptions{
+		MinDuration: ms(1000),
+		MaxDuration: ms(7000),
+		MinGap:      ms(80),
+		MergeUnder:  ms(300),
+		MergeMaxGap: ms(200),
+		FillGap:     ms(250),
+		ChainGap:    ms(500),
+		MaxCps:      10,
+	}
+	cues := NormalizeTiming([]Cue{
+		{Start: ms(5000), End: ms(5200), Text: "again"},
+		{Start: ms(0), End: ms(1500), Text: "Hello"},
+		{Start: ms(1400), End: ms(3000), Text: "world"},
+		{Start: ms(3050), End: ms(3600), Text: "this line is rather long"},
+		{Start: ms(9000), End: ms(20000), Text: "long"},
+	}, opts)
+
+	assert.Equal(t, []Cue{
+		// 重叠时提前上一条的结束时间
+		{Start: ms(0), End: ms(1320), Text: "Hello"},
+		// 与下一条间隔过近，保证最小间隔
+		{Start: ms(1400), End: ms(2970), Text: "world"},
+		// 按阅读速度延长到下一条之前
+		{Start: ms(3050), End: ms(4920), Text: "this line is rather long"},
+		// 过短且相邻字幕离得太远不能合并，按最短时长延长
+		{Start: ms(5000), End: ms(6000), Text: "again"},
+		{Start: ms(9000), End: ms(16000), Text: "long"},
+	}, cues)
+}
+
+func TestNormalizeTiming_MergeAndLink(t *testing.T) {
+	opts := TimingOptions{MinGap: ms(80), MergeUnder: ms(300), MergeMaxGap: ms(200), FillGap: ms(250), ChainGap: ms(500)}
+	cues := NormalizeTiming([]Cue{
+		{Start: ms(0), End: ms(1000), Text: "你好", SubText: "Hello"},
+		{Start: ms(1100), End: ms(1300), Text: "世界", SubText: "world"},
+		{Start: ms(1450), End: ms(2500), Text: "再见", SubText: "bye"},
+		{Start: ms(2900), End: ms(4000), Text: "下次见", SubText: "see you"},
+	}, opts)
+
+	assert.Equal(t, []Cue{
+		// 过短的字幕并入间隔较小的上一条，之后与下一条的小间隔被填补
+		{Start: ms(0), End: ms(1370), Text: "你好世界", SubText: "Hello world", Words: []Word{}},
+		{Start: ms(1450), End: ms(2660), Text: "再见", SubText: "bye"},
+		// 间隔在中点衔接
+		{Start: ms(2740), End: ms(4000), Text: "下次见", SubText: "see you"},
+	}, cues)
+}
+
+func TestNormalizeTiming_SnapToShotChanges(t *testing.T) {
+	opts := TimingOptions{
+		MinGap:        ms(80),
+		ShotChanges:   []time.Duration{ms(2000), ms(5000), ms(9000)},
+		SnapTolerance: ms(300),
+		CutGap:        ms(83),
+	}
+	cues := NormalizeTiming([]Cue{
+		{Start: ms(0), End: ms(2200), Text: "a"},
+		{Start: ms(2150), End: ms(4800), Text: "b"},
+		{Start: ms(6000), End: ms(8000), Text: "c"},
+	}, opts)
+
+	assert.Equal(t, []Cue{
+		// 结束时间跨过镜头切换，拉回到切换前两帧
+		{Start: ms(0), End: ms(1917), Text: "a"},
+		// 开始时间对齐到镜头切换，结束时间延长到下一个切换前
+		{Start: ms(2000), End: ms(4917), Text: "b"},
+		// 离镜头切换太远的不对齐
+		{Start: ms(6000), End: ms(8000), Text: "c"},
+	}, cues)
+}
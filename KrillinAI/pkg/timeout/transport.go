@@ -0,0 +1,168 @@
+package timeout
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"krillin-ai/config"
+)
+
+// 调用外部服务的超时：连接超时、空闲超时（等待响应以及流式响应中途没有数据）和单次请求的总超时，
+// 按提供方从配置中读取，服务卡住时请求会报错而不是一直挂起
+
+var (
+	ErrIdleTimeout    = errors.New("no data received within idle timeout")
+	ErrOverallTimeout = errors.New("request exceeded overall timeout")
+)
+
+// Settings 某个提供方的超时，0表示不限制
+type Settings struct {
+	Connect time.Duration
+	Idle    time.Duration
+	Overall time.Duration
+}
+
+// For 返回提供方的超时设置，提供方没有单独配置的字段使用默认值
+func For(provider string) Settings {
+	conf := config.Conf.Timeout
+	settings := Settings{
+		Connect: seconds(conf.ConnectSeconds),
+		Idle:    seconds(conf.IdleSeconds),
+		Overall: seconds(conf.OverallSeconds),
+	}
+	for _, p := range conf.Providers {
+		if p.Name != provider {
+			continue
+		}
+		if p.ConnectSeconds > 0 {
+			settings.Connect = seconds(p.ConnectSeconds)
+		}
+		if p.IdleSeconds > 0 {
+			settings.Idle = seconds(p.IdleSeconds)
+		}
+		if p.OverallSeconds > 0 {
+			settings.Overall = seconds(p.OverallSeconds)
+		}
+		break
+	}
+	return settings
+}
+
+func seconds(n int) time.Duration {
+	return time.Duration(n) * time.Second
+}
+
+// Transport 按提供方的超时设置发送请求，每次请求时读取配置，Provider 可在创建后修改
+type Transport struct {
+	Base     http.RoundTripper
+	Provider string
+}
+
+// NewTransport 包装 base，base 的连接超时改为按提供方配置，base 为 nil 时使用新的 http.Transport
+func NewTransport(provider string, base *http.Transport) *Transport {
+	if base == nil {
+		base = &http.Transport{}
+	}
+	t := &Transport{Base: base, Provider: provider}
+	if base.TLSHandshakeTimeout == 0 {
+		base.TLSHandshakeTimeout = 10 * time.Second
+	}
+	base.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		dialer := net.Dialer{Timeout: For(t.Provider).Connect, KeepAlive: 30 * time.Second}
+		return dialer.DialContext(ctx, network, addr)
+	}
+	return t
+}
+
+// NewClient 使用提供方超时设置的 http.Client
+func NewClient(provider string, base *http.Transport) *http.Client {
+	return &http.Client{Transport: NewTransport(provider, base)}
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	settings := For(t.Provider)
+	ctx, cancel := context.WithCancelCause(req.Context())
+	cancelOverall := context.CancelFunc(func() {})
+	if settings.Overall > 0 {
+		ctx, cancelOverall = context.WithTimeoutCause(ctx, settings.Overall, ErrOverallTimeout)
+	}
+	stop := func() {
+		cancelOverall()
+		cancel(nil)
+	}
+	var watchdog *time.Timer
+	if settings.Idle > 0 {
+		watchdog = time.AfterFunc(settings.Idle, func() { cancel(ErrIdleTimeout) })
+	}
+
+	resp, err := t.Base.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		if watchdog != nil {
+			watchdog.Stop()
+		}
+		err = t.timeoutError(ctx, err)
+		stop()
+		return nil, err
+	}
+	if watchdog != nil {
+		watchdog.Reset(settings.Idle)
+	}
+	resp.Body = &watchedBody{body: resp.Body, ctx: ctx, transport: t, watchdog: watchdog, idle: settings.Idle, stop: stop}
+	return resp, nil
+}
+
+// timeoutError 请求因超时被中断时，返回带提供方名称的超时错误
+func (t *Transport) timeoutError(ctx context.Context, err error) error {
+	cause := context.Cause(ctx)
+	if errors.Is(cause, ErrIdleTimeout) || errors.Is(cause, ErrOverallTimeout) {
+		return fmt.Errorf("%s: %w (%v)", t.Provider, cause, err)
+	}
+	return err
+}
+
+// watchedBody 每读到数据就重置空闲计时，关闭时释放计时器和 context
+type watchedBody struct {
+	body      io.ReadCloser
+	ctx       context.Context
+	transport *Transport
+	watchdog  *time.Timer
+	idle      time.Duration
+	stop      func()
+	closeOnce sync.Once
+}
+
+func (b *watchedBody) Read(p []byte) (int, error) {
+	n, err := b.body.Read(p)
+	if n > 0 && b.watchdog != nil {
+		b.watchdog.Reset(b.idle)
+	}
+	if err != nil && err != io.EOF {
+		err = b.transport.timeoutError(b.ctx, err)
+	}
+	return n, err
+}
+
+func (b *watchedBody) Close() error {
+	err := b.body.Close()
+	b.closeOnce.Do(func() {
+		if b.watchdog != nil {
+			b.watchdog.Stop()
+		}
+		b.stop()
+	})
+	return err
+}
+
+// WithOverall 为不经过 http 的调用（如本地转录进程）加上提供方的总超时，未配置总超时时只返回可取消的 context
+func WithOverall(ctx context.Context, provider string) (context.Context, context.CancelFunc) {
+	if overall := For(provider).Overall; overall > 0 {
+		return context.WithTimeoutCause(ctx, overall, ErrOverallTimeout)
+	}
+	return context.WithCancel(ctx)
+}
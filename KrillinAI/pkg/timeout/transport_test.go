@@ -0,0 +1,91 @@
+package timeout
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"krillin-ai/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setTimeoutConfig(t *testing.T, conf config.TimeoutConfig) {
+	t.Helper()
+	old := config.Conf.Timeout
+	config.Conf.Timeout = conf
+	t.Cleanup(func() { config.Conf.Timeout = old })
+}
+
+func TestFor_ProviderOverridesDefaults(t *testing.T) {
+	setTimeoutConfig(t, config.TimeoutConfig{
+		ConnectSeconds: 30,
+		IdleSeconds:    300,
+		Providers:      []config.TimeoutProvider{{Name: "tts", IdleSeconds: 60, OverallSeconds: 120}},
+	})
+
+	assert.Equal(t, Settings{Connect: 30 * time.Second, Idle: 60 * time.Second, Overall: 120 * time.Second}, For("tts"))
+	assert.Equal(t, Settings{Connect: 30 * time.Second, Idle: 300 * time.Second}, For("llm"))
+}
+
+func TestTransport_AbortsStalledStream(t *testing.T) {
+	setTimeoutConfig(t, config.TimeoutConfig{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("data: first\n\n"))
+		w.(http.Flusher).Flush()
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("llm", nil)
+	client.Transport.(*Transport).Provider = "stream"
+	config.Conf.Timeout.Providers = []config.TimeoutProvider{{Name: "stream", IdleSeconds: 1}}
+
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	start := time.Now()
+	body, err := io.ReadAll(resp.Body)
+
+	assert.Equal(t, "data: first\n\n", string(body))
+	assert.True(t, errors.Is(err, ErrIdleTimeout), "unexpected error: %v", err)
+	assert.Less(t, time.Since(start), 3*time.Second)
+}
+
+func TestTransport_OverallTimeout(t *testing.T) {
+	setTimeoutConfig(t, config.TimeoutConfig{OverallSeconds: 1})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer server.Close()
+
+	_, err := NewClient("tts", nil).Get(server.URL)
+
+	assert.True(t, errors.Is(err, ErrOverallTimeout), "unexpected error: %v", err)
+}
+
+func TestTransport_CompletesNormalRequest(t *testing.T) {
+	setTimeoutConfig(t, config.TimeoutConfig{ConnectSeconds: 5, IdleSeconds: 5, OverallSeconds: 5})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	resp, err := NewClient("mt", nil).Get(server.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+
+	require.NoError(t, err)
+	assert.Equal(t, "ok", string(body))
+}
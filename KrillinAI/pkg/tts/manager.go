@@ -1,6 +1,7 @@
 package tts
 
 import (
+	"context"
 	"krillin-ai/config"
 	"krillin-ai/internal/types"
 	"krillin-ai/log"
@@ -44,7 +45,7 @@ func NewCompositeTtsClient() *CompositeTtsClient {
 
 	// OpenAI
 	if config.Conf.Tts.Openai.ApiKey != "" {
-		c.OpenAI = openai.NewClient(config.Conf.Tts.Openai.BaseUrl, config.Conf.Tts.Openai.ApiKey, config.Conf.App.Proxy)
+		c.OpenAI = openai.NewClient(config.Conf.Tts.Openai.BaseUrl, config.Conf.Tts.Openai.ApiKey, config.Conf.App.Proxy).WithTimeout("tts")
 	}
 
 	// MiniMax
@@ -77,14 +78,14 @@ func NewCompositeTtsClient() *CompositeTtsClient {
 	return c
 }
 
-func (c *CompositeTtsClient) Text2Speech(text, voice, outputFile string) error {
+func (c *CompositeTtsClient) Text2Speech(ctx context.Context, text, voice, outputFile string) error {
 	// Routing Logic
 
 	// 1. Check for Doubao specific patterns
 	if strings.Contains(voice, "bigtts") || strings.Contains(voice, "mars") || strings.Contains(voice, "moon") || strings.Contains(voice, "volcano") {
 		if c.Doubao != nil {
 			log.GetLogger().Info("Routing to Doubao TTS", zap.String("voice", voice))
-			return c.Doubao.Text2Speech(text, voice, outputFile)
+			return c.Doubao.Text2Speech(ctx, text, voice, outputFile)
 		}
 	}
 
@@ -92,7 +93,7 @@ func (c *CompositeTtsClient) Text2Speech(text, voice, outputFile string) error {
 	// Most Edge TTS voices follow "zh-CN-XiaoxiaoNeural" format
 	if (strings.HasPrefix(voice, "zh-CN-") || strings.HasPrefix(voice, "en-US-")) && strings.Contains(voice, "Neural") {
 		log.GetLogger().Info("Routing to Edge TTS", zap.String("voice", voice))
-		return c.EdgeTTS.Text2Speech(text, voice, outputFile)
+		return c.EdgeTTS.Text2Speech(ctx, text, voice, outputFile)
 	}
 
 	// 3. MiniMax check (usually short IDs or specific names, tough to distinguish without list)
@@ -105,11 +106,11 @@ func (c *CompositeTtsClient) Text2Speech(text, voice, outputFile string) error {
 				c.Doubao.Cluster = "volcano_icl"
 			}
 			log.GetLogger().Info("Routing to Doubao TTS (ICL clone)", zap.String("voice", voice), zap.String("cluster", c.Doubao.Cluster))
-			return c.Doubao.Text2Speech(text, voice, outputFile)
+			return c.Doubao.Text2Speech(ctx, text, voice, outputFile)
 		}
 	}
 
 	// 5. Fallback to Default
 	log.GetLogger().Info("Routing to Default TTS", zap.String("voice", voice), zap.Any("default_provider", config.Conf.Tts.Provider))
-	return c.Default.Text2Speech(text, voice, outputFile)
+	return c.Default.Text2Speech(ctx, text, voice, outputFile)
 }
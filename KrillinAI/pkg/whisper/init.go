@@ -3,6 +3,7 @@ package whisper
 import (
 	"github.com/sashabaranov/go-openai"
 	"krillin-ai/config"
+	"krillin-ai/pkg/timeout"
 	"net/http"
 )
 
@@ -16,14 +17,11 @@ func NewClient(baseUrl, apiKey, proxyAddr string) *Client {
 		cfg.BaseURL = baseUrl
 	}
 
+	transport := &http.Transport{}
 	if proxyAddr != "" {
-		transport := &http.Transport{
-			Proxy: http.ProxyURL(config.Conf.App.ParsedProxy),
-		}
-		cfg.HTTPClient = &http.Client{
-			Transport: transport,
-		}
+		transport.Proxy = http.ProxyURL(config.Conf.App.ParsedProxy)
 	}
+	cfg.HTTPClient = timeout.NewClient("transcribe", transport)
 
 	client := openai.NewClientWithConfig(cfg)
 	return &Client{client: client}
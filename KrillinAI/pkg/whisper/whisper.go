@@ -9,9 +9,9 @@ import (
 	"strings"
 )
 
-func (c *Client) Transcription(audioFile, language, workDir string) (*types.TranscriptionData, error) {
+func (c *Client) Transcription(ctx context.Context, audioFile, language, workDir string) (*types.TranscriptionData, error) {
 	resp, err := c.client.CreateTranscription(
-		context.Background(),
+		ctx,
 		openai.AudioRequest{
 			Model:    openai.Whisper1,
 			FilePath: audioFile,
@@ -1,11 +1,13 @@
 package whispercpp
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"krillin-ai/internal/storage"
 	"krillin-ai/internal/types"
 	"krillin-ai/log"
+	"krillin-ai/pkg/timeout"
 	"krillin-ai/pkg/util"
 	"os"
 	"os/exec"
@@ -16,7 +18,9 @@ import (
 	"go.uber.org/zap"
 )
 
-func (c *WhispercppProcessor) Transcription(audioFile, language, workDir string) (*types.TranscriptionData, error) {
+func (c *WhispercppProcessor) Transcription(ctx context.Context, audioFile, language, workDir string) (*types.TranscriptionData, error) {
+	ctx, cancel := timeout.WithOverall(ctx, "transcribe")
+	defer cancel()
 	name := util.ChangeFileExtension(audioFile, "")
 	cmdArgs := []string{
 		"-m", fmt.Sprintf("./models/whispercpp/ggml-%s.bin", c.Model),
@@ -27,7 +31,7 @@ func (c *WhispercppProcessor) Transcription(audioFile, language, workDir string)
 		"--output-file", name,
 		"--file", audioFile,
 	}
-	cmd := exec.Command(storage.WhispercppPath, cmdArgs...)
+	cmd := exec.CommandContext(ctx, storage.WhispercppPath, cmdArgs...)
 	log.GetLogger().Info("WhispercppProcessor转录开始", zap.String("cmd", cmd.String()))
 	output, err := cmd.CombinedOutput()
 	if err != nil && !strings.Contains(string(output), "output_json: saving output to") {
@@ -76,25 +80,28 @@ func (c *WhispercppProcessor) Transcription(audioFile, language, workDir string)
 				seperatedWords := strings.Split(word.Text, "—")
 				transcriptionData.Words = append(transcriptionData.Words, []types.Word{
 					{
-						Num:   num,
-						Text:  util.CleanPunction(strings.TrimSpace(seperatedWords[0])),
-						Start: fromSec,
-						End:   mid,
+						Num:        num,
+						Text:       util.CleanPunction(strings.TrimSpace(seperatedWords[0])),
+						Start:      fromSec,
+						End:        mid,
+						Confidence: word.P,
 					},
 					{
-						Num:   num + 1,
-						Text:  util.CleanPunction(strings.TrimSpace(seperatedWords[1])),
-						Start: mid,
-						End:   toSec,
+						Num:        num + 1,
+						Text:       util.CleanPunction(strings.TrimSpace(seperatedWords[1])),
+						Start:      mid,
+						End:        toSec,
+						Confidence: word.P,
 					},
 				}...)
 				num += 2
 			} else {
 				transcriptionData.Words = append(transcriptionData.Words, types.Word{
-					Num:   num,
-					Text:  util.CleanPunction(strings.TrimSpace(word.Text)),
-					Start: fromSec,
-					End:   toSec,
+					Num:        num,
+					Text:       util.CleanPunction(strings.TrimSpace(word.Text)),
+					Start:      fromSec,
+					End:        toSec,
+					Confidence: word.P,
 				})
 				num++
 			}
@@ -0,0 +1,31 @@
+package whispercppserver
+
+import (
+	"krillin-ai/pkg/timeout"
+	"net/http"
+	"strings"
+)
+
+// Client 调用常驻的 whisper.cpp server（或兼容 /inference 接口的 faster-whisper 服务），
+// 模型只在服务启动时加载一次，多个分段可以并发请求
+type Client struct {
+	BaseUrl       string
+	InferencePath string
+	Model         string
+	httpClient    *http.Client
+}
+
+func NewClient(baseUrl, inferencePath, model string) *Client {
+	if inferencePath == "" {
+		inferencePath = "/inference"
+	}
+	if !strings.HasPrefix(inferencePath, "/") {
+		inferencePath = "/" + inferencePath
+	}
+	return &Client{
+		BaseUrl:       strings.TrimSuffix(baseUrl, "/"),
+		InferencePath: inferencePath,
+		Model:         model,
+		httpClient:    timeout.NewClient("transcribe", nil),
+	}
+}
@@ -0,0 +1,203 @@
+package whispercppserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"krillin-ai/internal/types"
+	"krillin-ai/log"
+	"krillin-ai/pkg/util"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"go.uber.org/zap"
+)
+
+// 特殊token，如 [_BEG_]、[_TT_150]、<|endoftext|>
+var specialTokenRegex = regexp.MustCompile(`^(\[.*\]|<\|.*\|>)$`)
+
+func (c *Client) Transcription(ctx context.Context, audioFile, language, workDir string) (*types.TranscriptionData, error) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+
+	file, err := os.Open(audioFile)
+	if err != nil {
+		log.GetLogger().Error("WhispercppServer 打开音频文件失败", zap.String("audio file", audioFile), zap.Error(err))
+		return nil, err
+	}
+	defer file.Close()
+	part, err := writer.CreateFormFile("file", filepath.Base(audioFile))
+	if err != nil {
+		return nil, err
+	}
+	if _, err = io.Copy(part, file); err != nil {
+		return nil, err
+	}
+
+	if language == "" {
+		language = "auto"
+	}
+	fields := map[string]string{
+		"response_format": "verbose_json",
+		"temperature":     "0.0",
+		"language":        language,
+		// faster-whisper 类服务需要显式要求词级时间戳，whisper.cpp server 会忽略
+		"timestamp_granularities[]": "word",
+	}
+	if c.Model != "" {
+		fields["model"] = c.Model
+	}
+	for key, value := range fields {
+		if err = writer.WriteField(key, value); err != nil {
+			return nil, err
+		}
+	}
+	if err = writer.Close(); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.BaseUrl+c.InferencePath, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	log.GetLogger().Info("WhispercppServer转录开始", zap.String("url", req.URL.String()), zap.String("audio file", audioFile))
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		log.GetLogger().Error("WhispercppServer 请求失败", zap.Error(err))
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		log.GetLogger().Error("WhispercppServer 返回错误", zap.Int("status", resp.StatusCode), zap.String("body", string(respBody)))
+		return nil, fmt.Errorf("whispercpp server returned status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	var result types.WhispercppServerOutput
+	if err = json.Unmarshal(respBody, &result); err != nil {
+		log.GetLogger().Error("WhispercppServer 解析返回失败", zap.String("body", string(respBody)), zap.Error(err))
+		return nil, err
+	}
+
+	transcriptionData := parseServerOutput(&result)
+	log.GetLogger().Info("WhispercppServer转录成功", zap.String("audio file", audioFile), zap.Int("words", len(transcriptionData.Words)))
+	return transcriptionData, nil
+}
+
+// parseServerOutput 转换为 TranscriptionData。顶层 words 已经是整词，直接使用；
+// whisper.cpp server 的 segments[].words 实际是子词 token，需要先合并成词
+func parseServerOutput(result *types.WhispercppServerOutput) *types.TranscriptionData {
+	transcriptionData := &types.TranscriptionData{
+		Language: result.Language,
+		Text:     strings.ReplaceAll(result.Text, "—", " "), // 连字符处理，因为模型存在很多错误添加到连字符
+		Words:    make([]types.Word, 0),
+	}
+
+	rawWords := result.Words
+	if len(rawWords) == 0 {
+		var tokens []types.WhispercppServerWord
+		for _, segment := range result.Segments {
+			tokens = append(tokens, segment.Words...)
+		}
+		rawWords = mergeTokens(tokens)
+	}
+	if transcriptionData.Text == "" {
+		for _, segment := range result.Segments {
+			transcriptionData.Text += strings.ReplaceAll(segment.Text, "—", " ")
+		}
+	}
+
+	num := 0
+	for _, word := range rawWords {
+		if strings.Contains(word.Word, "—") {
+			// 对称切分
+			mid := (word.Start + word.End) / 2
+			seperatedWords := strings.Split(word.Word, "—")
+			transcriptionData.Words = append(transcriptionData.Words, []types.Word{
+				{
+					Num:        num,
+					Text:       util.CleanPunction(strings.TrimSpace(seperatedWords[0])),
+					Start:      word.Start,
+					End:        mid,
+					Confidence: word.Probability,
+				},
+				{
+					Num:        num + 1,
+					Text:       util.CleanPunction(strings.TrimSpace(seperatedWords[1])),
+					Start:      mid,
+					End:        word.End,
+					Confidence: word.Probability,
+				},
+			}...)
+			num += 2
+			continue
+		}
+		text := util.CleanPunction(strings.TrimSpace(word.Word))
+		if text == "" {
+			continue
+		}
+		transcriptionData.Words = append(transcriptionData.Words, types.Word{
+			Num:        num,
+			Text:       text,
+			Start:      word.Start,
+			End:        word.End,
+			Confidence: word.Probability,
+		})
+		num++
+	}
+	return transcriptionData
+}
+
+// mergeTokens 把子词 token 合并成词：以空格开头的 token 开启新词，其余接到上一个词后面；
+// 中日泰等不以空格分词的文字，每个 token 单独作为一个词
+func mergeTokens(tokens []types.WhispercppServerWord) []types.WhispercppServerWord {
+	merged := make([]types.WhispercppServerWord, 0, len(tokens))
+	for _, token := range tokens {
+		trimmed := strings.TrimSpace(token.Word)
+		if trimmed == "" || specialTokenRegex.MatchString(trimmed) {
+			continue
+		}
+		if len(merged) == 0 || strings.HasPrefix(token.Word, " ") || !isContinuation(merged[len(merged)-1].Word, token.Word) {
+			merged = append(merged, token)
+			continue
+		}
+		last := &merged[len(merged)-1]
+		last.Word += token.Word
+		last.End = token.End
+		if token.Probability < last.Probability {
+			last.Probability = token.Probability
+		}
+	}
+	return merged
+}
+
+func isContinuation(prev, next string) bool {
+	prevRunes := []rune(prev)
+	nextRunes := []rune(next)
+	if len(prevRunes) == 0 || len(nextRunes) == 0 {
+		return false
+	}
+	last, first := prevRunes[len(prevRunes)-1], nextRunes[0]
+	if isUnspacedScript(last) || isUnspacedScript(first) {
+		// 标点依附在前一个词上
+		return unicode.IsPunct(first) && !isUnspacedScript(first)
+	}
+	return true
+}
+
+func isUnspacedScript(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Thai, unicode.Lao, unicode.Khmer, unicode.Myanmar)
+}
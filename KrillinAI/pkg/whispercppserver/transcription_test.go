@@ -0,0 +1,73 @@
+package whispercppserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"krillin-ai/internal/types"
+	"krillin-ai/log"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	log.InitLogger()
+}
+
+func TestTranscription_MergesSubwordTokens(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/inference", r.URL.Path)
+		require.NoError(t, r.ParseMultipartForm(1<<20))
+		assert.Equal(t, "verbose_json", r.FormValue("response_format"))
+		assert.Equal(t, "en", r.FormValue("language"))
+
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"language": "english",
+			"text":     " Hello world.",
+			"segments": []map[string]any{{
+				"text": " Hello world.",
+				"words": []map[string]any{
+					{"word": "[_BEG_]", "start": 0.0, "end": 0.0, "probability": 1.0},
+					{"word": " Hel", "start": 0.1, "end": 0.3, "probability": 0.9},
+					{"word": "lo", "start": 0.3, "end": 0.5, "probability": 0.8},
+					{"word": " world", "start": 0.6, "end": 1.0, "probability": 0.95},
+					{"word": ".", "start": 1.0, "end": 1.1, "probability": 0.99},
+				},
+			}},
+		})
+	}))
+	defer server.Close()
+
+	audioFile := filepath.Join(t.TempDir(), "split_audio_000.mp3")
+	require.NoError(t, os.WriteFile(audioFile, []byte("fake"), 0644))
+
+	client := NewClient(server.URL, "", "")
+	data, err := client.Transcription(context.Background(), audioFile, "en", t.TempDir())
+
+	require.NoError(t, err)
+	assert.Equal(t, " Hello world.", data.Text)
+	require.Len(t, data.Words, 2)
+	assert.Equal(t, "Hello", data.Words[0].Text)
+	assert.Equal(t, 0.1, data.Words[0].Start)
+	assert.Equal(t, 0.5, data.Words[0].End)
+	assert.Equal(t, "world", data.Words[1].Text)
+	assert.Equal(t, 1.1, data.Words[1].End)
+}
+
+func TestMergeTokens_UnspacedScript(t *testing.T) {
+	tokens := mergeTokens([]types.WhispercppServerWord{
+		{Word: "你好", Start: 0, End: 0.4},
+		{Word: "世界", Start: 0.4, End: 0.8},
+		{Word: "。", Start: 0.8, End: 0.9},
+	})
+
+	require.Len(t, tokens, 2)
+	assert.Equal(t, "你好", tokens[0].Word)
+	assert.Equal(t, "世界。", tokens[1].Word)
+}
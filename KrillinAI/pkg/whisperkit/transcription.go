@@ -1,10 +1,12 @@
 package whisperkit
 
 import (
+	"context"
 	"encoding/json"
 	"krillin-ai/internal/storage"
 	"krillin-ai/internal/types"
 	"krillin-ai/log"
+	"krillin-ai/pkg/timeout"
 	"krillin-ai/pkg/util"
 	"os"
 	"os/exec"
@@ -13,7 +15,9 @@ import (
 	"go.uber.org/zap"
 )
 
-func (c *WhisperKitProcessor) Transcription(audioFile, language, workDir string) (*types.TranscriptionData, error) {
+func (c *WhisperKitProcessor) Transcription(ctx context.Context, audioFile, language, workDir string) (*types.TranscriptionData, error) {
+	ctx, cancel := timeout.WithOverall(ctx, "transcribe")
+	defer cancel()
 	cmdArgs := []string{
 		"transcribe",
 		"--model-path", "./models/whisperkit/openai_whisper-large-v2",
@@ -26,7 +30,7 @@ func (c *WhisperKitProcessor) Transcription(audioFile, language, workDir string)
 		"--skip-special-tokens",
 		"--audio-path", audioFile,
 	}
-	cmd := exec.Command(storage.WhisperKitPath, cmdArgs...)
+	cmd := exec.CommandContext(ctx, storage.WhisperKitPath, cmdArgs...)
 	log.GetLogger().Info("WhisperKitProcessor转录开始", zap.String("cmd", cmd.String()))
 	output, err := cmd.CombinedOutput()
 	if err != nil {
@@ -61,25 +65,28 @@ func (c *WhisperKitProcessor) Transcription(audioFile, language, workDir string)
 				seperatedWords := strings.Split(word.Word, "—")
 				transcriptionData.Words = append(transcriptionData.Words, []types.Word{
 					{
-						Num:   num,
-						Text:  util.CleanPunction(strings.TrimSpace(seperatedWords[0])),
-						Start: word.Start,
-						End:   mid,
+						Num:        num,
+						Text:       util.CleanPunction(strings.TrimSpace(seperatedWords[0])),
+						Start:      word.Start,
+						End:        mid,
+						Confidence: word.Probability,
 					},
 					{
-						Num:   num + 1,
-						Text:  util.CleanPunction(strings.TrimSpace(seperatedWords[1])),
-						Start: mid,
-						End:   word.End,
+						Num:        num + 1,
+						Text:       util.CleanPunction(strings.TrimSpace(seperatedWords[1])),
+						Start:      mid,
+						End:        word.End,
+						Confidence: word.Probability,
 					},
 				}...)
 				num += 2
 			} else {
 				transcriptionData.Words = append(transcriptionData.Words, types.Word{
-					Num:   num,
-					Text:  util.CleanPunction(strings.TrimSpace(word.Word)),
-					Start: word.Start,
-					End:   word.End,
+					Num:        num,
+					Text:       util.CleanPunction(strings.TrimSpace(word.Word)),
+					Start:      word.Start,
+					End:        word.End,
+					Confidence: word.Probability,
 				})
 				num++
 			}
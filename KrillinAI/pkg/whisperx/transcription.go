@@ -1,10 +1,12 @@
 package whisperx
 
 import (
+	"context"
 	"encoding/json"
 	"krillin-ai/internal/storage"
 	"krillin-ai/internal/types"
 	"krillin-ai/log"
+	"krillin-ai/pkg/timeout"
 	"krillin-ai/pkg/util"
 	"os"
 	"os/exec"
@@ -14,7 +16,9 @@ import (
 	"go.uber.org/zap"
 )
 
-func (c *WhisperXProcessor) Transcription(audioFile, language, workDir string) (*types.TranscriptionData, error) {
+func (c *WhisperXProcessor) Transcription(ctx context.Context, audioFile, language, workDir string) (*types.TranscriptionData, error) {
+	ctx, cancel := timeout.WithOverall(ctx, "transcribe")
+	defer cancel()
 	var (
 		cmdArgs []string
 		envPath string
@@ -38,7 +42,7 @@ func (c *WhisperXProcessor) Transcription(audioFile, language, workDir string) (
 			"--batch_size", "8",
 			"--model_cache_only", "True",
 		}
-		cmd = exec.Command(envPath, cmdArgs...)
+		cmd = exec.CommandContext(ctx, envPath, cmdArgs...)
 	} else {
 		cmdArgs = []string{
 			audioFile,
@@ -50,7 +54,7 @@ func (c *WhisperXProcessor) Transcription(audioFile, language, workDir string) (
 			"--batch_size", "16",
 			"--model_cache_only", "True",
 		}
-		cmd = exec.Command(envPath, cmdArgs...)
+		cmd = exec.CommandContext(ctx, envPath, cmdArgs...)
 		cudaLibPath := "LD_LIBRARY_PATH=./bin/whisperx/.venv/lib/python3.12/site-packages/nvidia/cudnn/lib"
 		currentEnv := os.Environ()
 		newEnv := append(currentEnv, cudaLibPath)
@@ -90,25 +94,28 @@ func (c *WhisperXProcessor) Transcription(audioFile, language, workDir string) (
 				seperatedWords := strings.Split(word.Word, "—")
 				transcriptionData.Words = append(transcriptionData.Words, []types.Word{
 					{
-						Num:   num,
-						Text:  util.CleanPunction(strings.TrimSpace(seperatedWords[0])),
-						Start: word.Start,
-						End:   mid,
+						Num:        num,
+						Text:       util.CleanPunction(strings.TrimSpace(seperatedWords[0])),
+						Start:      word.Start,
+						End:        mid,
+						Confidence: word.Probability,
 					},
 					{
-						Num:   num + 1,
-						Text:  util.CleanPunction(strings.TrimSpace(seperatedWords[1])),
-						Start: mid,
-						End:   word.End,
+						Num:        num + 1,
+						Text:       util.CleanPunction(strings.TrimSpace(seperatedWords[1])),
+						Start:      mid,
+						End:        word.End,
+						Confidence: word.Probability,
 					},
 				}...)
 				num += 2
 			} else {
 				transcriptionData.Words = append(transcriptionData.Words, types.Word{
-					Num:   num,
-					Text:  util.CleanPunction(strings.TrimSpace(word.Word)),
-					Start: word.Start,
-					End:   word.End,
+					Num:        num,
+					Text:       util.CleanPunction(strings.TrimSpace(word.Word)),
+					Start:      word.Start,
+					End:        word.End,
+					Confidence: word.Probability,
 				})
 				num++
 			}
@@ -4,6 +4,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"krillin-ai/pkg/doubao"
 	"os"
@@ -27,7 +28,7 @@ func main() {
 		voice := "zh_female_qingxin"
 
 		fmt.Printf("Generating TTS for text: %s\n", text)
-		err := client.Text2Speech(text, voice, outputFile)
+		err := client.Text2Speech(context.Background(), text, voice, outputFile)
 		if err != nil {
 			fmt.Printf("TTS failed for cluster %s: %v\n", cluster, err)
 		} else {
@@ -31,7 +31,7 @@ func main() {
 
 	for _, v := range voices {
 		fmt.Printf("Testing voice: %s ... ", v)
-		err := client.Text2Speech("你好，我是测试语音。", v, fmt.Sprintf("test_%s.mp3", v))
+		err := client.Text2Speech(context.Background(), "你好，我是测试语音。", v, fmt.Sprintf("test_%s.mp3", v))
 		if err != nil {
 			fmt.Printf("FAILED: %v\n", err)
 		} else {